@@ -4,28 +4,64 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/ixti/ecs-task-helper/pkg/cgroup"
 	"github.com/ixti/ecs-task-helper/pkg/container_metadata"
+	"github.com/ixti/ecs-task-helper/pkg/environ"
+	"github.com/ixti/ecs-task-helper/pkg/expand"
+	"github.com/ixti/ecs-task-helper/pkg/identity"
+	"github.com/ixti/ecs-task-helper/pkg/linewriter"
+	"github.com/ixti/ecs-task-helper/pkg/otel"
+	"github.com/ixti/ecs-task-helper/pkg/procattr"
+	"github.com/ixti/ecs-task-helper/pkg/profile"
+	"github.com/ixti/ecs-task-helper/pkg/supervisor"
 	"github.com/spf13/cobra"
 	"golang.org/x/sys/unix"
 )
 
 type execCmdDeps struct {
 	metadataCmdDeps
-	Environ  func() []string
-	LookPath func(file string) (string, error)
-	Exec     func(argv0 string, argv []string, envv []string) error
+	Environ   func() []string
+	LookPath  func(file string) (string, error)
+	Exec      func(argv0 string, argv []string, envv []string) error
+	Supervise func(ctx context.Context, s *supervisor.Supervisor) (*supervisor.Result, error)
+	// LookupUser resolves --user specification.
+	LookupUser func(spec string) (*identity.Identity, error)
+	// DropPrivileges switches the current process to the given identity.
+	DropPrivileges func(id *identity.Identity) error
+	// ApplyAttributes applies resource limits and scheduling attributes to
+	// the current process.
+	ApplyAttributes func(a *procattr.Attributes) error
+	// Executable returns path of the running executable, to start the
+	// supervised child through exec-child.
+	Executable func() (string, error)
+	// CgroupRoot is where the container cgroup is mounted.
+	CgroupRoot string
 }
 
 func defaultExecCmdDeps() *execCmdDeps {
 	return &execCmdDeps{
 		metadataCmdDeps: *defaultMetadataCmdDeps(),
-		Environ:    os.Environ,
-		LookPath:   exec.LookPath,
-		Exec:       unix.Exec,
+		Environ:         os.Environ,
+		LookPath:        exec.LookPath,
+		Exec:            unix.Exec,
+		Supervise: func(ctx context.Context, s *supervisor.Supervisor) (*supervisor.Result, error) {
+			return s.Run(ctx)
+		},
+		LookupUser:      identity.Resolve,
+		DropPrivileges:  (*identity.Identity).Drop,
+		ApplyAttributes: (*procattr.Attributes).Apply,
+		Executable:      os.Executable,
+		CgroupRoot:      cgroup.DefaultRoot,
 	}
 }
 
@@ -34,8 +70,87 @@ func NewExecCommand(d *execCmdDeps) *cobra.Command {
 		d = defaultExecCmdDeps()
 	}
 
+	var withOTEL, withExpand bool
+	var expandVars []string
+
+	envOpts := &envOptions{}
+	preOpts := &preOptions{}
+	profileOpts := &profileOptions{}
+	superviseOpts := &superviseOptions{}
+	secretsOpts := &secretsOptions{}
+	userOpts := &userOptions{}
+	tuneOpts := &tuneOptions{}
+	procattrOpts := &procattrOptions{}
+	metadataOpts := &metadataOptions{}
+	logOpts := &logOptions{}
+	reportOpts := &reportOptions{}
+	memoryOpts := &memoryOptions{}
+
 	runE := func(cmd *cobra.Command, args []string) error {
-		argv0, err := d.LookPath(args[0])
+		if err := metadataOpts.Validate(); err != nil {
+			return err
+		}
+
+		if err := envOpts.Validate(); err != nil {
+			return err
+		}
+
+		for _, pattern := range expandVars {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid --expand-var %s: %w", pattern, err)
+			}
+		}
+
+		if err := logOpts.Validate(); err != nil {
+			return err
+		}
+
+		if err := reportOpts.Validate(); err != nil {
+			return err
+		}
+
+		profiles, err := profileOpts.Resolve()
+		if err != nil {
+			return err
+		}
+
+		supervise := superviseOpts.IsEnabled(cmd.Flags())
+
+		superviseConfig, err := superviseOpts.Config(cmd.Flags(), d.LookPath)
+		if err != nil {
+			return err
+		}
+
+		reloadSignal, err := secretsOpts.ReloadSignal()
+		if err != nil {
+			return err
+		}
+
+		memorySignal, err := memoryOpts.ParseSignal()
+		if err != nil {
+			return err
+		}
+
+		preCommands, err := preOpts.Parse(d.LookPath)
+		if err != nil {
+			return err
+		}
+
+		if err := tuneOpts.Validate(); err != nil {
+			return err
+		}
+
+		user, err := userOpts.Resolve(d.LookupUser)
+		if err != nil {
+			return err
+		}
+
+		attrs, err := procattrOpts.Attributes(cmd.Flags())
+		if err != nil {
+			return err
+		}
+
+		argv0, err := lookCommand(d.LookPath, args[0], procattrOpts.Chdir)
 		if err != nil {
 			slog.Error("Can't find command", "command", args[0], "error", err)
 			return err
@@ -43,14 +158,157 @@ func NewExecCommand(d *execCmdDeps) *cobra.Command {
 
 		argv := append([]string{argv0}, args[1:]...)
 
-		metadata, err := d.FetchMetadata(cmd.Context(), d.Timeout)
-
+		metadata, err := metadataOpts.Fetch(cmd.Context(), &d.metadataCmdDeps)
 		if err != nil {
-			slog.Error("Can't retrieve ECS task metadata", "error", err)
+			return err
+		}
+
+		if metadata == nil {
 			metadata = &container_metadata.Metadata{}
 		}
 
-		if err := d.Exec(argv0, argv, metadata.EnvironWith(d.Environ())); err != nil {
+		commandEnv, err := newCommandEnviron(envOpts, metadataOpts, cmd.Flags(), d.Environ(), metadata)
+		if err != nil {
+			slog.Error("Can't load env file", "error", err)
+			return err
+		}
+
+		env := commandEnv.Merge(metadata)
+
+		if user != nil {
+			env = user.EnvironWith(env)
+		}
+
+		if withOTEL {
+			env = otel.EnvironWith(env, metadata)
+		}
+
+		env = profile.EnvironWith(env, metadata, profiles)
+		env = tuneOpts.EnvironWith(env, metadata, d.CgroupRoot)
+
+		if withExpand || len(expandVars) > 0 {
+			env, argv, err = expandCommand(env, argv, expandVars)
+			if err != nil {
+				slog.Error("Can't expand variables", "error", err)
+				return err
+			}
+		}
+
+		resolved := &resolvedSecrets{}
+
+		if secretsOpts.IsEnabled(cmd.Flags()) {
+			resolved, err = resolveSecrets(cmd.Context(), env, commandEnv.process, metadata)
+			if err != nil {
+				slog.Error("Can't resolve secrets", "error", err)
+				return err
+			}
+
+			resolved.owner = user
+			env = resolved.EnvironWith(env)
+
+			if secretsOpts.File != "" {
+				if err := writeSecretsFile(secretsOpts.File, resolved.values, user); err != nil {
+					slog.Error("Can't write secrets file", "error", err)
+					return err
+				}
+			}
+		}
+
+		env = envOpts.Sanitize(env)
+
+		if err := runPre(cmd.Context(), preOpts, preCommands, env, cmd.OutOrStdout(), cmd.ErrOrStderr()); err != nil {
+			slog.Error("Can't run pre commands", "error", err)
+			return err
+		}
+
+		if supervise {
+			if superviseConfig.Restart.Policy != supervisor.RestartNever {
+				env = environ.Set(env, restartAttemptVariable, "0")
+				superviseConfig.Restart.Prepare = commandEnv.Restart(cmd.Context(), &d.metadataCmdDeps)
+			}
+
+			superviseConfig.Path, superviseConfig.Args, superviseConfig.Env = argv0, argv, env
+
+			flush := logOpts.Wrap(&superviseConfig, cmd.OutOrStdout(), cmd.ErrOrStderr(), metadata)
+			defer flush()
+
+			var tail *linewriter.Tail
+
+			if reportOpts.IsEnabled(cmd.Flags()) {
+				tail = reportOpts.Tee(&superviseConfig, cmd.ErrOrStderr())
+			}
+
+			if attrs != nil {
+				if err := d.superviseChild(&superviseConfig, procattrOpts.Args(cmd.Flags()), userOpts, user); err != nil {
+					slog.Error("Can't run command with process attributes", "error", err)
+					return err
+				}
+			} else if user != nil {
+				if err := superviseAs(&superviseConfig, user, d.DropPrivileges); err != nil {
+					slog.Error("Can't run command as user", "user", userOpts.User, "error", err)
+					return err
+				}
+			}
+
+			s := supervisor.New(superviseConfig)
+
+			if secretsOpts.Refresh > 0 {
+				ctx, cancel := context.WithCancel(cmd.Context())
+				defer cancel()
+
+				go resolved.Watch(ctx, s, secretsOpts, reloadSignal)
+			}
+
+			memory := newMemoryWatcher(d.CgroupRoot, memoryOpts, memorySignal, s.Signal)
+
+			if memory != nil && memory.Polls() {
+				ctx, cancel := context.WithCancel(cmd.Context())
+				defer cancel()
+
+				go memory.Watch(ctx)
+			}
+
+			result, err := d.Supervise(cmd.Context(), s)
+			if err != nil {
+				slog.Error("Command execution failed", "command", args[0], "error", err)
+				return err
+			}
+
+			var oomKilled *bool
+			if memory != nil {
+				oomKilled = memory.Exited(result)
+			}
+
+			if tail != nil {
+				report := newTerminationReport(result, oomKilled, tail.Lines(), metadata, time.Now())
+				reportOpts.Write(cmd.ErrOrStderr(), report)
+			}
+
+			if result.ExitCode != 0 {
+				// Exit status is propagated as is, there's no error to report.
+				cmd.SilenceErrors = true
+				return &exitCodeError{code: result.ExitCode}
+			}
+
+			return nil
+		}
+
+		// Attributes are inherited by the command, but not by pre commands.
+		if attrs != nil {
+			if err := d.ApplyAttributes(attrs); err != nil {
+				slog.Error("Can't set process attributes", "error", err)
+				return err
+			}
+		}
+
+		if user != nil {
+			if err := d.DropPrivileges(user); err != nil {
+				slog.Error("Can't drop privileges", "user", userOpts.User, "error", err)
+				return err
+			}
+		}
+
+		if err := d.Exec(argv0, argv, env); err != nil {
 			slog.Error("Command execution failed", "command", args[0], "error", err)
 			return err
 		}
@@ -59,11 +317,92 @@ func NewExecCommand(d *execCmdDeps) *cobra.Command {
 		return nil
 	}
 
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:          "exec command [args...]",
 		Short:        "Execute a command with ECS metadata environment variables",
 		SilenceUsage: true,
 		Args:         cobra.MinimumNArgs(1),
 		RunE:         runE,
 	}
+
+	// Everything after the command name belongs to the command itself.
+	cmd.Flags().SetInterspersed(false)
+	metadataOpts.AddFlags(cmd.Flags())
+	envOpts.AddFlags(cmd.Flags())
+	preOpts.AddFlags(cmd.Flags())
+	superviseOpts.AddFlags(cmd.Flags())
+	logOpts.AddFlags(cmd.Flags())
+	reportOpts.AddFlags(cmd.Flags())
+	memoryOpts.AddFlags(cmd.Flags())
+	cmd.Flags().BoolVar(&withExpand, "expand", false, "Expand ${VAR}, ${VAR:-default} and ${VAR:?error} in command arguments")
+	cmd.Flags().StringArrayVar(&expandVars, "expand-var", nil, "Also expand values of variables matching the pattern, e.g. LOG_* (can be specified multiple times, implies --expand)")
+	cmd.Flags().BoolVar(&withOTEL, "otel", false, "Merge OpenTelemetry resource attributes into OTEL_RESOURCE_ATTRIBUTES and OTEL_SERVICE_NAME")
+	profileOpts.AddFlags(cmd.Flags())
+	tuneOpts.AddFlags(cmd.Flags())
+	secretsOpts.AddFlags(cmd.Flags())
+	userOpts.AddFlags(cmd.Flags())
+	procattrOpts.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+// restartAttemptVariable holds the number of restarts of the supervised child.
+const restartAttemptVariable = "ECS_RESTART_ATTEMPT"
+
+// Restart returns function preparing env to restart the supervised child
+// with: ECS metadata variables are refreshed, unless metadata is skipped, and
+// the restart attempt is updated. Refreshed variables are merged into base and
+// env files again, rather than into env, so that --no-override keeps only
+// values that didn't come from metadata. Previous metadata is kept if it can't
+// be retrieved.
+func (e *commandEnviron) Restart(ctx context.Context, d *metadataCmdDeps) func(attempt int, env []string) []string {
+	return func(attempt int, env []string) []string {
+		if e.metadata.Policy != metadataSkip {
+			metadata, err := d.FetchMetadata(ctx, d.Timeout)
+			if err != nil {
+				slog.Warn("Can't refresh ECS task metadata, restarting with previous", "error", err)
+			} else {
+				merged := e.Merge(metadata)
+
+				for _, v := range metadata.Environ() {
+					key, _, _ := strings.Cut(v, "=")
+
+					if value, ok := environ.Lookup(merged, key); ok {
+						env = environ.Set(env, key, value)
+					}
+				}
+
+				env = e.options.Sanitize(env)
+			}
+		}
+
+		return environ.Set(env, restartAttemptVariable, strconv.Itoa(attempt))
+	}
+}
+
+// expandCommand expands references in values of env variables matching
+// patterns, and then in command arguments using the expanded env. Other
+// values, e.g. secrets injected by ECS, are never expanded, and neither is
+// the command itself.
+func expandCommand(env []string, argv []string, patterns []string) ([]string, []string, error) {
+	env, err := expand.EnvironMatching(env, func(name string) bool {
+		return matchAny(patterns, name)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lookup := expand.LookupEnviron(env)
+	expanded := append(make([]string, 0, len(argv)), argv[0])
+
+	for _, arg := range argv[1:] {
+		value, err := expand.String(arg, lookup)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to expand argument %q: %w", arg, err)
+		}
+
+		expanded = append(expanded, value)
+	}
+
+	return env, expanded, nil
 }
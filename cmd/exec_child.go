@@ -0,0 +1,123 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/ixti/ecs-task-helper/pkg/identity"
+	"github.com/ixti/ecs-task-helper/pkg/supervisor"
+	"github.com/spf13/cobra"
+)
+
+// execChildCommand is the name of the hidden command supervised child is
+// started with, when process attributes must apply to the child only.
+const execChildCommand = "exec-child"
+
+// NewExecChildCommand returns the hidden command applying process attributes
+// and --user to itself, and then replacing itself with the command. Arguments
+// are the resolved path of the command followed by its argv.
+func NewExecChildCommand(d *execCmdDeps) *cobra.Command {
+	if d == nil {
+		d = defaultExecCmdDeps()
+	}
+
+	userOpts := &userOptions{}
+	procattrOpts := &procattrOptions{}
+
+	runE := func(cmd *cobra.Command, args []string) error {
+		user, err := userOpts.Resolve(d.LookupUser)
+		if err != nil {
+			return err
+		}
+
+		attrs, err := procattrOpts.Attributes(cmd.Flags())
+		if err != nil {
+			return err
+		}
+
+		if attrs != nil {
+			if err := d.ApplyAttributes(attrs); err != nil {
+				slog.Error("Can't set process attributes", "error", err)
+				return err
+			}
+		}
+
+		if user != nil {
+			if err := d.DropPrivileges(user); err != nil {
+				slog.Error("Can't drop privileges", "user", userOpts.User, "error", err)
+				return err
+			}
+		}
+
+		if err := d.Exec(args[0], args[1:], d.Environ()); err != nil {
+			slog.Error("Command execution failed", "command", args[1], "error", err)
+			return err
+		}
+
+		// This is effectively unreachable in real world, as Exec replaces the process.
+		return nil
+	}
+
+	cmd := &cobra.Command{
+		Use:          execChildCommand + " [flags] -- path argv0 [args...]",
+		Short:        "Apply process attributes and execute the command",
+		Hidden:       true,
+		SilenceUsage: true,
+		Args:         cobra.MinimumNArgs(2),
+		RunE:         runE,
+	}
+
+	cmd.Flags().SetInterspersed(false)
+	userOpts.AddFlags(cmd.Flags())
+	procattrOpts.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+// childCommand returns command starting the given one through exec-child of
+// executable exe with the given flags.
+func childCommand(exe string, flags []string, command *supervisor.Command) *supervisor.Command {
+	args := append([]string{exe, execChildCommand}, flags...)
+	args = append(args, "--", command.Path)
+
+	return &supervisor.Command{Path: exe, Args: append(args, command.Args...)}
+}
+
+// superviseChild configures supervisor to start the child through exec-child
+// with attribute flags, so that they don't apply to the supervisor. As
+// attributes may require root privileges, privileged supervisor lets
+// exec-child drop them to user, and the pre-stop hook too.
+func (d *execCmdDeps) superviseChild(config *supervisor.Config, flags []string, userOpts *userOptions, user *identity.Identity) error {
+	exe, err := d.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find executable: %w", err)
+	}
+
+	child := &supervisor.Command{Path: config.Path, Args: config.Args}
+
+	if user != nil && os.Geteuid() == 0 {
+		userFlags := []string{"--user=" + userOpts.User}
+		child = childCommand(exe, append(flags, userFlags...), child)
+
+		if config.Stop.PreStop != nil {
+			config.Stop.PreStop = childCommand(exe, userFlags, config.Stop.PreStop)
+		}
+
+		config.Path, config.Args = child.Path, child.Args
+
+		return nil
+	}
+
+	child = childCommand(exe, flags, child)
+	config.Path, config.Args = child.Path, child.Args
+
+	if user != nil {
+		return superviseAs(config, user, d.DropPrivileges)
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"slices"
+
+	"github.com/ixti/ecs-task-helper/pkg/container_metadata"
+	"github.com/ixti/ecs-task-helper/pkg/dotenv"
+	"github.com/ixti/ecs-task-helper/pkg/environ"
+	"github.com/spf13/pflag"
+)
+
+type envOptions struct {
+	Files      []string
+	Clean      bool
+	Keep       []string
+	Unset      []string
+	NoOverride bool
+}
+
+func (o *envOptions) AddFlags(flags *pflag.FlagSet) {
+	flags.StringArrayVar(&o.Files, "env-file", nil, "Read environment variables from a dotenv file (can be specified multiple times)")
+	flags.BoolVar(&o.Clean, "clean-env", false, "Start the command without the environment of the process, except variables given with --keep")
+	flags.StringArrayVar(&o.Keep, "keep", nil, "Keep variables matching the pattern, e.g. PATH or LC_* (can be specified multiple times, implies --clean-env)")
+	flags.StringArrayVar(&o.Unset, "unset", nil, "Remove variables matching the pattern from the command environment (can be specified multiple times)")
+	flags.BoolVar(&o.NoOverride, "no-override", false, "Keep existing values of ECS metadata variables")
+}
+
+// Validate returns error if any of the patterns is malformed.
+func (o *envOptions) Validate() error {
+	for _, pattern := range o.Keep {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid --keep %s: %w", pattern, err)
+		}
+	}
+
+	for _, pattern := range o.Unset {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid --unset %s: %w", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// Base returns the environment of the process to start from: as is, or only
+// variables matching --keep patterns with --clean-env.
+func (o *envOptions) Base(flags *pflag.FlagSet, env []string) []string {
+	if !o.Clean && !flags.Changed("keep") {
+		return env
+	}
+
+	return environ.Filter(env, func(key string) bool {
+		return matchAny(o.Keep, key)
+	})
+}
+
+// commandEnviron holds what the environment of the command is built from, so
+// that it can be built again, e.g. with refreshed metadata on restart.
+type commandEnviron struct {
+	options  *envOptions
+	metadata *metadataOptions
+	// process is the environment of the process, and base is what is kept
+	// of it.
+	process []string
+	base    []string
+	files   [][]string
+}
+
+// newCommandEnviron returns environ of process with env files loaded.
+func newCommandEnviron(o *envOptions, m *metadataOptions, flags *pflag.FlagSet, process []string, metadata *container_metadata.Metadata) (*commandEnviron, error) {
+	e := &commandEnviron{options: o, metadata: m, process: process, base: o.Base(flags, process)}
+
+	files, err := o.LoadFiles(e.base, metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	e.files = files
+
+	return e, nil
+}
+
+// Merge returns base with env files and ECS metadata variables merged in.
+func (e *commandEnviron) Merge(metadata *container_metadata.Metadata) []string {
+	return e.options.Merge(e.base, e.files, metadata)
+}
+
+// Merge returns base with env files and ECS metadata variables merged in.
+// ECS metadata variables override existing values unless --no-override.
+func (o *envOptions) Merge(base []string, envFiles [][]string, metadata *container_metadata.Metadata) []string {
+	if o.NoOverride {
+		return metadata.EnvironWithDefaults(base, envFiles...)
+	}
+
+	return metadata.EnvironWith(base, envFiles...)
+}
+
+// Sanitize returns env without variables matching --unset patterns.
+func (o *envOptions) Sanitize(env []string) []string {
+	if len(o.Unset) == 0 {
+		return env
+	}
+
+	return environ.Filter(env, func(key string) bool {
+		return !matchAny(o.Unset, key)
+	})
+}
+
+// matchAny returns true if key matches any of the validated patterns.
+func matchAny(patterns []string, key string) bool {
+	return slices.ContainsFunc(patterns, func(pattern string) bool {
+		matched, _ := path.Match(pattern, key)
+		return matched
+	})
+}
+
+// LoadFiles parses env files in order. References are resolved to the values
+// variables would have if merged at that point, so that their precedence is
+// the same as of Merge, with or without --no-override.
+func (o *envOptions) LoadFiles(base []string, metadata *container_metadata.Metadata) ([][]string, error) {
+	layers := make([][]string, 0, len(o.Files))
+	loaded := []string{}
+
+	lookup := func(name string) (string, bool) {
+		return environ.Lookup(o.Merge(base, [][]string{loaded}, metadata), name)
+	}
+
+	for _, path := range o.Files {
+		env, err := dotenv.ParseFile(path, lookup)
+		if err != nil {
+			return nil, err
+		}
+
+		layers = append(layers, env)
+		loaded = append(loaded, env...)
+	}
+
+	return layers, nil
+}
@@ -0,0 +1,239 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ixti/ecs-task-helper/pkg/container_metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeEnvFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	return path
+}
+
+func testEnvDeps(env []string, capturedEnv *[]string) *execCmdDeps {
+	return &execCmdDeps{
+		metadataCmdDeps: metadataCmdDeps{
+			FetchMetadata: func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+				return testMetadata(), nil
+			},
+			Timeout: 5 * time.Second,
+		},
+		Environ:  func() []string { return env },
+		LookPath: func(file string) (string, error) { return "/bin/" + file, nil },
+		Exec: func(argv0 string, argv []string, envv []string) error {
+			*capturedEnv = envv
+			return nil
+		},
+	}
+}
+
+func TestNewExecCommand_EnvFile(t *testing.T) {
+	t.Run("with --env-file merges variables by precedence", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var capturedEnv []string
+
+		common := writeEnvFile(t, "common.env", "APP_ENV=development\nAPP_PORT=8080\nECS_CONTAINER_NAME=fake\n")
+		production := writeEnvFile(t, "production.env", "export APP_ENV=production\nAPP_URL=\"http://${ECS_CONTAINER_NAME}:${APP_PORT}\"\nAPP_HOME=$HOME/app\n")
+		deps := testEnvDeps([]string{"HOME=/home/test", "APP_ENV=test", "APP_DEBUG=1"}, &capturedEnv)
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--env-file", common, "--env-file", production, "sh"})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Contains(capturedEnv, "APP_DEBUG=1")
+		assert.Contains(capturedEnv, "APP_ENV=production")
+		assert.Contains(capturedEnv, "APP_PORT=8080")
+		assert.Contains(capturedEnv, "APP_URL=http://curl:8080")
+		assert.Contains(capturedEnv, "APP_HOME=/home/test/app")
+		assert.Contains(capturedEnv, "ECS_CONTAINER_NAME=curl")
+		assert.NotContains(capturedEnv, "APP_ENV=test")
+		assert.NotContains(capturedEnv, "ECS_CONTAINER_NAME=fake")
+	})
+
+	t.Run("with --env-file resolves references to ECS metadata variables", func(t *testing.T) {
+		var capturedEnv []string
+
+		path := writeEnvFile(t, "app.env", "APP_CLUSTER=${ECS_CLUSTER_NAME}\n")
+		deps := testEnvDeps([]string{"ECS_CLUSTER_NAME=local"}, &capturedEnv)
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--env-file", path, "sh"})
+
+		require.NoError(t, cmd.Execute())
+		assert.Contains(t, capturedEnv, "APP_CLUSTER=default")
+	})
+
+	t.Run("with --env-file and --no-override resolves references to existing values", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var capturedEnv []string
+
+		common := writeEnvFile(t, "common.env", "ECS_SERVICE_NAME=myservice\n")
+		app := writeEnvFile(t, "app.env", "APP_CLUSTER=${ECS_CLUSTER_NAME}\nAPP_SERVICE=${ECS_SERVICE_NAME}\nAPP_CONTAINER=${ECS_CONTAINER_NAME}\n")
+		deps := testEnvDeps([]string{"ECS_CLUSTER_NAME=local"}, &capturedEnv)
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--no-override", "--env-file", common, "--env-file", app, "sh"})
+
+		require.NoError(cmd.Execute())
+		assert.Contains(capturedEnv, "APP_CLUSTER=local")
+		assert.Contains(capturedEnv, "APP_SERVICE=myservice")
+		assert.Contains(capturedEnv, "APP_CONTAINER=curl")
+		assert.Contains(capturedEnv, "ECS_CLUSTER_NAME=local")
+	})
+
+	t.Run("with invalid env file returns error", func(t *testing.T) {
+		var capturedEnv []string
+
+		path := writeEnvFile(t, "invalid.env", "APP_ENV='production\n")
+		deps := testEnvDeps(nil, &capturedEnv)
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--env-file", path, "sh"})
+
+		err := cmd.Execute()
+
+		assert.EqualError(t, err, path+":1: unterminated quote in value of APP_ENV")
+		assert.Nil(t, capturedEnv)
+	})
+
+	t.Run("with missing env file returns error", func(t *testing.T) {
+		var capturedEnv []string
+
+		deps := testEnvDeps(nil, &capturedEnv)
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--env-file", filepath.Join(t.TempDir(), "missing.env"), "sh"})
+
+		err := cmd.Execute()
+
+		assert.ErrorContains(t, err, "failed to read env file")
+	})
+}
+
+func TestNewExecCommand_Sanitize(t *testing.T) {
+	processEnv := []string{
+		"PATH=/usr/bin",
+		"LC_ALL=C",
+		"LC_CTYPE=UTF-8",
+		"HOME=/root",
+		"AWS_CONTAINER_CREDENTIALS_RELATIVE_URI=/v2/credentials/secret",
+		"ECS_CLUSTER_NAME=local",
+	}
+
+	t.Run("with --clean-env keeps only matching variables", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var capturedEnv []string
+
+		cmd := NewExecCommand(testEnvDeps(processEnv, &capturedEnv))
+		cmd.SetArgs([]string{"--clean-env", "--keep", "PATH", "--keep", "LC_*", "sh"})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Equal([]string{"PATH=/usr/bin", "LC_ALL=C", "LC_CTYPE=UTF-8"}, capturedEnv[:3])
+		assert.Contains(capturedEnv, "ECS_CLUSTER_NAME=default")
+		assert.NotContains(capturedEnv, "HOME=/root")
+		assert.NotContains(capturedEnv, "AWS_CONTAINER_CREDENTIALS_RELATIVE_URI=/v2/credentials/secret")
+	})
+
+	t.Run("with --keep implies --clean-env", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var capturedEnv []string
+
+		cmd := NewExecCommand(testEnvDeps(processEnv, &capturedEnv))
+		cmd.SetArgs([]string{"--keep", "HOME", "sh"})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Contains(capturedEnv, "HOME=/root")
+		assert.NotContains(capturedEnv, "PATH=/usr/bin")
+	})
+
+	t.Run("with --unset removes matching variables", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var capturedEnv []string
+
+		cmd := NewExecCommand(testEnvDeps(processEnv, &capturedEnv))
+		cmd.SetArgs([]string{"--unset", "AWS_CONTAINER_*", "--unset", "ECS_LOG_*", "sh"})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Contains(capturedEnv, "PATH=/usr/bin")
+		assert.Contains(capturedEnv, "ECS_CLUSTER_NAME=default")
+		assert.NotContains(capturedEnv, "AWS_CONTAINER_CREDENTIALS_RELATIVE_URI=/v2/credentials/secret")
+		assert.NotContains(capturedEnv, "ECS_LOG_GROUP=")
+	})
+
+	t.Run("with --no-override keeps existing metadata variables", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var capturedEnv []string
+
+		cmd := NewExecCommand(testEnvDeps(processEnv, &capturedEnv))
+		cmd.SetArgs([]string{"--no-override", "sh"})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Contains(capturedEnv, "ECS_CLUSTER_NAME=local")
+		assert.Contains(capturedEnv, "ECS_CONTAINER_NAME=curl")
+	})
+
+	t.Run("with --clean-env resolves secrets with process credentials", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var capturedEnv []string
+
+		server := newSecretsStandIn(t, testParameters())
+		deps := testSecretsDeps(server, []string{"PASSWORD=ssm:///myapp/password"}, &capturedEnv)
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--clean-env", "--keep", "PASSWORD", "--secrets", "sh"})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Contains(capturedEnv, "PASSWORD=hunter2")
+		assert.NotContains(capturedEnv, "AWS_CONTAINER_CREDENTIALS_FULL_URI="+server.URL+"/credentials")
+	})
+
+	t.Run("with invalid pattern returns error", func(t *testing.T) {
+		var capturedEnv []string
+
+		cmd := NewExecCommand(testEnvDeps(processEnv, &capturedEnv))
+		cmd.SetArgs([]string{"--unset", "AWS_[", "sh"})
+
+		err := cmd.Execute()
+
+		assert.EqualError(t, err, "invalid --unset AWS_[: syntax error in pattern")
+		assert.Nil(t, capturedEnv)
+	})
+}
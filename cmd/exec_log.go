@@ -0,0 +1,190 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ixti/ecs-task-helper/pkg/container_metadata"
+	"github.com/ixti/ecs-task-helper/pkg/jsonlog"
+	"github.com/ixti/ecs-task-helper/pkg/linewriter"
+	"github.com/ixti/ecs-task-helper/pkg/multiline"
+	"github.com/ixti/ecs-task-helper/pkg/supervisor"
+	"github.com/spf13/pflag"
+)
+
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// defaultMultilineTimeout is how long to wait for more lines of a record,
+// e.g. a stack trace written line by line.
+const defaultMultilineTimeout = 1 * time.Second
+
+type logOptions struct {
+	Format           string
+	Multiline        []string
+	MultilineStart   string
+	MultilineTimeout time.Duration
+	MultilineMaxSize int
+}
+
+func (o *logOptions) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&o.Format, "log-format", logFormatText, "Format of the command output: text as is, or json to wrap lines into JSON objects with ECS metadata fields (implies --supervise)")
+	flags.StringSliceVar(&o.Multiline, "multiline", nil, "Group stack trace lines of the command output into single events: "+strings.Join(multiline.Presets(), ", ")+" (implies --supervise)")
+	flags.StringVar(&o.MultilineStart, "multiline-start", "", "Group lines of the command output into events starting with lines matching the regexp (implies --supervise)")
+	flags.DurationVar(&o.MultilineTimeout, "multiline-timeout", defaultMultilineTimeout, "Time to wait for more lines of an event, 0 to disable (implies --supervise)")
+	flags.IntVar(&o.MultilineMaxSize, "multiline-max-size", multiline.MaxEventSize, "Maximum size of an event in bytes (implies --supervise)")
+}
+
+func (o *logOptions) Validate() error {
+	if o.Format != logFormatText && o.Format != logFormatJSON {
+		return errors.New("invalid --log-format: must be text or json")
+	}
+
+	if _, err := multiline.NewRule("", o.Multiline); err != nil {
+		return fmt.Errorf("invalid --multiline: %w", err)
+	}
+
+	if _, err := multiline.NewRule(o.MultilineStart, nil); err != nil {
+		return fmt.Errorf("invalid --multiline-start: %w", err)
+	}
+
+	if o.MultilineMaxSize <= 0 {
+		return errors.New("invalid --multiline-max-size: must be positive")
+	}
+
+	return nil
+}
+
+// multilineRule returns rule grouping lines into events, or nil if lines are
+// not to be grouped. Options must be valid.
+func (o *logOptions) multilineRule() *multiline.Rule {
+	if len(o.Multiline) == 0 && o.MultilineStart == "" {
+		return nil
+	}
+
+	rule, _ := multiline.NewRule(o.MultilineStart, o.Multiline)
+
+	return rule
+}
+
+// Wrap makes the supervised child write its output to stdout and stderr in
+// the format, grouping lines into events if requested. Returns function
+// flushing incomplete last lines and events.
+func (o *logOptions) Wrap(config *supervisor.Config, stdout, stderr io.Writer, metadata *container_metadata.Metadata) func() {
+	rule := o.multilineRule()
+
+	if o.Format != logFormatJSON && rule == nil {
+		return func() {}
+	}
+
+	fields := logFields(metadata)
+
+	out, flushOut := o.wrap(stdout, "stdout", rule, fields)
+	errOut, flushErr := o.wrap(stderr, "stderr", rule, fields)
+
+	config.Stdout, config.Stderr = out, errOut
+
+	return func() {
+		flushOut()
+		flushErr()
+	}
+}
+
+func (o *logOptions) wrap(w io.Writer, stream string, rule *multiline.Rule, fields []jsonlog.Field) (io.Writer, func()) {
+	if rule == nil {
+		out := jsonlog.NewWriter(w, stream, fields)
+		return out, out.Flush
+	}
+
+	maxSize := o.MultilineMaxSize
+
+	emit := func(event []byte) {
+		if o.Format == logFormatJSON {
+			writeJSON(w, event, stream, fields, o.MultilineMaxSize)
+			return
+		}
+
+		// Log drivers split output by newlines, but not carriage returns.
+		w.Write(append(bytes.ReplaceAll(event, []byte{'\n'}, []byte{'\r'}), '\n'))
+	}
+
+	// JSON object the event is wrapped into counts towards its size.
+	if o.Format == logFormatJSON {
+		maxSize = max(maxSize-jsonEnvelopeSize(stream, fields), 1)
+	}
+
+	coalescer := multiline.New(rule, o.MultilineTimeout, maxSize, emit)
+	out := linewriter.NewWithMaxSize(coalescer.Line, maxSize)
+
+	return out, func() {
+		out.Flush()
+		coalescer.Flush()
+	}
+}
+
+// jsonEnvelopeSize returns size of JSON object wrapping an empty event, with
+// the longest timestamp.
+func jsonEnvelopeSize(stream string, fields []jsonlog.Field) int {
+	now := time.Date(2026, 12, 31, 23, 59, 59, 999999999, time.UTC)
+
+	return len(jsonlog.Format(nil, stream, now, fields)) - 1
+}
+
+// writeJSON writes event wrapped into JSON object to w. As escaping makes
+// the object longer than the event with the envelope, event that doesn't fit
+// into maxSize is split in two, preferably between lines, each written the
+// same way.
+func writeJSON(w io.Writer, event []byte, stream string, fields []jsonlog.Field, maxSize int) {
+	out := jsonlog.Format(event, stream, time.Now(), fields)
+
+	// Trailing newline is not a part of the log event.
+	if len(out)-1 <= maxSize || len(event) < 2 {
+		w.Write(out)
+		return
+	}
+
+	// Split at the last line break of the first half, if any.
+	i := len(event) / 2
+	if j := bytes.LastIndexByte(event[:i], '\n'); j > 0 {
+		writeJSON(w, event[:j], stream, fields, maxSize)
+		writeJSON(w, event[j+1:], stream, fields, maxSize)
+
+		return
+	}
+
+	for i > 1 && !utf8.RuneStart(event[i]) {
+		i--
+	}
+
+	writeJSON(w, event[:i], stream, fields, maxSize)
+	writeJSON(w, event[i:], stream, fields, maxSize)
+}
+
+// logFields returns ECS metadata fields identifying the source of log lines.
+// Unknown fields are omitted.
+func logFields(metadata *container_metadata.Metadata) []jsonlog.Field {
+	var fields []jsonlog.Field
+
+	for _, field := range []jsonlog.Field{
+		{Key: "ecs_task_id", Value: metadata.TaskID()},
+		{Key: "ecs_task_family", Value: metadata.TaskDefinitionFamily},
+		{Key: "ecs_task_revision", Value: metadata.TaskDefinitionVersion},
+		{Key: "ecs_container_name", Value: metadata.ContainerName},
+	} {
+		if field.Value != "" {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
@@ -0,0 +1,225 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ixti/ecs-task-helper/pkg/container_metadata"
+	"github.com/ixti/ecs-task-helper/pkg/jsonlog"
+	"github.com/ixti/ecs-task-helper/pkg/linewriter"
+	"github.com/ixti/ecs-task-helper/pkg/supervisor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewExecCommand_LogFormat(t *testing.T) {
+	t.Run("with json wraps output lines", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var config supervisor.Config
+
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+
+		cmd := NewExecCommand(testSuperviseDeps(&config))
+		cmd.SetArgs([]string{"--log-format", "json", "nginx"})
+		cmd.SetOut(stdout)
+		cmd.SetErr(stderr)
+
+		require.NoError(cmd.Execute())
+
+		config.Stdout.Write([]byte("listening\n"))
+		config.Stderr.Write([]byte(`{"level":"error","message":"oops"}` + "\n"))
+
+		var out, errOut map[string]any
+
+		require.NoError(json.Unmarshal(stdout.Bytes(), &out))
+		assert.Equal("listening", out["message"])
+		assert.Equal("stdout", out["stream"])
+		assert.Equal("8f03e41243824aea923aca126495f665", out["ecs_task_id"])
+		assert.Equal("curltest", out["ecs_task_family"])
+		assert.Equal("24", out["ecs_task_revision"])
+		assert.Equal("curl", out["ecs_container_name"])
+
+		require.NoError(json.Unmarshal(stderr.Bytes(), &errOut))
+		assert.Equal("error", errOut["level"])
+		assert.Equal("oops", errOut["message"])
+		assert.Equal("stderr", errOut["stream"])
+		assert.Equal("curl", errOut["ecs_container_name"])
+	})
+
+	t.Run("with text keeps output as is", func(t *testing.T) {
+		assert := assert.New(t)
+
+		var config supervisor.Config
+
+		cmd := NewExecCommand(testSuperviseDeps(&config))
+		cmd.SetArgs([]string{"--log-format", "text", "nginx"})
+
+		assert.NoError(cmd.Execute())
+		assert.Equal("/bin/nginx", config.Path)
+		assert.Nil(config.Stdout)
+		assert.Nil(config.Stderr)
+	})
+
+	t.Run("with invalid format returns error", func(t *testing.T) {
+		var config supervisor.Config
+
+		cmd := NewExecCommand(testSuperviseDeps(&config))
+		cmd.SetArgs([]string{"--log-format", "xml", "nginx"})
+
+		assert.EqualError(t, cmd.Execute(), "invalid --log-format: must be text or json")
+	})
+}
+
+func TestNewExecCommand_Multiline(t *testing.T) {
+	t.Run("with preset groups stack traces", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var config supervisor.Config
+
+		stdout := &bytes.Buffer{}
+
+		cmd := NewExecCommand(testSuperviseDeps(&config))
+		cmd.SetArgs([]string{"--multiline", "java", "--multiline-timeout", "0", "java", "-jar", "app.jar"})
+		cmd.SetOut(stdout)
+
+		require.NoError(cmd.Execute())
+
+		config.Stdout.Write([]byte("ERROR failed\njava.lang.IllegalStateException: boom\n\tat Foo.bar(Foo.java:1)\nINFO next\n"))
+
+		assert.Equal("ERROR failed\rjava.lang.IllegalStateException: boom\r\tat Foo.bar(Foo.java:1)\n", stdout.String())
+	})
+
+	t.Run("with json format wraps events", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var config supervisor.Config
+
+		stdout := &bytes.Buffer{}
+
+		cmd := NewExecCommand(testSuperviseDeps(&config))
+		cmd.SetArgs([]string{"--log-format", "json", "--multiline-start", `^\d`, "--multiline-timeout", "0", "app"})
+		cmd.SetOut(stdout)
+
+		require.NoError(cmd.Execute())
+
+		config.Stdout.Write([]byte("1 failed\ndetails\n2 next\n"))
+
+		var event map[string]any
+
+		require.NoError(json.Unmarshal(stdout.Bytes(), &event))
+		assert.Equal("1 failed\ndetails", event["message"])
+		assert.Equal("curl", event["ecs_container_name"])
+	})
+
+	t.Run("with --multiline-max-size above line size limit keeps long lines", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var config supervisor.Config
+
+		stdout := &bytes.Buffer{}
+
+		cmd := NewExecCommand(testSuperviseDeps(&config))
+		cmd.SetArgs([]string{"--multiline", "java", "--multiline-timeout", "0", "--multiline-max-size", "200000", "java"})
+		cmd.SetOut(stdout)
+
+		require.NoError(cmd.Execute())
+
+		line := strings.Repeat("x", linewriter.MaxLineSize+10)
+		config.Stdout.Write([]byte(line + "\nINFO next\n"))
+
+		assert.Equal(line+"\n", stdout.String())
+	})
+
+	t.Run("with json format keeps events within --multiline-max-size", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var config supervisor.Config
+
+		stdout := &bytes.Buffer{}
+
+		cmd := NewExecCommand(testSuperviseDeps(&config))
+		cmd.SetArgs([]string{"--log-format", "json", "--multiline", "java", "--multiline-timeout", "0", "--multiline-max-size", "400", "java"})
+		cmd.SetOut(stdout)
+
+		require.NoError(cmd.Execute())
+
+		trace := "ERROR failed\n" + strings.Repeat("\tat \"Foo\".bar(Foo.java:1)\n", 20)
+		config.Stdout.Write([]byte(trace + "INFO next\n"))
+
+		var messages []string
+
+		for _, line := range strings.SplitAfter(strings.TrimSuffix(stdout.String(), "\n"), "\n") {
+			var event map[string]any
+
+			assert.LessOrEqual(len(strings.TrimSuffix(line, "\n")), 400)
+			require.NoError(json.Unmarshal([]byte(line), &event))
+
+			messages = append(messages, event["message"].(string))
+		}
+
+		assert.Greater(len(messages), 2)
+		assert.Equal(strings.TrimSuffix(trace, "\n"), strings.Join(messages, "\n"))
+	})
+
+	t.Run("multiline flags imply --supervise", func(t *testing.T) {
+		for _, flag := range []string{"--multiline=go", "--multiline-start=^START", "--multiline-timeout=2s", "--multiline-max-size=1024"} {
+			t.Run(flag, func(t *testing.T) {
+				var config supervisor.Config
+
+				cmd := NewExecCommand(testSuperviseDeps(&config))
+				cmd.SetArgs([]string{flag, "app"})
+
+				require.NoError(t, cmd.Execute())
+				assert.Equal(t, "/bin/app", config.Path)
+			})
+		}
+	})
+
+	invalid := map[string][]string{
+		"invalid --multiline: unknown preset: cobol":     {"--multiline", "java,cobol"},
+		"invalid --multiline-max-size: must be positive": {"--multiline-max-size", "0"},
+	}
+
+	for expected, args := range invalid {
+		t.Run("returns error: "+expected, func(t *testing.T) {
+			var config supervisor.Config
+
+			cmd := NewExecCommand(testSuperviseDeps(&config))
+			cmd.SetArgs(append(args, "app"))
+
+			assert.EqualError(t, cmd.Execute(), expected)
+		})
+	}
+
+	t.Run("with invalid start pattern returns error", func(t *testing.T) {
+		var config supervisor.Config
+
+		cmd := NewExecCommand(testSuperviseDeps(&config))
+		cmd.SetArgs([]string{"--multiline-start", "(", "app"})
+
+		assert.ErrorContains(t, cmd.Execute(), "invalid --multiline-start: invalid start pattern")
+	})
+}
+
+func TestLogFields(t *testing.T) {
+	t.Run("omits unknown fields", func(t *testing.T) {
+		fields := logFields(&container_metadata.Metadata{ContainerName: "web"})
+
+		assert.Equal(t, []jsonlog.Field{{Key: "ecs_container_name", Value: "web"}}, fields)
+	})
+
+	t.Run("without metadata", func(t *testing.T) {
+		assert.Empty(t, logFields(&container_metadata.Metadata{}))
+	})
+}
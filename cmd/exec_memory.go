@@ -0,0 +1,217 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ixti/ecs-task-helper/pkg/cgroup"
+	"github.com/ixti/ecs-task-helper/pkg/supervisor"
+	"github.com/spf13/pflag"
+	"golang.org/x/sys/unix"
+)
+
+// defaultMemoryInterval is how often memory usage is checked.
+const defaultMemoryInterval = 5 * time.Second
+
+// memoryWarnStep is how much higher, in percent of the limit, memory usage
+// must get since the last warning to warn again.
+const memoryWarnStep = 5
+
+type memoryOptions struct {
+	Warn     int
+	SignalAt int
+	Signal   string
+	Interval time.Duration
+}
+
+func (o *memoryOptions) AddFlags(flags *pflag.FlagSet) {
+	flags.IntVar(&o.Warn, "memory-warn", 0, "Warn when memory usage reaches this percentage of the limit, and every 5% above it (implies --supervise)")
+	flags.IntVar(&o.SignalAt, "memory-signal-at", 0, "Send --memory-signal to the child when memory usage reaches this percentage of the limit (implies --supervise)")
+	flags.StringVar(&o.Signal, "memory-signal", "TERM", "Signal sent to the child at --memory-signal-at (implies --supervise)")
+	flags.DurationVar(&o.Interval, "memory-interval", defaultMemoryInterval, "Interval of memory usage checks against --memory-warn and --memory-signal-at, 0 to disable (implies --supervise)")
+}
+
+// ParseSignal validates options and returns signal sent to the child when
+// memory usage crosses the threshold.
+func (o *memoryOptions) ParseSignal() (syscall.Signal, error) {
+	if o.Warn < 0 || o.Warn > 100 {
+		return 0, errors.New("invalid --memory-warn: must be between 0 and 100")
+	}
+
+	if o.SignalAt < 0 || o.SignalAt > 100 {
+		return 0, errors.New("invalid --memory-signal-at: must be between 0 and 100")
+	}
+
+	if o.Interval < 0 {
+		return 0, errors.New("invalid --memory-interval: must not be negative")
+	}
+
+	sig, err := supervisor.ParseSignal(o.Signal)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --memory-signal: %w", err)
+	}
+
+	return sig, nil
+}
+
+// memoryWatcher checks memory usage of the container cgroup against its
+// limit, and reports OOM kills since it was created once the child exits.
+type memoryWatcher struct {
+	group   *cgroup.Group
+	options *memoryOptions
+	signal  syscall.Signal
+	// send sends signal to the child.
+	send func(sig syscall.Signal) error
+
+	mu sync.Mutex
+	// limit is zero if memory is not limited.
+	limit int64
+	// oomKills is the OOM kill counter when watcher was created, or -1 if
+	// OOM kills are not reported.
+	oomKills int64
+	// warned is the percentage of the limit usage was last warned at.
+	warned   int
+	signaled bool
+}
+
+// newMemoryWatcher returns watcher of the cgroup at root. Usage is checked
+// only if the cgroup has memory limit and any threshold is given. Returns nil
+// if there's nothing to watch.
+func newMemoryWatcher(root string, o *memoryOptions, sig syscall.Signal, send func(sig syscall.Signal) error) *memoryWatcher {
+	w := &memoryWatcher{group: cgroup.Open(root), options: o, signal: sig, send: send, oomKills: -1, warned: o.Warn - memoryWarnStep}
+
+	if kills, err := w.group.OOMKills(); err != nil {
+		slog.Debug("Can't read OOM kills, they won't be reported", "error", err)
+	} else {
+		w.oomKills = kills
+	}
+
+	limit, err := w.group.MemoryLimit()
+	if err != nil {
+		slog.Debug("Can't read memory limit", "error", err)
+	}
+
+	w.limit = limit
+
+	if w.options.Warn > 0 || w.options.SignalAt > 0 {
+		switch {
+		case err != nil:
+			slog.Warn("Can't read memory limit, usage won't be checked", "error", err)
+		case limit == 0:
+			slog.Warn("Memory is not limited, usage won't be checked")
+		}
+	}
+
+	if !w.checksUsage() && w.oomKills < 0 {
+		return nil
+	}
+
+	return w
+}
+
+// Polls returns true if memory usage is to be checked every interval.
+func (w *memoryWatcher) Polls() bool {
+	return w.checksUsage() && w.options.Interval > 0
+}
+
+// Watch checks memory usage every interval until ctx is done.
+func (w *memoryWatcher) Watch(ctx context.Context) {
+	ticker := time.NewTicker(w.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+// Exited logs OOM kills since the watcher was created, blaming the OOM
+// killer for the exit of the child if it was killed. Returns whether there
+// were any, or nil if OOM kills can't be read.
+func (w *memoryWatcher) Exited(result *supervisor.Result) *bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.oomKills < 0 {
+		return nil
+	}
+
+	kills, err := w.group.OOMKills()
+	if err != nil {
+		slog.Debug("Can't read OOM kills", "error", err)
+		return nil
+	}
+
+	kills -= w.oomKills
+	killed := kills > 0
+
+	switch {
+	case !killed:
+	case result.Signal == unix.SIGKILL:
+		slog.Error("Command was killed by the OOM killer", "oom_kills", kills, "limit", w.limit)
+	default:
+		slog.Error("OOM killer killed processes of the container", "oom_kills", kills, "limit", w.limit)
+	}
+
+	return &killed
+}
+
+func (w *memoryWatcher) check() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.checksUsage() {
+		return
+	}
+
+	usage, err := w.group.MemoryUsage()
+	if err != nil {
+		slog.Debug("Can't read memory usage", "error", err)
+		return
+	}
+
+	percent := int(usage * 100 / w.limit)
+
+	if w.options.Warn > 0 && percent >= w.warned+memoryWarnStep {
+		w.warned = percent - (percent-w.options.Warn)%memoryWarnStep
+
+		attrs := []any{"usage", usage, "limit", w.limit, "percent", percent}
+
+		if pressure, err := w.group.MemoryPressure(); err == nil {
+			attrs = append(attrs, "pressure", pressure.Some.Avg10)
+		}
+
+		slog.Warn("Memory usage is high", attrs...)
+	}
+
+	if w.options.SignalAt > 0 {
+		if percent < w.options.SignalAt {
+			w.signaled = false
+		} else if !w.signaled {
+			w.signaled = true
+
+			slog.Warn("Memory usage crossed threshold, signaling child", "usage", usage, "limit", w.limit, "percent", percent, "signal", w.options.Signal)
+
+			if err := w.send(w.signal); err != nil {
+				slog.Warn("Can't signal child", "error", err)
+			}
+		}
+	}
+}
+
+// checksUsage returns true if memory is limited and any threshold is given.
+func (w *memoryWatcher) checksUsage() bool {
+	return w.limit > 0 && (w.options.Warn > 0 || w.options.SignalAt > 0)
+}
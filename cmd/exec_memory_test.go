@@ -0,0 +1,210 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"github.com/ixti/ecs-task-helper/pkg/supervisor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+// fakeMemoryCgroup creates v2 cgroup tree with 1000 bytes memory limit.
+// Returns function setting memory usage and OOM kill counter.
+func fakeMemoryCgroup(t *testing.T) (string, func(usage int, oomKills int)) {
+	root := t.TempDir()
+
+	write := func(name string, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(root, name), []byte(content), 0o644))
+	}
+
+	set := func(usage int, oomKills int) {
+		write("memory.current", strconv.Itoa(usage)+"\n")
+		write("memory.events", "max 0\noom 0\noom_kill "+strconv.Itoa(oomKills)+"\n")
+	}
+
+	write("cgroup.controllers", "memory\n")
+	write("memory.max", "1000\n")
+	set(0, 0)
+
+	return root, set
+}
+
+func TestMemoryOptions_ParseSignal(t *testing.T) {
+	t.Run("with defaults", func(t *testing.T) {
+		sig, err := (&memoryOptions{Signal: "TERM", Interval: defaultMemoryInterval}).ParseSignal()
+
+		require.NoError(t, err)
+		assert.Equal(t, unix.SIGTERM, sig)
+	})
+
+	tests := []struct {
+		name     string
+		options  memoryOptions
+		expected string
+	}{
+		{"with too high --memory-warn", memoryOptions{Warn: 101, Signal: "TERM"}, "invalid --memory-warn: must be between 0 and 100"},
+		{"with negative --memory-signal-at", memoryOptions{SignalAt: -1, Signal: "TERM"}, "invalid --memory-signal-at: must be between 0 and 100"},
+		{"with negative --memory-interval", memoryOptions{Interval: -1, Signal: "TERM"}, "invalid --memory-interval: must not be negative"},
+		{"with unknown --memory-signal", memoryOptions{Signal: "NOPE"}, "invalid --memory-signal: "},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.options.ParseSignal()
+
+			assert.ErrorContains(t, err, tc.expected)
+		})
+	}
+}
+
+func TestMemoryWatcher(t *testing.T) {
+	// watcher returns watcher of root recording signals sent.
+	watcher := func(root string, o *memoryOptions) (*memoryWatcher, *[]syscall.Signal) {
+		var sent []syscall.Signal
+
+		w := newMemoryWatcher(root, o, unix.SIGUSR1, func(sig syscall.Signal) error {
+			sent = append(sent, sig)
+			return nil
+		})
+
+		return w, &sent
+	}
+
+	t.Run("warns at high-water marks", func(t *testing.T) {
+		assert := assert.New(t)
+
+		root, set := fakeMemoryCgroup(t)
+		w, _ := watcher(root, &memoryOptions{Warn: 80})
+
+		var warned []int
+
+		for _, usage := range []int{500, 830, 840, 800, 910, 990} {
+			set(usage, 0)
+			w.check()
+
+			warned = append(warned, w.warned)
+		}
+
+		assert.Equal([]int{75, 80, 80, 80, 90, 95}, warned)
+	})
+
+	t.Run("signals child once threshold is crossed", func(t *testing.T) {
+		assert := assert.New(t)
+
+		root, set := fakeMemoryCgroup(t)
+		w, sent := watcher(root, &memoryOptions{SignalAt: 90})
+
+		for _, usage := range []int{500, 900, 950} {
+			set(usage, 0)
+			w.check()
+		}
+
+		assert.Equal([]syscall.Signal{unix.SIGUSR1}, *sent)
+
+		for _, usage := range []int{800, 920} {
+			set(usage, 0)
+			w.check()
+		}
+
+		assert.Equal([]syscall.Signal{unix.SIGUSR1, unix.SIGUSR1}, *sent)
+	})
+
+	t.Run("reports OOM kills since creation on exit", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		root, set := fakeMemoryCgroup(t)
+		set(0, 2)
+
+		w, sent := watcher(root, &memoryOptions{Warn: 80, Interval: defaultMemoryInterval})
+
+		set(1000, 3)
+		w.check()
+
+		killed := w.Exited(&supervisor.Result{ExitCode: 137, Signal: unix.SIGKILL})
+		require.NotNil(killed)
+		assert.True(*killed)
+		assert.Empty(*sent)
+	})
+
+	t.Run("without OOM kills reports none on exit", func(t *testing.T) {
+		root, _ := fakeMemoryCgroup(t)
+		w, _ := watcher(root, &memoryOptions{})
+
+		killed := w.Exited(&supervisor.Result{ExitCode: 1})
+		require.NotNil(t, killed)
+		assert.False(t, *killed)
+	})
+
+	t.Run("polls only with thresholds", func(t *testing.T) {
+		assert := assert.New(t)
+
+		root, _ := fakeMemoryCgroup(t)
+
+		for options, expected := range map[memoryOptions]bool{
+			{Interval: defaultMemoryInterval}:               false,
+			{Warn: 80, Interval: defaultMemoryInterval}:     true,
+			{SignalAt: 90, Interval: defaultMemoryInterval}: true,
+			{Warn: 80}: false,
+		} {
+			w, _ := watcher(root, &options)
+
+			assert.Equal(expected, w.Polls(), "%+v", options)
+		}
+	})
+
+	t.Run("without memory limit only counts OOM kills", func(t *testing.T) {
+		assert := assert.New(t)
+
+		root, set := fakeMemoryCgroup(t)
+		require.NoError(t, os.WriteFile(filepath.Join(root, "memory.max"), []byte("max\n"), 0o644))
+
+		w, sent := watcher(root, &memoryOptions{SignalAt: 10})
+		assert.NotNil(w)
+
+		set(1000, 0)
+		w.check()
+		assert.Empty(*sent)
+	})
+
+	t.Run("without cgroup", func(t *testing.T) {
+		w, _ := watcher(t.TempDir(), &memoryOptions{Warn: 80})
+
+		assert.Nil(t, w)
+	})
+}
+
+func TestNewExecCommand_Memory(t *testing.T) {
+	t.Run("with --memory-signal-at implies --supervise", func(t *testing.T) {
+		assert := assert.New(t)
+
+		var config supervisor.Config
+
+		deps := testSuperviseDeps(&config)
+		deps.CgroupRoot, _ = fakeMemoryCgroup(t)
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--memory-signal-at", "90", "--memory-signal", "USR2", "java"})
+
+		assert.NoError(cmd.Execute())
+		assert.Equal("/bin/java", config.Path)
+	})
+
+	t.Run("with invalid --memory-signal returns error", func(t *testing.T) {
+		var config supervisor.Config
+
+		cmd := NewExecCommand(testSuperviseDeps(&config))
+		cmd.SetArgs([]string{"--memory-signal-at", "90", "--memory-signal", "NOPE", "java"})
+
+		assert.ErrorContains(t, cmd.Execute(), "invalid --memory-signal: ")
+		assert.Empty(t, config.Path)
+	})
+}
@@ -0,0 +1,152 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ixti/ecs-task-helper/pkg/linewriter"
+	"github.com/ixti/ecs-task-helper/pkg/supervisor"
+	"github.com/spf13/pflag"
+	"golang.org/x/sys/unix"
+)
+
+// preKillGracePeriod is how long a pre command is given to exit after it was
+// sent SIGTERM on timeout or interruption, before it's killed.
+const preKillGracePeriod = 5 * time.Second
+
+// preTimeoutPrefix starts --pre value giving the command its own timeout,
+// e.g. timeout=5m:/app/bin/migrate.
+const preTimeoutPrefix = "timeout="
+
+type preOptions struct {
+	Commands      []string
+	Timeout       time.Duration
+	IgnoreFailure bool
+}
+
+func (o *preOptions) AddFlags(flags *pflag.FlagSet) {
+	flags.StringArrayVar(&o.Commands, "pre", nil, "Command to run before the command, optionally prefixed with its own timeout, e.g. timeout=5m:migrate (can be specified multiple times)")
+	flags.DurationVar(&o.Timeout, "pre-timeout", 0, "Default timeout of --pre commands, 0 to disable")
+	flags.BoolVar(&o.IgnoreFailure, "pre-ignore-failure", false, "Continue if a --pre command fails or times out")
+}
+
+// preCommand is a pre command with its timeout, 0 for none.
+type preCommand struct {
+	*supervisor.Command
+	Timeout time.Duration
+}
+
+// Parse returns pre commands with resolved executables. Commands without
+// timeout prefix get --pre-timeout.
+func (o *preOptions) Parse(lookPath func(file string) (string, error)) ([]*preCommand, error) {
+	commands := make([]*preCommand, 0, len(o.Commands))
+
+	for _, value := range o.Commands {
+		command, timeout := value, o.Timeout
+
+		if rest, ok := strings.CutPrefix(value, preTimeoutPrefix); ok {
+			duration, after, found := strings.Cut(rest, ":")
+			if !found {
+				return nil, fmt.Errorf("invalid --pre %s: missing command after timeout", value)
+			}
+
+			d, err := time.ParseDuration(duration)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --pre %s: %w", value, err)
+			}
+
+			if d < 0 {
+				return nil, fmt.Errorf("invalid --pre %s: timeout must not be negative", value)
+			}
+
+			command, timeout = after, d
+		}
+
+		c, err := parseCommand(command, lookPath)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --pre: %w", err)
+		}
+
+		commands = append(commands, &preCommand{Command: c, Timeout: timeout})
+	}
+
+	return commands, nil
+}
+
+// runPre runs commands one by one with env, each within its timeout,
+// prefixing their output with the command name. SIGINT and SIGTERM interrupt
+// the running command and abort the sequence.
+func runPre(ctx context.Context, o *preOptions, commands []*preCommand, env []string, stdout, stderr io.Writer) error {
+	if len(commands) == 0 {
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, unix.SIGINT, unix.SIGTERM)
+	defer stop()
+
+	for _, command := range commands {
+		name := filepath.Base(command.Args[0])
+
+		slog.Info("Running pre command", "command", name)
+
+		err := runPreCommand(ctx, command.Command, env, command.Timeout, stdout, stderr)
+
+		switch {
+		case err == nil:
+			continue
+		case ctx.Err() != nil:
+			return fmt.Errorf("pre command %s interrupted", name)
+		case o.IgnoreFailure:
+			slog.Warn("Pre command failed, ignoring", "command", name, "error", err)
+		default:
+			return fmt.Errorf("pre command %s failed: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func runPreCommand(ctx context.Context, command *supervisor.Command, env []string, timeout time.Duration, stdout, stderr io.Writer) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	prefix := "[pre:" + filepath.Base(command.Args[0]) + "] "
+
+	out := linewriter.NewPrefixed(stdout, prefix)
+	defer out.Flush()
+
+	errOut := linewriter.NewPrefixed(stderr, prefix)
+	defer errOut.Flush()
+
+	c := exec.CommandContext(ctx, command.Path)
+	c.Args, c.Env, c.Stdout, c.Stderr = command.Args, env, out, errOut
+
+	// Run in own process group, so that whatever it spawned is stopped too.
+	c.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	c.Cancel = func() error { return unix.Kill(-c.Process.Pid, unix.SIGTERM) }
+	c.WaitDelay = preKillGracePeriod
+
+	err := c.Run()
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+
+	return err
+}
@@ -0,0 +1,166 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewExecCommand_Pre(t *testing.T) {
+	t.Run("with --pre runs commands in order before exec", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var capturedEnv []string
+		var stdout, stderr bytes.Buffer
+
+		deps := testEnvDeps([]string{"PATH=/usr/bin:/bin"}, &capturedEnv)
+
+		cmd := NewExecCommand(deps)
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&stderr)
+		cmd.SetArgs([]string{
+			"--pre", `sh -c 'echo "migrating $ECS_CONTAINER_NAME"; echo oops >&2'`,
+			"--pre", "true",
+			"--pre", `sh -c "printf 'warming\nup'"`,
+			"sh",
+		})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.NotNil(capturedEnv)
+		assert.Equal("[pre:sh] migrating curl\n[pre:sh] warming\n[pre:sh] up\n", stdout.String())
+		assert.Equal("[pre:sh] oops\n", stderr.String())
+	})
+
+	t.Run("with failing --pre does not execute", func(t *testing.T) {
+		assert := assert.New(t)
+
+		var capturedEnv []string
+
+		deps := testEnvDeps(nil, &capturedEnv)
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--pre", "false", "--pre", "touch /nonexistent", "sh"})
+
+		err := cmd.Execute()
+
+		assert.EqualError(err, "pre command false failed: exit status 1")
+		assert.Nil(capturedEnv)
+	})
+
+	t.Run("with --pre-ignore-failure continues", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var capturedEnv []string
+		var stdout bytes.Buffer
+
+		deps := testEnvDeps(nil, &capturedEnv)
+
+		cmd := NewExecCommand(deps)
+		cmd.SetOut(&stdout)
+		cmd.SetArgs([]string{"--pre-ignore-failure", "--pre", "false", "--pre", "echo done", "sh"})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.NotNil(capturedEnv)
+		assert.Equal("[pre:echo] done\n", stdout.String())
+	})
+
+	t.Run("with --pre-timeout stops slow command", func(t *testing.T) {
+		assert := assert.New(t)
+
+		var capturedEnv []string
+
+		deps := testEnvDeps(nil, &capturedEnv)
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--pre-timeout", "100ms", "--pre", "sleep 10", "sh"})
+
+		started := time.Now()
+		err := cmd.Execute()
+
+		assert.EqualError(err, "pre command sleep failed: timed out after 100ms")
+		assert.Less(time.Since(started), 5*time.Second)
+		assert.Nil(capturedEnv)
+	})
+
+	t.Run("with timeout prefix overrides --pre-timeout per command", func(t *testing.T) {
+		assert := assert.New(t)
+
+		var capturedEnv []string
+		var stdout bytes.Buffer
+
+		deps := testEnvDeps(nil, &capturedEnv)
+
+		cmd := NewExecCommand(deps)
+		cmd.SetOut(&stdout)
+		cmd.SetArgs([]string{
+			"--pre-timeout", "100ms",
+			"--pre", "timeout=5s:sh -c 'sleep 0.3; echo migrated'",
+			"--pre", "sleep 10",
+			"sh",
+		})
+
+		started := time.Now()
+		err := cmd.Execute()
+
+		assert.EqualError(err, "pre command sleep failed: timed out after 100ms")
+		assert.Equal("[pre:sh] migrated\n", stdout.String())
+		assert.Less(time.Since(started), 5*time.Second)
+		assert.Nil(capturedEnv)
+	})
+
+	t.Run("with timeout prefix without --pre-timeout", func(t *testing.T) {
+		var capturedEnv []string
+
+		deps := testEnvDeps(nil, &capturedEnv)
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--pre", "true", "--pre", "timeout=100ms:sleep 10", "sh"})
+
+		err := cmd.Execute()
+
+		assert.EqualError(t, err, "pre command sleep failed: timed out after 100ms")
+	})
+
+	invalid := map[string]string{
+		"timeout=5m":        "invalid --pre timeout=5m: missing command after timeout",
+		"timeout=soon:true": `invalid --pre timeout=soon:true: time: invalid duration "soon"`,
+		"timeout=-1s:true":  "invalid --pre timeout=-1s:true: timeout must not be negative",
+		"timeout=1s:":       "invalid --pre: empty command",
+	}
+
+	for value, expected := range invalid {
+		t.Run("with --pre "+value+" returns error", func(t *testing.T) {
+			var capturedEnv []string
+
+			cmd := NewExecCommand(testEnvDeps(nil, &capturedEnv))
+			cmd.SetArgs([]string{"--pre", value, "sh"})
+
+			assert.EqualError(t, cmd.Execute(), expected)
+		})
+	}
+
+	t.Run("with invalid --pre returns error", func(t *testing.T) {
+		var capturedEnv []string
+
+		deps := testEnvDeps(nil, &capturedEnv)
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--pre", `echo "unterminated`, "sh"})
+
+		err := cmd.Execute()
+
+		assert.EqualError(t, err, "invalid --pre: unterminated quote")
+	})
+}
@@ -0,0 +1,118 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ixti/ecs-task-helper/pkg/procattr"
+	"github.com/spf13/pflag"
+)
+
+type procattrOptions struct {
+	Rlimits     []string
+	Nice        int
+	OOMScoreAdj int
+	Umask       string
+	Chdir       string
+}
+
+func (o *procattrOptions) AddFlags(flags *pflag.FlagSet) {
+	flags.StringArrayVar(&o.Rlimits, "rlimit", nil, "Set resource limit as name=soft[:hard], e.g. nofile=65536:65536 (can be specified multiple times): "+strings.Join(procattr.Resources(), ", "))
+	flags.IntVar(&o.Nice, "nice", 0, "Set nice value of the command, from -20 (highest priority) to 19 (lowest)")
+	flags.IntVar(&o.OOMScoreAdj, "oom-score-adj", 0, "Set OOM score adjustment of the command, from -1000 (never killed) to 1000 (killed first)")
+	flags.StringVar(&o.Umask, "umask", "", "Set file mode creation mask of the command, e.g. 027")
+	flags.StringVar(&o.Chdir, "chdir", "", "Change working directory of the command")
+}
+
+// Attributes returns process attributes given by flags, or nil if none were
+// given. Attributes of flags that weren't given are nil.
+func (o *procattrOptions) Attributes(flags *pflag.FlagSet) (*procattr.Attributes, error) {
+	attrs := &procattr.Attributes{Dir: o.Chdir}
+
+	for _, value := range o.Rlimits {
+		rlimit, err := procattr.ParseRlimit(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --rlimit: %w", err)
+		}
+
+		attrs.Rlimits = append(attrs.Rlimits, rlimit)
+	}
+
+	if flags.Changed("nice") {
+		if o.Nice < procattr.MinNice || o.Nice > procattr.MaxNice {
+			return nil, fmt.Errorf("invalid --nice: must be between %d and %d", procattr.MinNice, procattr.MaxNice)
+		}
+
+		attrs.Nice = &o.Nice
+	}
+
+	if flags.Changed("oom-score-adj") {
+		if o.OOMScoreAdj < procattr.MinOOMScoreAdj || o.OOMScoreAdj > procattr.MaxOOMScoreAdj {
+			return nil, fmt.Errorf("invalid --oom-score-adj: must be between %d and %d", procattr.MinOOMScoreAdj, procattr.MaxOOMScoreAdj)
+		}
+
+		attrs.OOMScoreAdj = &o.OOMScoreAdj
+	}
+
+	if o.Umask != "" {
+		umask, err := procattr.ParseUmask(o.Umask)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --umask: %w", err)
+		}
+
+		attrs.Umask = &umask
+	}
+
+	if attrs.Dir == "" && len(attrs.Rlimits) == 0 && attrs.Nice == nil && attrs.OOMScoreAdj == nil && attrs.Umask == nil {
+		return nil, nil
+	}
+
+	return attrs, nil
+}
+
+// Args returns flags passing the attributes given by flags to exec-child.
+func (o *procattrOptions) Args(flags *pflag.FlagSet) []string {
+	var args []string
+
+	for _, value := range o.Rlimits {
+		args = append(args, "--rlimit="+value)
+	}
+
+	if flags.Changed("nice") {
+		args = append(args, "--nice="+strconv.Itoa(o.Nice))
+	}
+
+	if flags.Changed("oom-score-adj") {
+		args = append(args, "--oom-score-adj="+strconv.Itoa(o.OOMScoreAdj))
+	}
+
+	if o.Umask != "" {
+		args = append(args, "--umask="+o.Umask)
+	}
+
+	if o.Chdir != "" {
+		args = append(args, "--chdir="+o.Chdir)
+	}
+
+	return args
+}
+
+// lookCommand looks up file as lookPath does, except relative path with
+// a slash, e.g. bin/server, is resolved against dir the command runs in.
+func lookCommand(lookPath func(file string) (string, error), file string, dir string) (string, error) {
+	if dir != "" && strings.Contains(file, "/") && !filepath.IsAbs(file) {
+		abs, err := filepath.Abs(filepath.Join(dir, file))
+		if err != nil {
+			return "", err
+		}
+
+		file = abs
+	}
+
+	return lookPath(file)
+}
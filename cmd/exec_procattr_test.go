@@ -0,0 +1,276 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/ixti/ecs-task-helper/pkg/identity"
+	"github.com/ixti/ecs-task-helper/pkg/procattr"
+	"github.com/ixti/ecs-task-helper/pkg/supervisor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestNewExecCommand_Attributes(t *testing.T) {
+	t.Run("with attribute flags applies them before exec", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var (
+			capturedEnv   []string
+			capturedAttrs *procattr.Attributes
+			calls         []string
+		)
+
+		deps := testEnvDeps(nil, &capturedEnv)
+		deps.LookupUser = testLookupUser
+		deps.ApplyAttributes = func(a *procattr.Attributes) error {
+			capturedAttrs = a
+			calls = append(calls, "attributes")
+			return nil
+		}
+		deps.DropPrivileges = func(id *identity.Identity) error {
+			calls = append(calls, "drop")
+			return nil
+		}
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{
+			"--rlimit", "nofile=65536:65536", "--rlimit", "core=0",
+			"--nice", "-5", "--oom-score-adj", "500", "--umask", "027", "--chdir", "/app",
+			"--user", "app", "sh",
+		})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		require.NotNil(capturedAttrs)
+		assert.Equal([]string{"attributes", "drop"}, calls)
+		assert.Equal([]procattr.Rlimit{
+			{Name: "nofile", Resource: unix.RLIMIT_NOFILE, Cur: 65536, Max: 65536},
+			{Name: "core", Resource: unix.RLIMIT_CORE, Cur: 0, Max: 0},
+		}, capturedAttrs.Rlimits)
+		assert.Equal(-5, *capturedAttrs.Nice)
+		assert.Equal(500, *capturedAttrs.OOMScoreAdj)
+		assert.Equal(0o27, *capturedAttrs.Umask)
+		assert.Equal("/app", capturedAttrs.Dir)
+	})
+
+	t.Run("with --chdir looks up relative command in the directory", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var (
+			capturedEnv []string
+			looked      []string
+			argv0       string
+		)
+
+		deps := testEnvDeps(nil, &capturedEnv)
+		deps.ApplyAttributes = func(a *procattr.Attributes) error { return nil }
+		deps.LookPath = func(file string) (string, error) {
+			looked = append(looked, file)
+			return file, nil
+		}
+		deps.Exec = func(path string, argv []string, envv []string) error {
+			argv0 = path
+			return nil
+		}
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--chdir", "/app/current", "bin/server"})
+
+		require.NoError(cmd.Execute())
+		assert.Equal([]string{"/app/current/bin/server"}, looked)
+		assert.Equal("/app/current/bin/server", argv0)
+	})
+
+	t.Run("with --supervise applies attributes to the child only", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var config supervisor.Config
+
+		deps := testSuperviseDeps(&config)
+		deps.ApplyAttributes = func(a *procattr.Attributes) error {
+			return errors.New("attributes must not apply to the supervisor")
+		}
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{
+			"--supervise", "--rlimit", "nofile=65536", "--nice", "-5", "--oom-score-adj", "500",
+			"--umask", "027", "--chdir", "/app", "--pre-stop", "drain", "java", "-jar", "app.jar",
+		})
+
+		require.NoError(cmd.Execute())
+		assert.Equal("/bin/ecstatic", config.Path)
+		assert.Equal([]string{
+			"/bin/ecstatic", "exec-child",
+			"--rlimit=nofile=65536", "--nice=-5", "--oom-score-adj=500", "--umask=027", "--chdir=/app",
+			"--", "/bin/java", "/bin/java", "-jar", "app.jar",
+		}, config.Args)
+		assert.Equal(&supervisor.Command{Path: "/bin/drain", Args: []string{"drain"}}, config.Stop.PreStop)
+	})
+
+	t.Run("with --supervise and --user lets the child drop privileges", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var (
+			config  supervisor.Config
+			dropped bool
+		)
+
+		deps := testSuperviseDeps(&config)
+		deps.LookupUser = testLookupUser
+		deps.DropPrivileges = func(id *identity.Identity) error {
+			dropped = true
+			return nil
+		}
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--nice", "10", "--user", "app", "--pre-stop", "drain", "java"})
+
+		require.NoError(cmd.Execute())
+
+		// Unprivileged supervisor can only verify its own identity.
+		if os.Geteuid() == 0 {
+			assert.False(dropped)
+			assert.Nil(config.Credential)
+			assert.Equal([]string{"/bin/ecstatic", "exec-child", "--nice=10", "--user=app", "--", "/bin/java", "/bin/java"}, config.Args)
+			assert.Equal(&supervisor.Command{
+				Path: "/bin/ecstatic",
+				Args: []string{"/bin/ecstatic", "exec-child", "--user=app", "--", "/bin/drain", "drain"},
+			}, config.Stop.PreStop)
+		} else {
+			assert.True(dropped)
+			assert.Equal([]string{"/bin/ecstatic", "exec-child", "--nice=10", "--", "/bin/java", "/bin/java"}, config.Args)
+			assert.Equal(&supervisor.Command{Path: "/bin/drain", Args: []string{"drain"}}, config.Stop.PreStop)
+		}
+	})
+
+	t.Run("with zero values applies them", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var (
+			capturedEnv   []string
+			capturedAttrs *procattr.Attributes
+		)
+
+		deps := testEnvDeps(nil, &capturedEnv)
+		deps.ApplyAttributes = func(a *procattr.Attributes) error {
+			capturedAttrs = a
+			return nil
+		}
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--nice", "0", "sh"})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		require.NotNil(capturedAttrs)
+		assert.Equal(0, *capturedAttrs.Nice)
+		assert.Nil(capturedAttrs.OOMScoreAdj)
+		assert.Nil(capturedAttrs.Umask)
+	})
+
+	t.Run("with failed attributes does not exec", func(t *testing.T) {
+		var capturedEnv []string
+
+		deps := testEnvDeps(nil, &capturedEnv)
+		deps.ApplyAttributes = func(a *procattr.Attributes) error {
+			return errors.New("failed to set nofile limit: operation not permitted")
+		}
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--rlimit", "nofile=1048576", "sh"})
+
+		err := cmd.Execute()
+
+		assert.EqualError(t, err, "failed to set nofile limit: operation not permitted")
+		assert.Nil(t, capturedEnv)
+	})
+
+	invalid := map[string][]string{
+		"invalid --rlimit: unknown resource: files":               {"--rlimit", "files=1"},
+		"invalid --nice: must be between -20 and 19":              {"--nice", "20"},
+		"invalid --oom-score-adj: must be between -1000 and 1000": {"--oom-score-adj", "-1001"},
+		`invalid --umask: not an octal mode: "999"`:               {"--umask", "999"},
+	}
+
+	for message, flags := range invalid {
+		t.Run("with "+flags[0]+" "+flags[1]+" returns error", func(t *testing.T) {
+			var capturedEnv []string
+
+			cmd := NewExecCommand(testEnvDeps(nil, &capturedEnv))
+			cmd.SetArgs(append(flags, "sh"))
+
+			err := cmd.Execute()
+
+			assert.EqualError(t, err, message)
+			assert.Nil(t, capturedEnv)
+		})
+	}
+}
+
+func TestNewExecChildCommand(t *testing.T) {
+	t.Run("applies attributes and drops privileges before exec", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var (
+			capturedAttrs *procattr.Attributes
+			capturedArgv  []string
+			calls         []string
+		)
+
+		deps := testEnvDeps([]string{"APP_ENV=production"}, nil)
+		deps.LookupUser = testLookupUser
+		deps.ApplyAttributes = func(a *procattr.Attributes) error {
+			capturedAttrs = a
+			calls = append(calls, "attributes")
+			return nil
+		}
+		deps.DropPrivileges = func(id *identity.Identity) error {
+			calls = append(calls, "drop:"+id.Name)
+			return nil
+		}
+		deps.Exec = func(argv0 string, argv []string, envv []string) error {
+			calls = append(calls, "exec:"+argv0)
+			capturedArgv = argv
+			assert.Equal([]string{"APP_ENV=production"}, envv)
+			return nil
+		}
+
+		cmd := NewExecChildCommand(deps)
+		cmd.SetArgs([]string{"--nice=-5", "--chdir=/app", "--user=app", "--", "/bin/java", "java", "-jar", "app.jar"})
+
+		require.NoError(cmd.Execute())
+		assert.Equal([]string{"attributes", "drop:app", "exec:/bin/java"}, calls)
+		assert.Equal([]string{"java", "-jar", "app.jar"}, capturedArgv)
+		assert.Equal(-5, *capturedAttrs.Nice)
+		assert.Equal("/app", capturedAttrs.Dir)
+	})
+
+	t.Run("with failed attributes does not exec", func(t *testing.T) {
+		deps := testEnvDeps(nil, nil)
+		deps.ApplyAttributes = func(a *procattr.Attributes) error {
+			return errors.New("failed to set nice value: permission denied")
+		}
+		deps.Exec = func(argv0 string, argv []string, envv []string) error {
+			return errors.New("Exec must not be called")
+		}
+
+		cmd := NewExecChildCommand(deps)
+		cmd.SetArgs([]string{"--nice=-5", "--", "/bin/java", "java"})
+
+		assert.EqualError(t, cmd.Execute(), "failed to set nice value: permission denied")
+	})
+}
@@ -0,0 +1,131 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/ixti/ecs-task-helper/pkg/container_metadata"
+	"github.com/ixti/ecs-task-helper/pkg/linewriter"
+	"github.com/ixti/ecs-task-helper/pkg/supervisor"
+	"github.com/spf13/pflag"
+	"golang.org/x/sys/unix"
+)
+
+// defaultReportLines is the number of the last stderr lines in the report,
+// usually enough for an error with a short stack trace.
+const defaultReportLines = 20
+
+type reportOptions struct {
+	Enabled bool
+	File    string
+	Lines   int
+}
+
+func (o *reportOptions) AddFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&o.Enabled, "report", false, "Report exit status, resource usage, OOM kills and last stderr lines of the command to stderr when it exits (implies --supervise)")
+	flags.StringVar(&o.File, "report-file", "", "Also write the report to the file, e.g. on a shared volume (implies --report)")
+	flags.IntVar(&o.Lines, "report-lines", defaultReportLines, "Number of the last stderr lines in the report (implies --report)")
+}
+
+// IsEnabled returns true if --report, --report-file or --report-lines were
+// given.
+func (o *reportOptions) IsEnabled(flags *pflag.FlagSet) bool {
+	return o.Enabled || o.File != "" || flags.Changed("report-lines")
+}
+
+func (o *reportOptions) Validate() error {
+	if o.Lines < 0 {
+		return errors.New("invalid --report-lines: must not be negative")
+	}
+
+	return nil
+}
+
+// Tee makes the supervised child copy its stderr, defaulting to stderr, to
+// the returned tail.
+func (o *reportOptions) Tee(config *supervisor.Config, stderr io.Writer) *linewriter.Tail {
+	tail := linewriter.NewTail(o.Lines)
+
+	if config.Stderr != nil {
+		stderr = config.Stderr
+	}
+
+	config.Stderr = io.MultiWriter(stderr, tail)
+
+	return tail
+}
+
+// Write writes report to w, and to the report file if requested. Failure to
+// write the file is logged, as the command has exited already.
+func (o *reportOptions) Write(w io.Writer, report *terminationReport) {
+	data, err := json.Marshal(report)
+	if err != nil {
+		slog.Error("Can't encode termination report", "error", err)
+		return
+	}
+
+	data = append(data, '\n')
+
+	w.Write(data)
+
+	if o.File != "" {
+		if err := os.WriteFile(o.File, data, 0o644); err != nil {
+			slog.Error("Can't write termination report file", "file", o.File, "error", err)
+		}
+	}
+}
+
+// terminationReport describes why and how the supervised child exited.
+type terminationReport struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Message    string    `json:"message"`
+	ExitCode   int       `json:"exit_code"`
+	Signal     string    `json:"signal,omitempty"`
+	Runtime    float64   `json:"runtime_seconds"`
+	UserTime   float64   `json:"user_cpu_seconds"`
+	SystemTime float64   `json:"system_cpu_seconds"`
+	MaxRSS     int64     `json:"max_rss_bytes"`
+	// OOMKilled is omitted if OOM kills of the cgroup can't be read.
+	OOMKilled  *bool    `json:"oom_killed,omitempty"`
+	StderrTail []string `json:"stderr_tail"`
+
+	TaskID        string `json:"ecs_task_id,omitempty"`
+	TaskFamily    string `json:"ecs_task_family,omitempty"`
+	TaskRevision  string `json:"ecs_task_revision,omitempty"`
+	ContainerName string `json:"ecs_container_name,omitempty"`
+}
+
+func newTerminationReport(result *supervisor.Result, oomKilled *bool, stderrTail []string, metadata *container_metadata.Metadata, now time.Time) *terminationReport {
+	report := &terminationReport{
+		Timestamp:     now.UTC(),
+		Message:       "Command exited",
+		ExitCode:      result.ExitCode,
+		Runtime:       result.Usage.Runtime.Seconds(),
+		UserTime:      result.Usage.UserTime.Seconds(),
+		SystemTime:    result.Usage.SystemTime.Seconds(),
+		MaxRSS:        result.Usage.MaxRSS,
+		OOMKilled:     oomKilled,
+		StderrTail:    stderrTail,
+		TaskID:        metadata.TaskID(),
+		TaskFamily:    metadata.TaskDefinitionFamily,
+		TaskRevision:  metadata.TaskDefinitionVersion,
+		ContainerName: metadata.ContainerName,
+	}
+
+	if result.Signal != 0 {
+		report.Signal = unix.SignalName(result.Signal)
+	}
+
+	if report.StderrTail == nil {
+		report.StderrTail = []string{}
+	}
+
+	return report
+}
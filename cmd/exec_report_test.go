@@ -0,0 +1,197 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ixti/ecs-task-helper/pkg/container_metadata"
+	"github.com/ixti/ecs-task-helper/pkg/supervisor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestNewExecCommand_Report(t *testing.T) {
+	killed := &supervisor.Result{
+		ExitCode: 137,
+		Signal:   unix.SIGKILL,
+		Usage: supervisor.Usage{
+			Runtime:    90 * time.Second,
+			UserTime:   1500 * time.Millisecond,
+			SystemTime: 250 * time.Millisecond,
+			MaxRSS:     512 * 1024 * 1024,
+		},
+	}
+
+	// fakeCgroup returns cgroup root with OOM kill counter.
+	fakeCgroup := func(t *testing.T) string {
+		root := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(root, "cgroup.controllers"), []byte("memory\n"), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(root, "memory.events"), []byte("oom 0\noom_kill 0\n"), 0o644))
+
+		return root
+	}
+
+	// reportDeps returns deps of the child writing to stderr and being OOM
+	// killed.
+	reportDeps := func(root string) *execCmdDeps {
+		var config supervisor.Config
+
+		deps := testSuperviseDeps(&config)
+		deps.CgroupRoot = root
+		deps.Supervise = func(ctx context.Context, s *supervisor.Supervisor) (*supervisor.Result, error) {
+			s.Config().Stderr.Write([]byte("starting\nallocating\nstill allocating"))
+			os.WriteFile(filepath.Join(root, "memory.events"), []byte("oom 1\noom_kill 1\n"), 0o644)
+
+			return killed, nil
+		}
+
+		return deps
+	}
+
+	t.Run("with --report writes report to stderr", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		stderr := &bytes.Buffer{}
+
+		cmd := NewExecCommand(reportDeps(fakeCgroup(t)))
+		cmd.SetArgs([]string{"--report", "--report-lines", "2", "java"})
+		cmd.SetErr(stderr)
+
+		err := cmd.Execute()
+
+		var exitErr *exitCodeError
+		require.ErrorAs(err, &exitErr)
+		assert.Equal(137, exitErr.code)
+
+		output, report, _ := strings.Cut(stderr.String(), "still allocating")
+		assert.Equal("starting\nallocating\n", output)
+
+		var fields map[string]any
+
+		require.NoError(json.Unmarshal([]byte(report), &fields))
+		assert.Equal("Command exited", fields["message"])
+		assert.Equal(float64(137), fields["exit_code"])
+		assert.Equal("SIGKILL", fields["signal"])
+		assert.Equal(float64(90), fields["runtime_seconds"])
+		assert.Equal(1.5, fields["user_cpu_seconds"])
+		assert.Equal(0.25, fields["system_cpu_seconds"])
+		assert.Equal(float64(512*1024*1024), fields["max_rss_bytes"])
+		assert.Equal(true, fields["oom_killed"])
+		assert.Equal([]any{"allocating", "still allocating"}, fields["stderr_tail"])
+		assert.Equal("8f03e41243824aea923aca126495f665", fields["ecs_task_id"])
+		assert.Equal("curl", fields["ecs_container_name"])
+	})
+
+	t.Run("with --report-file writes report to file", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		file := filepath.Join(t.TempDir(), "report.json")
+
+		cmd := NewExecCommand(reportDeps(fakeCgroup(t)))
+		cmd.SetArgs([]string{"--report-file", file, "java"})
+		cmd.SetErr(&bytes.Buffer{})
+
+		assert.Error(cmd.Execute())
+
+		data, err := os.ReadFile(file)
+		require.NoError(err)
+
+		var fields map[string]any
+
+		require.NoError(json.Unmarshal(data, &fields))
+		assert.Equal(float64(137), fields["exit_code"])
+		assert.Equal([]any{"starting", "allocating", "still allocating"}, fields["stderr_tail"])
+	})
+
+	t.Run("without cgroup omits OOM kills", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		stderr := &bytes.Buffer{}
+
+		cmd := NewExecCommand(reportDeps(t.TempDir()))
+		cmd.SetArgs([]string{"--report", "java"})
+		cmd.SetErr(stderr)
+
+		assert.Error(cmd.Execute())
+
+		_, report, _ := strings.Cut(stderr.String(), "still allocating")
+
+		var fields map[string]any
+
+		require.NoError(json.Unmarshal([]byte(report), &fields))
+		assert.NotContains(fields, "oom_killed")
+	})
+
+	t.Run("with --report implies --supervise", func(t *testing.T) {
+		assert := assert.New(t)
+
+		var config supervisor.Config
+
+		cmd := NewExecCommand(testSuperviseDeps(&config))
+		cmd.SetArgs([]string{"--report", "java"})
+		cmd.SetErr(&bytes.Buffer{})
+
+		assert.NoError(cmd.Execute())
+		assert.Equal("/bin/java", config.Path)
+		assert.NotNil(config.Stderr)
+	})
+
+	t.Run("with --report-lines implies --report", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		stderr := &bytes.Buffer{}
+
+		cmd := NewExecCommand(reportDeps(fakeCgroup(t)))
+		cmd.SetArgs([]string{"--report-lines", "1", "java"})
+		cmd.SetErr(stderr)
+
+		assert.Error(cmd.Execute())
+
+		_, report, _ := strings.Cut(stderr.String(), "still allocating")
+
+		var fields map[string]any
+
+		require.NoError(json.Unmarshal([]byte(report), &fields))
+		assert.Equal([]any{"still allocating"}, fields["stderr_tail"])
+	})
+
+	t.Run("with negative --report-lines returns error", func(t *testing.T) {
+		var config supervisor.Config
+
+		cmd := NewExecCommand(testSuperviseDeps(&config))
+		cmd.SetArgs([]string{"--report", "--report-lines", "-1", "java"})
+
+		assert.EqualError(t, cmd.Execute(), "invalid --report-lines: must not be negative")
+	})
+}
+
+func TestNewTerminationReport(t *testing.T) {
+	t.Run("with exited child", func(t *testing.T) {
+		assert := assert.New(t)
+
+		now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.FixedZone("", 3600))
+
+		report := newTerminationReport(&supervisor.Result{ExitCode: 1}, nil, nil, &container_metadata.Metadata{}, now)
+
+		assert.Equal(&terminationReport{
+			Timestamp:  now.UTC(),
+			Message:    "Command exited",
+			ExitCode:   1,
+			StderrTail: []string{},
+		}, report)
+	})
+}
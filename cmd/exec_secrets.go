@@ -0,0 +1,146 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/ixti/ecs-task-helper/pkg/container_metadata"
+	"github.com/ixti/ecs-task-helper/pkg/environ"
+	"github.com/ixti/ecs-task-helper/pkg/identity"
+	"github.com/ixti/ecs-task-helper/pkg/secrets"
+	"github.com/ixti/ecs-task-helper/pkg/supervisor"
+	"github.com/spf13/pflag"
+)
+
+// restartOnChange is the --secrets-on-change value to restart the child.
+const restartOnChange = "restart"
+
+type secretsOptions struct {
+	Enabled  bool
+	File     string
+	Refresh  time.Duration
+	OnChange string
+}
+
+func (o *secretsOptions) AddFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&o.Enabled, "secrets", false, "Resolve ssm:// and secretsmanager:// references in environment variables")
+	flags.StringVar(&o.File, "secrets-file", "", "Write resolved secrets to this file (implies --secrets)")
+	flags.DurationVar(&o.Refresh, "secrets-refresh", 0, "Re-resolve secrets on this interval (implies --secrets and --supervise)")
+	flags.StringVar(&o.OnChange, "secrets-on-change", "HUP", "Signal sent to the child when secrets change, or \"restart\" (implies --secrets and --supervise)")
+}
+
+// IsEnabled returns true if --secrets or any of the flags implying it were given.
+func (o *secretsOptions) IsEnabled(flags *pflag.FlagSet) bool {
+	implied := []string{"secrets-file", "secrets-refresh", "secrets-on-change"}
+
+	return o.Enabled || slices.ContainsFunc(implied, flags.Changed)
+}
+
+// ReloadSignal returns signal sent to the child when secrets change, or zero
+// if the child should be restarted instead.
+func (o *secretsOptions) ReloadSignal() (syscall.Signal, error) {
+	if o.OnChange == restartOnChange {
+		return 0, nil
+	}
+
+	sig, err := supervisor.ParseSignal(o.OnChange)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --secrets-on-change: %w", err)
+	}
+
+	return sig, nil
+}
+
+type resolvedSecrets struct {
+	resolver  *secrets.Resolver
+	variables []secrets.Variable
+	values    map[string]string
+	// owner of the secrets file, if the command runs as another user.
+	owner *identity.Identity
+}
+
+// resolveSecrets resolves secret references in env. AWS configuration, like
+// credentials, is read from env layered over processEnv, so that it's
+// available even if removed from the command environment.
+// Region defaults to the region of the task.
+func resolveSecrets(ctx context.Context, env []string, processEnv []string, metadata *container_metadata.Metadata) (*resolvedSecrets, error) {
+	variables, err := secrets.Find(env)
+	if err != nil || len(variables) == 0 {
+		return &resolvedSecrets{}, err
+	}
+
+	resolver, err := secrets.NewResolver(environ.Merge(processEnv, env))
+	if err != nil {
+		return nil, err
+	}
+
+	if resolver.Region == "" {
+		resolver.Region = metadata.Region()
+	}
+
+	values, err := resolver.ResolveAll(ctx, variables)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resolvedSecrets{resolver: resolver, variables: variables, values: values}, nil
+}
+
+// EnvironWith returns env with references replaced by resolved values.
+func (r *resolvedSecrets) EnvironWith(env []string) []string {
+	for name, value := range r.values {
+		env = environ.Set(env, name, value)
+	}
+
+	return env
+}
+
+// Watch re-resolves secrets until ctx is done. On change, the secrets file
+// is rewritten and the child is sent reloadSignal, or restarted with updated
+// environment if reloadSignal is zero.
+func (r *resolvedSecrets) Watch(ctx context.Context, s *supervisor.Supervisor, o *secretsOptions, reloadSignal syscall.Signal) {
+	if len(r.variables) == 0 {
+		slog.Warn("No secret references to refresh")
+		return
+	}
+
+	r.resolver.Watch(ctx, r.variables, r.values, o.Refresh, func(values map[string]string, changed []string) {
+		r.values = values
+
+		if o.File != "" {
+			if err := writeSecretsFile(o.File, values, r.owner); err != nil {
+				slog.Error("Can't write secrets file", "error", err)
+			}
+		}
+
+		var err error
+
+		if reloadSignal == 0 {
+			slog.Info("Restarting child to apply changed secrets", "variables", changed)
+			err = s.Restart(r.EnvironWith(s.Config().Env))
+		} else {
+			slog.Info("Signaling child to reload changed secrets", "variables", changed, "signal", o.OnChange)
+			err = s.Signal(reloadSignal)
+		}
+
+		if err != nil {
+			slog.Warn("Can't apply changed secrets", "error", err)
+		}
+	})
+}
+
+// writeSecretsFile writes secrets file owned by owner, if it's not nil.
+func writeSecretsFile(path string, values map[string]string, owner *identity.Identity) error {
+	if err := secrets.WriteFile(path, values); err != nil {
+		return err
+	}
+
+	return chownSecretsFile(path, owner)
+}
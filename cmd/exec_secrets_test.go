@@ -0,0 +1,208 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ixti/ecs-task-helper/pkg/container_metadata"
+	"github.com/ixti/ecs-task-helper/pkg/supervisor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSecretsStandIn serves container credentials and SSM parameters.
+// Parameters are looked up on every request, so they can be changed by tests.
+func newSecretsStandIn(t *testing.T, parameters *sync.Map) *httptest.Server {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /credentials", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"AccessKeyId":     "AKIDEXAMPLE",
+			"SecretAccessKey": "secret",
+			"Token":           "session",
+			"Expiration":      time.Now().Add(time.Hour),
+		})
+	})
+
+	mux.HandleFunc("POST /", func(w http.ResponseWriter, r *http.Request) {
+		input := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&input)
+
+		value, ok := parameters.Load(input["Name"])
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"__type": "ParameterNotFound"})
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]any{"Parameter": map[string]any{"Value": value}})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func testParameters() *sync.Map {
+	parameters := &sync.Map{}
+	parameters.Store("/myapp/password", "hunter2")
+
+	return parameters
+}
+
+func testSecretsDeps(server *httptest.Server, env []string, capturedEnv *[]string) *execCmdDeps {
+	return &execCmdDeps{
+		metadataCmdDeps: metadataCmdDeps{
+			FetchMetadata: func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+				return testMetadata(), nil
+			},
+			Timeout: 5 * time.Second,
+		},
+		Environ: func() []string {
+			return append([]string{
+				"AWS_ENDPOINT_URL=" + server.URL,
+				"AWS_CONTAINER_CREDENTIALS_FULL_URI=" + server.URL + "/credentials",
+			}, env...)
+		},
+		LookPath: func(file string) (string, error) { return "/bin/" + file, nil },
+		Exec: func(argv0 string, argv []string, envv []string) error {
+			*capturedEnv = envv
+			return nil
+		},
+	}
+}
+
+func TestNewExecCommand_Secrets(t *testing.T) {
+	t.Run("with --secrets resolves references", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var capturedEnv []string
+
+		server := newSecretsStandIn(t, testParameters())
+		deps := testSecretsDeps(server, []string{"PASSWORD=ssm:///myapp/password"}, &capturedEnv)
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--secrets", "sh"})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Contains(capturedEnv, "PASSWORD=hunter2")
+	})
+
+	t.Run("without --secrets leaves references untouched", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var capturedEnv []string
+
+		server := newSecretsStandIn(t, testParameters())
+		deps := testSecretsDeps(server, []string{"PASSWORD=ssm:///myapp/password"}, &capturedEnv)
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"sh"})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Contains(capturedEnv, "PASSWORD=ssm:///myapp/password")
+	})
+
+	t.Run("with unresolvable reference does not execute", func(t *testing.T) {
+		assert := assert.New(t)
+
+		var capturedEnv []string
+
+		server := newSecretsStandIn(t, &sync.Map{})
+		deps := testSecretsDeps(server, []string{"PASSWORD=ssm:///myapp/password"}, &capturedEnv)
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--secrets", "sh"})
+
+		err := cmd.Execute()
+
+		assert.ErrorContains(err, "failed to resolve ssm:///myapp/password for PASSWORD")
+		assert.Nil(capturedEnv)
+	})
+}
+
+func TestNewExecCommand_SecretsFile(t *testing.T) {
+	t.Run("with --secrets-file writes resolved secrets", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var capturedEnv []string
+
+		path := filepath.Join(t.TempDir(), "secrets.env")
+		server := newSecretsStandIn(t, testParameters())
+		deps := testSecretsDeps(server, []string{"PASSWORD=ssm:///myapp/password"}, &capturedEnv)
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--secrets-file", path, "sh"})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Contains(capturedEnv, "PASSWORD=hunter2")
+
+		data, err := os.ReadFile(path)
+		require.NoError(err)
+		assert.Equal("PASSWORD=\"hunter2\"\n", string(data))
+	})
+
+	t.Run("with --secrets-refresh rewrites file on change", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var capturedEnv []string
+
+		path := filepath.Join(t.TempDir(), "secrets.env")
+		parameters := testParameters()
+		server := newSecretsStandIn(t, parameters)
+		deps := testSecretsDeps(server, []string{"PASSWORD=ssm:///myapp/password"}, &capturedEnv)
+		deps.Supervise = func(ctx context.Context, s *supervisor.Supervisor) (*supervisor.Result, error) {
+			parameters.Store("/myapp/password", "hunter3")
+
+			assert.Eventually(func() bool {
+				data, _ := os.ReadFile(path)
+				return string(data) == "PASSWORD=\"hunter3\"\n"
+			}, time.Second, 10*time.Millisecond)
+
+			return &supervisor.Result{}, nil
+		}
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--secrets-file", path, "--secrets-refresh", "10ms", "sh"})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Nil(capturedEnv)
+	})
+
+	t.Run("with invalid --secrets-on-change returns error", func(t *testing.T) {
+		var capturedEnv []string
+
+		server := newSecretsStandIn(t, testParameters())
+		deps := testSecretsDeps(server, nil, &capturedEnv)
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--secrets-on-change", "reload", "sh"})
+
+		err := cmd.Execute()
+
+		assert.ErrorContains(t, err, "invalid --secrets-on-change")
+	})
+}
@@ -0,0 +1,183 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/ixti/ecs-task-helper/pkg/shellwords"
+	"github.com/ixti/ecs-task-helper/pkg/supervisor"
+	"github.com/spf13/pflag"
+	"golang.org/x/sys/unix"
+)
+
+// defaultStopTimeout matches the default ECS container stopTimeout.
+const defaultStopTimeout = 30 * time.Second
+
+// Restart defaults allow a few quick restarts of a crashing child, while
+// a crash loop still fails the task.
+const (
+	defaultMaxRestarts       = 5
+	defaultRestartWindow     = 5 * time.Minute
+	defaultRestartBackoff    = 1 * time.Second
+	defaultRestartMaxBackoff = 30 * time.Second
+)
+
+// killGracePeriod is how long before ECS stop timeout the supervisor kills
+// the child, so that it gets a chance to report the exit.
+const killGracePeriod = 1 * time.Second
+
+// defaultKillAfter is the kill deadline for the default ECS stopTimeout.
+const defaultKillAfter = defaultStopTimeout - killGracePeriod
+
+// getDefaultKillAfter returns kill deadline for the container stop timeout.
+// ECS does not expose stopTimeout to the container, so unless it's mirrored
+// in ECS_CONTAINER_STOP_TIMEOUT of the container definition, the deadline is
+// derived from the default stopTimeout.
+func getDefaultKillAfter() time.Duration {
+	timeout := defaultStopTimeout
+
+	if v := os.Getenv("ECS_CONTAINER_STOP_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			timeout = d
+		} else {
+			slog.Warn(
+				"Invalid ECS_CONTAINER_STOP_TIMEOUT, using default",
+				"value", v,
+				"default", defaultStopTimeout,
+			)
+		}
+	}
+
+	return max(timeout-killGracePeriod, 0)
+}
+
+type superviseOptions struct {
+	Enabled    bool
+	PreStop    string
+	StopDelay  time.Duration
+	StopSignal string
+	KillAfter  time.Duration
+	MapSignals []string
+
+	Restart           string
+	MaxRestarts       int
+	RestartWindow     time.Duration
+	RestartBackoff    time.Duration
+	RestartMaxBackoff time.Duration
+}
+
+func (o *superviseOptions) AddFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&o.Enabled, "supervise", false, "Run command as a supervised child instead of replacing the process")
+	flags.StringVar(&o.PreStop, "pre-stop", "", "Command to run on SIGTERM before stopping the child (implies --supervise)")
+	flags.DurationVar(&o.StopDelay, "stop-delay", 0, "Delay between SIGTERM and stopping the child (implies --supervise)")
+	flags.StringVar(&o.StopSignal, "stop-signal", "TERM", "Signal sent to the child to stop it (implies --supervise)")
+	flags.DurationVar(&o.KillAfter, "kill-after", defaultKillAfter, "Kill the child if it's still running this long after SIGTERM, 0 to disable, defaults to ECS_CONTAINER_STOP_TIMEOUT minus 1s if set (implies --supervise)")
+	flags.StringSliceVar(&o.MapSignals, "map-signal", nil, "Rewrite received signal before forwarding, e.g. TERM:QUIT or HUP:DROP (implies --supervise)")
+	flags.StringVar(&o.Restart, "restart", string(supervisor.RestartNever), "Restart the child when it exits: never, on-failure or always (implies --supervise)")
+	flags.IntVar(&o.MaxRestarts, "max-restarts", defaultMaxRestarts, "Give up after this many restarts within --restart-window, 0 for unlimited (implies --supervise)")
+	flags.DurationVar(&o.RestartWindow, "restart-window", defaultRestartWindow, "Window restarts are counted within, 0 to count all restarts (implies --supervise)")
+	flags.DurationVar(&o.RestartBackoff, "restart-backoff", defaultRestartBackoff, "Delay before restart, doubled for each restart within --restart-window (implies --supervise)")
+	flags.DurationVar(&o.RestartMaxBackoff, "restart-max-backoff", defaultRestartMaxBackoff, "Maximum delay before restart (implies --supervise)")
+}
+
+// IsEnabled returns true if --supervise or any of the flags implying it were given.
+func (o *superviseOptions) IsEnabled(flags *pflag.FlagSet) bool {
+	implied := []string{
+		"pre-stop", "stop-delay", "stop-signal", "kill-after", "map-signal",
+		"restart", "max-restarts", "restart-window", "restart-backoff", "restart-max-backoff",
+		"log-format", "multiline", "multiline-start", "multiline-timeout", "multiline-max-size",
+		"secrets-refresh", "secrets-on-change",
+		"report", "report-file", "report-lines",
+		"memory-warn", "memory-signal-at", "memory-signal", "memory-interval",
+	}
+
+	return o.Enabled || slices.ContainsFunc(implied, flags.Changed)
+}
+
+// Config returns supervisor configuration without the command itself.
+func (o *superviseOptions) Config(flags *pflag.FlagSet, lookPath func(file string) (string, error)) (supervisor.Config, error) {
+	config := supervisor.Config{
+		Stop:      supervisor.StopConfig{Delay: o.StopDelay, KillAfter: o.KillAfter},
+		SignalMap: map[syscall.Signal]syscall.Signal{},
+	}
+
+	if !flags.Changed("kill-after") {
+		config.Stop.KillAfter = getDefaultKillAfter()
+	}
+
+	for _, mapping := range o.MapSignals {
+		from, to, err := supervisor.ParseSignalMapping(mapping)
+		if err != nil {
+			return config, fmt.Errorf("invalid --map-signal: %w", err)
+		}
+
+		config.SignalMap[from] = to
+	}
+
+	sig, err := supervisor.ParseSignal(o.StopSignal)
+	if err != nil {
+		return config, fmt.Errorf("invalid --stop-signal: %w", err)
+	}
+
+	// SIGTERM mapping defines the stop signal, unless it's given explicitly.
+	if to, ok := config.SignalMap[unix.SIGTERM]; ok && to != 0 && !flags.Changed("stop-signal") {
+		sig = to
+	}
+
+	config.Stop.Signal = sig
+
+	policy, err := supervisor.ParseRestartPolicy(o.Restart)
+	if err != nil {
+		return config, fmt.Errorf("invalid --restart: %w", err)
+	}
+
+	if o.MaxRestarts < 0 {
+		return config, errors.New("invalid --max-restarts: must not be negative")
+	}
+
+	config.Restart = supervisor.RestartConfig{
+		Policy:      policy,
+		MaxRestarts: o.MaxRestarts,
+		Window:      o.RestartWindow,
+		Backoff:     o.RestartBackoff,
+		MaxBackoff:  o.RestartMaxBackoff,
+	}
+
+	if o.PreStop != "" {
+		hook, err := parseCommand(o.PreStop, lookPath)
+		if err != nil {
+			return config, fmt.Errorf("invalid --pre-stop: %w", err)
+		}
+
+		config.Stop.PreStop = hook
+	}
+
+	return config, nil
+}
+
+// parseCommand splits command line into arguments and resolves the executable.
+func parseCommand(command string, lookPath func(file string) (string, error)) (*supervisor.Command, error) {
+	args, err := shellwords.Split(command)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+
+	path, err := lookPath(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return &supervisor.Command{Path: path, Args: args}, nil
+}
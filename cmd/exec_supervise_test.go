@@ -0,0 +1,403 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ixti/ecs-task-helper/pkg/container_metadata"
+	"github.com/ixti/ecs-task-helper/pkg/supervisor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func testSuperviseDeps(capturedConfig *supervisor.Config) *execCmdDeps {
+	return &execCmdDeps{
+		metadataCmdDeps: metadataCmdDeps{
+			FetchMetadata: func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+				return testMetadata(), nil
+			},
+			Timeout: 5 * time.Second,
+		},
+		Environ:  func() []string { return []string{"PATH=/usr/bin"} },
+		LookPath: func(file string) (string, error) { return "/bin/" + file, nil },
+		Exec: func(argv0 string, argv []string, envv []string) error {
+			return errors.New("Exec must not be called in supervise mode")
+		},
+		Executable: func() (string, error) { return "/bin/ecstatic", nil },
+		Supervise: func(ctx context.Context, s *supervisor.Supervisor) (*supervisor.Result, error) {
+			*capturedConfig = s.Config()
+			return &supervisor.Result{ExitCode: 0}, nil
+		},
+	}
+}
+
+func TestGetDefaultKillAfter(t *testing.T) {
+	t.Run("returns default when env var is not set", func(t *testing.T) {
+		t.Setenv("ECS_CONTAINER_STOP_TIMEOUT", "")
+
+		assert.Equal(t, 29*time.Second, getDefaultKillAfter())
+	})
+
+	t.Run("parses valid duration from env var", func(t *testing.T) {
+		t.Setenv("ECS_CONTAINER_STOP_TIMEOUT", "2m")
+
+		assert.Equal(t, 119*time.Second, getDefaultKillAfter())
+	})
+
+	t.Run("returns default when env var is invalid", func(t *testing.T) {
+		t.Setenv("ECS_CONTAINER_STOP_TIMEOUT", "not-a-duration")
+
+		assert.Equal(t, 29*time.Second, getDefaultKillAfter())
+	})
+
+	t.Run("never returns negative duration", func(t *testing.T) {
+		t.Setenv("ECS_CONTAINER_STOP_TIMEOUT", "500ms")
+
+		assert.Equal(t, time.Duration(0), getDefaultKillAfter())
+	})
+}
+
+func TestNewExecCommand_Stop(t *testing.T) {
+	t.Run("with stop flags configures stop sequence", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var config supervisor.Config
+
+		cmd := NewExecCommand(testSuperviseDeps(&config))
+		cmd.SetArgs([]string{
+			"--pre-stop", "curl -s -X POST 'http://localhost:8080/-/drain'",
+			"--stop-delay", "15s",
+			"--stop-signal", "QUIT",
+			"--kill-after", "25s",
+			"nginx",
+		})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Equal(supervisor.StopConfig{
+			PreStop: &supervisor.Command{
+				Path: "/bin/curl",
+				Args: []string{"curl", "-s", "-X", "POST", "http://localhost:8080/-/drain"},
+			},
+			Delay:     15 * time.Second,
+			Signal:    unix.SIGQUIT,
+			KillAfter: 25 * time.Second,
+		}, config.Stop)
+	})
+
+	t.Run("stop flags imply --supervise", func(t *testing.T) {
+		for _, flag := range []string{"--pre-stop=true", "--stop-delay=1s", "--stop-signal=INT", "--kill-after=1s", "--map-signal=HUP:USR1"} {
+			t.Run(flag, func(t *testing.T) {
+				var config supervisor.Config
+
+				cmd := NewExecCommand(testSuperviseDeps(&config))
+				cmd.SetArgs([]string{flag, "sh"})
+
+				require.NoError(t, cmd.Execute())
+				assert.Equal(t, "/bin/sh", config.Path)
+			})
+		}
+	})
+
+	t.Run("with defaults", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		t.Setenv("ECS_CONTAINER_STOP_TIMEOUT", "")
+
+		var config supervisor.Config
+
+		cmd := NewExecCommand(testSuperviseDeps(&config))
+		cmd.SetArgs([]string{"--supervise", "sh"})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Equal(supervisor.StopConfig{Signal: unix.SIGTERM, KillAfter: 29 * time.Second}, config.Stop)
+	})
+
+	t.Run("with ECS_CONTAINER_STOP_TIMEOUT set after flags are registered", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var config supervisor.Config
+
+		cmd := NewExecCommand(testSuperviseDeps(&config))
+		cmd.SetArgs([]string{"--supervise", "sh"})
+
+		t.Setenv("ECS_CONTAINER_STOP_TIMEOUT", "2m")
+
+		require.NoError(cmd.Execute())
+		assert.Equal(119*time.Second, config.Stop.KillAfter)
+	})
+
+	t.Run("with --kill-after ignores ECS_CONTAINER_STOP_TIMEOUT", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		t.Setenv("ECS_CONTAINER_STOP_TIMEOUT", "2m")
+
+		var config supervisor.Config
+
+		cmd := NewExecCommand(testSuperviseDeps(&config))
+		cmd.SetArgs([]string{"--kill-after", "29s", "sh"})
+
+		require.NoError(cmd.Execute())
+		assert.Equal(29*time.Second, config.Stop.KillAfter)
+	})
+
+	t.Run("with invalid stop signal returns error", func(t *testing.T) {
+		var config supervisor.Config
+
+		cmd := NewExecCommand(testSuperviseDeps(&config))
+		cmd.SetArgs([]string{"--stop-signal", "BOGUS", "sh"})
+
+		err := cmd.Execute()
+
+		assert.ErrorContains(t, err, "invalid --stop-signal: unknown signal: BOGUS")
+	})
+
+	t.Run("with unterminated quote in pre-stop returns error", func(t *testing.T) {
+		var config supervisor.Config
+
+		cmd := NewExecCommand(testSuperviseDeps(&config))
+		cmd.SetArgs([]string{"--pre-stop", "echo 'bye", "sh"})
+
+		err := cmd.Execute()
+
+		assert.ErrorContains(t, err, "invalid --pre-stop: unterminated quote")
+	})
+
+	t.Run("with empty pre-stop command returns error", func(t *testing.T) {
+		var config supervisor.Config
+
+		cmd := NewExecCommand(testSuperviseDeps(&config))
+		cmd.SetArgs([]string{"--pre-stop", "  ", "sh"})
+
+		err := cmd.Execute()
+
+		assert.ErrorContains(t, err, "invalid --pre-stop: empty command")
+	})
+}
+
+func TestNewExecCommand_SignalMap(t *testing.T) {
+	t.Run("with mappings configures signal map", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var config supervisor.Config
+
+		cmd := NewExecCommand(testSuperviseDeps(&config))
+		cmd.SetArgs([]string{"--map-signal", "HUP:USR1", "--map-signal", "USR2:DROP,WINCH:DROP", "nginx"})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Equal(map[syscall.Signal]syscall.Signal{
+			unix.SIGHUP:   unix.SIGUSR1,
+			unix.SIGUSR2:  0,
+			unix.SIGWINCH: 0,
+		}, config.SignalMap)
+		assert.Equal(unix.SIGTERM, config.Stop.Signal)
+	})
+
+	t.Run("with TERM mapping uses it as stop signal", func(t *testing.T) {
+		var config supervisor.Config
+
+		cmd := NewExecCommand(testSuperviseDeps(&config))
+		cmd.SetArgs([]string{"--map-signal", "TERM:QUIT", "nginx"})
+
+		require.NoError(t, cmd.Execute())
+		assert.Equal(t, unix.SIGQUIT, config.Stop.Signal)
+	})
+
+	t.Run("with TERM mapping and explicit stop signal", func(t *testing.T) {
+		var config supervisor.Config
+
+		cmd := NewExecCommand(testSuperviseDeps(&config))
+		cmd.SetArgs([]string{"--map-signal", "TERM:QUIT", "--stop-signal", "INT", "nginx"})
+
+		require.NoError(t, cmd.Execute())
+		assert.Equal(t, unix.SIGINT, config.Stop.Signal)
+	})
+
+	t.Run("with invalid mapping returns error", func(t *testing.T) {
+		var config supervisor.Config
+
+		cmd := NewExecCommand(testSuperviseDeps(&config))
+		cmd.SetArgs([]string{"--map-signal", "TERM", "nginx"})
+
+		err := cmd.Execute()
+
+		assert.ErrorContains(t, err, "invalid --map-signal: invalid signal mapping: TERM")
+	})
+}
+
+func TestNewExecCommand_Restart(t *testing.T) {
+	t.Run("with restart flags configures restart policy", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var config supervisor.Config
+
+		cmd := NewExecCommand(testSuperviseDeps(&config))
+		cmd.SetArgs([]string{
+			"--restart", "on-failure",
+			"--max-restarts", "3",
+			"--restart-window", "1m",
+			"--restart-backoff", "2s",
+			"--restart-max-backoff", "10s",
+			"nginx",
+		})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Equal(supervisor.RestartOnFailure, config.Restart.Policy)
+		assert.Equal(3, config.Restart.MaxRestarts)
+		assert.Equal(time.Minute, config.Restart.Window)
+		assert.Equal(2*time.Second, config.Restart.Backoff)
+		assert.Equal(10*time.Second, config.Restart.MaxBackoff)
+		assert.NotNil(config.Restart.Prepare)
+		assert.Contains(config.Env, "ECS_RESTART_ATTEMPT=0")
+	})
+
+	t.Run("restart flags imply --supervise", func(t *testing.T) {
+		for _, flag := range []string{"--restart=always", "--max-restarts=1", "--restart-window=1m", "--restart-backoff=1s", "--restart-max-backoff=1s"} {
+			t.Run(flag, func(t *testing.T) {
+				var config supervisor.Config
+
+				cmd := NewExecCommand(testSuperviseDeps(&config))
+				cmd.SetArgs([]string{flag, "nginx"})
+
+				require.NoError(t, cmd.Execute())
+				assert.Equal(t, "/bin/nginx", config.Path)
+			})
+		}
+	})
+
+	t.Run("with defaults", func(t *testing.T) {
+		assert := assert.New(t)
+
+		var config supervisor.Config
+
+		cmd := NewExecCommand(testSuperviseDeps(&config))
+		cmd.SetArgs([]string{"--supervise", "nginx"})
+
+		assert.NoError(cmd.Execute())
+		assert.Equal(supervisor.RestartNever, config.Restart.Policy)
+		assert.Equal(5, config.Restart.MaxRestarts)
+		assert.Nil(config.Restart.Prepare)
+		assert.NotContains(config.Env, "ECS_RESTART_ATTEMPT=0")
+	})
+
+	t.Run("with restart re-fetches metadata", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var config supervisor.Config
+
+		d := testSuperviseDeps(&config)
+		cmd := NewExecCommand(d)
+		cmd.SetArgs([]string{"--restart", "always", "--unset", "ECS_CLUSTER_NAME", "nginx"})
+
+		require.NoError(cmd.Execute())
+
+		d.FetchMetadata = func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+			metadata := testMetadata()
+			metadata.TaskDefinitionVersion = "25"
+
+			return metadata, nil
+		}
+
+		env := config.Restart.Prepare(2, config.Env)
+
+		assert.Contains(env, "ECS_TASK_DEFINITION_VERSION=25")
+		assert.Contains(env, "ECS_RESTART_ATTEMPT=2")
+		assert.NotContains(env, "ECS_RESTART_ATTEMPT=0")
+		assert.NotContains(env, "ECS_CLUSTER_NAME=default")
+	})
+
+	t.Run("with restart and --no-override refreshes metadata variables", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var config supervisor.Config
+
+		d := testSuperviseDeps(&config)
+		d.Environ = func() []string { return []string{"ECS_CLUSTER_NAME=local"} }
+
+		cmd := NewExecCommand(d)
+		cmd.SetArgs([]string{"--restart", "always", "--no-override", "nginx"})
+
+		require.NoError(cmd.Execute())
+		assert.Contains(config.Env, "ECS_TASK_DEFINITION_VERSION=24")
+
+		d.FetchMetadata = func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+			metadata := testMetadata()
+			metadata.TaskDefinitionVersion = "25"
+
+			return metadata, nil
+		}
+
+		env := config.Restart.Prepare(1, config.Env)
+
+		assert.Contains(env, "ECS_TASK_DEFINITION_VERSION=25")
+		assert.NotContains(env, "ECS_TASK_DEFINITION_VERSION=24")
+		assert.Contains(env, "ECS_CLUSTER_NAME=local")
+		assert.Contains(env, "ECS_RESTART_ATTEMPT=1")
+	})
+
+	t.Run("with restart keeps metadata on fetch error", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var config supervisor.Config
+
+		d := testSuperviseDeps(&config)
+		cmd := NewExecCommand(d)
+		cmd.SetArgs([]string{"--restart", "always", "nginx"})
+
+		require.NoError(cmd.Execute())
+
+		d.FetchMetadata = func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+			return nil, errors.New("connection refused")
+		}
+
+		env := config.Restart.Prepare(1, config.Env)
+
+		assert.Contains(env, "ECS_TASK_DEFINITION_VERSION=24")
+		assert.Contains(env, "ECS_RESTART_ATTEMPT=1")
+	})
+
+	t.Run("with invalid restart policy returns error", func(t *testing.T) {
+		var config supervisor.Config
+
+		cmd := NewExecCommand(testSuperviseDeps(&config))
+		cmd.SetArgs([]string{"--restart", "sometimes", "nginx"})
+
+		err := cmd.Execute()
+
+		assert.EqualError(t, err, "invalid --restart: unknown restart policy: sometimes")
+	})
+
+	t.Run("with negative max restarts returns error", func(t *testing.T) {
+		var config supervisor.Config
+
+		cmd := NewExecCommand(testSuperviseDeps(&config))
+		cmd.SetArgs([]string{"--max-restarts=-1", "nginx"})
+
+		err := cmd.Execute()
+
+		assert.EqualError(t, err, "invalid --max-restarts: must not be negative")
+	})
+}
@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"github.com/ixti/ecs-task-helper/pkg/container_metadata"
+	"github.com/ixti/ecs-task-helper/pkg/environ"
+	"github.com/ixti/ecs-task-helper/pkg/supervisor"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -114,6 +116,68 @@ func TestNewExecCommand(t *testing.T) {
 		assert.Contains(capturedEnv, "ECS_CLUSTER_NAME=")
 	})
 
+	t.Run("with fetch error and --metadata=required does not exec", func(t *testing.T) {
+		assert := assert.New(t)
+
+		var capturedEnv []string
+
+		fetchErr := errors.New("network error")
+		deps := &execCmdDeps{
+			metadataCmdDeps: metadataCmdDeps{
+				FetchMetadata: func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+					return nil, fetchErr
+				},
+				Timeout: 5 * time.Second,
+			},
+			Environ:  func() []string { return []string{"PATH=/usr/bin"} },
+			LookPath: func(file string) (string, error) { return "/bin/" + file, nil },
+			Exec: func(argv0 string, argv []string, envv []string) error {
+				capturedEnv = envv
+				return nil
+			},
+		}
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--metadata", "required", "sh"})
+
+		err := cmd.Execute()
+
+		assert.ErrorIs(err, fetchErr)
+		assert.Nil(capturedEnv)
+	})
+
+	t.Run("with --metadata=skip does not fetch metadata", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var capturedEnv []string
+
+		deps := &execCmdDeps{
+			metadataCmdDeps: metadataCmdDeps{
+				FetchMetadata: func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+					t.Fatal("FetchMetadata must not be called")
+					return nil, nil
+				},
+				Timeout: 5 * time.Second,
+			},
+			Environ:  func() []string { return []string{"PATH=/usr/bin"} },
+			LookPath: func(file string) (string, error) { return "/bin/" + file, nil },
+			Exec: func(argv0 string, argv []string, envv []string) error {
+				capturedEnv = envv
+				return nil
+			},
+		}
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--metadata", "skip", "sh"})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Contains(capturedEnv, "PATH=/usr/bin")
+		assert.Contains(capturedEnv, "ECS_CONTAINER_NAME=")
+	})
+
 	t.Run("with LookPath error returns error", func(t *testing.T) {
 		assert := assert.New(t)
 
@@ -224,6 +288,251 @@ func TestNewExecCommand(t *testing.T) {
 		assert.Error(err)
 	})
 
+	t.Run("with --otel merges OpenTelemetry resource attributes", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var capturedEnv []string
+
+		deps := &execCmdDeps{
+			metadataCmdDeps: metadataCmdDeps{
+				FetchMetadata: func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+					return testMetadata(), nil
+				},
+				Timeout: 5 * time.Second,
+			},
+			Environ:  func() []string { return []string{"OTEL_RESOURCE_ATTRIBUTES=cloud.region=eu-central-1"} },
+			LookPath: func(file string) (string, error) { return "/bin/" + file, nil },
+			Exec: func(argv0 string, argv []string, envv []string) error {
+				capturedEnv = envv
+				return nil
+			},
+		}
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--otel", "sh"})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Contains(capturedEnv, "OTEL_SERVICE_NAME=curl")
+
+		attrs, ok := environ.Lookup(capturedEnv, "OTEL_RESOURCE_ATTRIBUTES")
+		require.True(ok)
+		assert.Contains(attrs, "cloud.region=eu-central-1,cloud.provider=aws,")
+		assert.Contains(attrs, "aws.ecs.task.family=curltest")
+	})
+
+	t.Run("without --otel leaves OpenTelemetry variables untouched", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var capturedEnv []string
+
+		deps := &execCmdDeps{
+			metadataCmdDeps: metadataCmdDeps{
+				FetchMetadata: func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+					return testMetadata(), nil
+				},
+				Timeout: 5 * time.Second,
+			},
+			Environ:  func() []string { return nil },
+			LookPath: func(file string) (string, error) { return "/bin/" + file, nil },
+			Exec: func(argv0 string, argv []string, envv []string) error {
+				capturedEnv = envv
+				return nil
+			},
+		}
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"sh"})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+
+		_, ok := environ.Lookup(capturedEnv, "OTEL_RESOURCE_ATTRIBUTES")
+		assert.False(ok)
+	})
+
+	t.Run("with --profile merges vendor variables", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var capturedEnv []string
+
+		deps := &execCmdDeps{
+			metadataCmdDeps: metadataCmdDeps{
+				FetchMetadata: func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+					return testMetadata(), nil
+				},
+				Timeout: 5 * time.Second,
+			},
+			Environ:  func() []string { return []string{"DD_ENV=staging"} },
+			LookPath: func(file string) (string, error) { return "/bin/" + file, nil },
+			Exec: func(argv0 string, argv []string, envv []string) error {
+				capturedEnv = envv
+				return nil
+			},
+		}
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--profile", "datadog", "sh"})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Contains(capturedEnv, "DD_ENV=staging")
+		assert.Contains(capturedEnv, "DD_SERVICE=curl")
+		assert.Contains(capturedEnv, "DD_VERSION=latest")
+	})
+
+	t.Run("with unknown profile returns error", func(t *testing.T) {
+		assert := assert.New(t)
+
+		deps := &execCmdDeps{
+			metadataCmdDeps: metadataCmdDeps{
+				FetchMetadata: func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+					return testMetadata(), nil
+				},
+				Timeout: 5 * time.Second,
+			},
+			Environ:  func() []string { return nil },
+			LookPath: func(file string) (string, error) { return "/bin/" + file, nil },
+			Exec: func(argv0 string, argv []string, envv []string) error {
+				return nil
+			},
+		}
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--profile", "unknown", "sh"})
+
+		err := cmd.Execute()
+
+		assert.ErrorContains(err, "unknown profile: unknown")
+	})
+
+	t.Run("passes flags after command name to the command", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var capturedArgv []string
+
+		deps := &execCmdDeps{
+			metadataCmdDeps: metadataCmdDeps{
+				FetchMetadata: func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+					return testMetadata(), nil
+				},
+				Timeout: 5 * time.Second,
+			},
+			Environ:  func() []string { return nil },
+			LookPath: func(file string) (string, error) { return "/bin/" + file, nil },
+			Exec: func(argv0 string, argv []string, envv []string) error {
+				capturedArgv = argv
+				return nil
+			},
+		}
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"sh", "-c", "echo --otel"})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Equal([]string{"/bin/sh", "-c", "echo --otel"}, capturedArgv)
+	})
+
+	t.Run("with --supervise runs supervised child", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var capturedConfig supervisor.Config
+
+		deps := &execCmdDeps{
+			metadataCmdDeps: metadataCmdDeps{
+				FetchMetadata: func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+					return testMetadata(), nil
+				},
+				Timeout: 5 * time.Second,
+			},
+			Environ:  func() []string { return []string{"PATH=/usr/bin"} },
+			LookPath: func(file string) (string, error) { return "/bin/" + file, nil },
+			Exec: func(argv0 string, argv []string, envv []string) error {
+				t.Fatal("Exec must not be called in supervise mode")
+				return nil
+			},
+			Supervise: func(ctx context.Context, s *supervisor.Supervisor) (*supervisor.Result, error) {
+				capturedConfig = s.Config()
+				return &supervisor.Result{ExitCode: 0}, nil
+			},
+		}
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--supervise", "sh", "-c", "echo hello"})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Equal("/bin/sh", capturedConfig.Path)
+		assert.Equal([]string{"/bin/sh", "-c", "echo hello"}, capturedConfig.Args)
+		assert.Contains(capturedConfig.Env, "PATH=/usr/bin")
+		assert.Contains(capturedConfig.Env, "ECS_CONTAINER_NAME=curl")
+	})
+
+	t.Run("with --supervise propagates child exit code", func(t *testing.T) {
+		assert := assert.New(t)
+
+		deps := &execCmdDeps{
+			metadataCmdDeps: metadataCmdDeps{
+				FetchMetadata: func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+					return testMetadata(), nil
+				},
+				Timeout: 5 * time.Second,
+			},
+			Environ:  func() []string { return nil },
+			LookPath: func(file string) (string, error) { return "/bin/" + file, nil },
+			Supervise: func(ctx context.Context, s *supervisor.Supervisor) (*supervisor.Result, error) {
+				return &supervisor.Result{ExitCode: 143}, nil
+			},
+		}
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--supervise", "sh"})
+
+		err := cmd.Execute()
+
+		var exitErr *exitCodeError
+		if assert.ErrorAs(err, &exitErr) {
+			assert.Equal(143, exitErr.code)
+		}
+	})
+
+	t.Run("with --supervise and start error returns error", func(t *testing.T) {
+		assert := assert.New(t)
+
+		startErr := errors.New("failed to start")
+		deps := &execCmdDeps{
+			metadataCmdDeps: metadataCmdDeps{
+				FetchMetadata: func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+					return testMetadata(), nil
+				},
+				Timeout: 5 * time.Second,
+			},
+			Environ:  func() []string { return nil },
+			LookPath: func(file string) (string, error) { return "/bin/" + file, nil },
+			Supervise: func(ctx context.Context, s *supervisor.Supervisor) (*supervisor.Result, error) {
+				return nil, startErr
+			},
+		}
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--supervise", "sh"})
+
+		err := cmd.Execute()
+
+		assert.ErrorIs(err, startErr)
+	})
+
 	t.Run("with nil deps uses defaults", func(t *testing.T) {
 		cmd := NewExecCommand(nil)
 
@@ -231,3 +540,108 @@ func TestNewExecCommand(t *testing.T) {
 		assert.NotNil(t, cmd.RunE)
 	})
 }
+
+func TestNewExecCommand_Expand(t *testing.T) {
+	t.Run("with --expand-var expands matching environment and arguments", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var capturedEnv, capturedArgv []string
+
+		deps := testEnvDeps([]string{"LOG_PREFIX=${ECS_CONTAINER_NAME}-${ECS_TASK_ID}", "LITERAL=$${HOME}"}, &capturedEnv)
+		deps.Exec = func(argv0 string, argv []string, envv []string) error {
+			capturedEnv, capturedArgv = envv, argv
+			return nil
+		}
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--expand-var", "LOG_*", "--expand-var", "LITERAL", "sh", "--node-name=${ECS_TASK_ID}", "${LOG_PREFIX:-x}", "$${ECS_TASK_ID}"})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Contains(capturedEnv, "LOG_PREFIX=curl-8f03e41243824aea923aca126495f665")
+		assert.Contains(capturedEnv, "LITERAL=${HOME}")
+		assert.Equal([]string{"/bin/sh", "--node-name=8f03e41243824aea923aca126495f665", "curl-8f03e41243824aea923aca126495f665", "${ECS_TASK_ID}"}, capturedArgv)
+	})
+
+	t.Run("with --expand keeps inherited values as is", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var capturedEnv, capturedArgv []string
+
+		deps := testEnvDeps([]string{"DB_PASSWORD=pa$$w${rd", "LOG_PREFIX=${ECS_TASK_ID}"}, &capturedEnv)
+		deps.Exec = func(argv0 string, argv []string, envv []string) error {
+			capturedEnv, capturedArgv = envv, argv
+			return nil
+		}
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--expand", "sh", "${LOG_PREFIX}"})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Contains(capturedEnv, "DB_PASSWORD=pa$$w${rd")
+		assert.Contains(capturedEnv, "LOG_PREFIX=${ECS_TASK_ID}")
+		assert.Equal([]string{"/bin/sh", "${ECS_TASK_ID}"}, capturedArgv)
+	})
+
+	t.Run("without --expand leaves references untouched", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var capturedEnv []string
+
+		deps := testEnvDeps([]string{"LOG_PREFIX=${ECS_CONTAINER_NAME}"}, &capturedEnv)
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"sh"})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Contains(capturedEnv, "LOG_PREFIX=${ECS_CONTAINER_NAME}")
+	})
+
+	t.Run("with required variable missing returns error", func(t *testing.T) {
+		assert := assert.New(t)
+
+		var capturedEnv []string
+
+		deps := testEnvDeps(nil, &capturedEnv)
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--expand", "sh", "${DATABASE_URL:?is required}"})
+
+		err := cmd.Execute()
+
+		assert.EqualError(err, `failed to expand argument "${DATABASE_URL:?is required}": DATABASE_URL: is required`)
+		assert.Nil(capturedEnv)
+	})
+
+	t.Run("with invalid --expand-var returns error", func(t *testing.T) {
+		var capturedEnv []string
+
+		cmd := NewExecCommand(testEnvDeps(nil, &capturedEnv))
+		cmd.SetArgs([]string{"--expand-var", "[", "sh"})
+
+		err := cmd.Execute()
+
+		assert.EqualError(t, err, "invalid --expand-var [: syntax error in pattern")
+	})
+
+	t.Run("with reference cycle returns error", func(t *testing.T) {
+		var capturedEnv []string
+
+		deps := testEnvDeps([]string{"A=${B}", "B=${A}"}, &capturedEnv)
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--expand-var", "*", "sh"})
+
+		err := cmd.Execute()
+
+		assert.ErrorContains(t, err, "reference cycle: A -> B -> A")
+	})
+}
@@ -0,0 +1,47 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/ixti/ecs-task-helper/pkg/cgroup"
+	"github.com/ixti/ecs-task-helper/pkg/container_metadata"
+	"github.com/ixti/ecs-task-helper/pkg/tuning"
+	"github.com/spf13/pflag"
+)
+
+type tuneOptions struct {
+	Runtimes []string
+}
+
+func (o *tuneOptions) AddFlags(flags *pflag.FlagSet) {
+	flags.StringSliceVar(&o.Runtimes, "tune", nil, "Tune runtimes for the container CPU and memory limits: "+strings.Join(tuning.Runtimes(), ", "))
+}
+
+func (o *tuneOptions) Validate() error {
+	if err := tuning.Validate(o.Runtimes); err != nil {
+		return fmt.Errorf("invalid --tune: %w", err)
+	}
+
+	return nil
+}
+
+// EnvironWith returns env with runtime settings derived from limits of the
+// container, read from metadata or the cgroup mounted at cgroupRoot.
+func (o *tuneOptions) EnvironWith(env []string, metadata *container_metadata.Metadata, cgroupRoot string) []string {
+	if len(o.Runtimes) == 0 {
+		return env
+	}
+
+	limits := tuning.Detect(metadata, cgroup.Open(cgroupRoot))
+	slog.Debug("Tuning runtimes", "runtimes", o.Runtimes, "cpu", limits.CPU, "memory", limits.Memory)
+
+	// Runtimes are validated before the command is prepared.
+	env, _ = tuning.EnvironWith(env, limits, o.Runtimes)
+
+	return env
+}
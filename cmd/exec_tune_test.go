@@ -0,0 +1,79 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ixti/ecs-task-helper/pkg/container_metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewExecCommand_Tune(t *testing.T) {
+	t.Run("with --tune uses metadata limits", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var capturedEnv []string
+
+		deps := testEnvDeps([]string{"GOMAXPROCS=8"}, &capturedEnv)
+		deps.FetchMetadata = func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+			metadata := testMetadata()
+			metadata.Limits = &container_metadata.Limits{CPU: 2, Memory: 1024}
+
+			return metadata, nil
+		}
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--tune", "go,web", "sh"})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Contains(capturedEnv, "GOMAXPROCS=8")
+		assert.Contains(capturedEnv, "GOMEMLIMIT=921MiB")
+		assert.Contains(capturedEnv, "WEB_CONCURRENCY=2")
+	})
+
+	t.Run("with --tune falls back to cgroup limits", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var capturedEnv []string
+
+		root := t.TempDir()
+		require.NoError(os.WriteFile(filepath.Join(root, "cgroup.controllers"), []byte("cpu memory\n"), 0o644))
+		require.NoError(os.WriteFile(filepath.Join(root, "cpu.max"), []byte("50000 100000\n"), 0o644))
+		require.NoError(os.WriteFile(filepath.Join(root, "memory.max"), []byte("536870912\n"), 0o644))
+
+		deps := testEnvDeps(nil, &capturedEnv)
+		deps.CgroupRoot = root
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--tune", "node", "sh"})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Contains(capturedEnv, "UV_THREADPOOL_SIZE=4")
+		assert.Contains(capturedEnv, "NODE_OPTIONS=--max-old-space-size=384")
+	})
+
+	t.Run("with unknown runtime returns error", func(t *testing.T) {
+		var capturedEnv []string
+
+		cmd := NewExecCommand(testEnvDeps(nil, &capturedEnv))
+		cmd.SetArgs([]string{"--tune", "cobol", "sh"})
+
+		err := cmd.Execute()
+
+		assert.EqualError(t, err, "invalid --tune: unknown runtime: cobol")
+		assert.Nil(t, capturedEnv)
+	})
+}
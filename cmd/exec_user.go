@@ -0,0 +1,61 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ixti/ecs-task-helper/pkg/identity"
+	"github.com/ixti/ecs-task-helper/pkg/supervisor"
+	"github.com/spf13/pflag"
+)
+
+type userOptions struct {
+	User string
+}
+
+func (o *userOptions) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&o.User, "user", "", "Run command as user[:group], given by names or numeric IDs")
+}
+
+// Resolve returns identity to run the command as, or nil if --user wasn't given.
+func (o *userOptions) Resolve(lookupUser func(spec string) (*identity.Identity, error)) (*identity.Identity, error) {
+	if o.User == "" {
+		return nil, nil
+	}
+
+	id, err := lookupUser(o.User)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --user: %w", err)
+	}
+
+	return id, nil
+}
+
+// superviseAs configures supervisor to start the child as user. Unprivileged
+// supervisor can't change identity of the child, so drop only verifies that
+// the supervisor already runs as user.
+func superviseAs(config *supervisor.Config, user *identity.Identity, drop func(id *identity.Identity) error) error {
+	if os.Geteuid() != 0 {
+		return drop(user)
+	}
+
+	config.Credential = user.Credential()
+
+	return nil
+}
+
+// chownSecretsFile makes secrets file readable by the user the command runs as.
+func chownSecretsFile(path string, user *identity.Identity) error {
+	if user == nil {
+		return nil
+	}
+
+	if err := os.Chown(path, user.UID, user.GID); err != nil {
+		return fmt.Errorf("failed to change owner of secrets file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/ixti/ecs-task-helper/pkg/identity"
+	"github.com/ixti/ecs-task-helper/pkg/supervisor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLookupUser(spec string) (*identity.Identity, error) {
+	if spec != "app" {
+		return nil, fmt.Errorf("unknown user: %s", spec)
+	}
+
+	return &identity.Identity{Name: "app", UID: os.Getuid(), GID: os.Getgid(), Groups: []int{os.Getgid()}, Home: "/home/app"}, nil
+}
+
+func TestNewExecCommand_User(t *testing.T) {
+	t.Run("with --user drops privileges before exec", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var (
+			capturedEnv []string
+			calls       []string
+		)
+
+		deps := testEnvDeps([]string{"HOME=/root", "USER=root"}, &capturedEnv)
+		deps.LookupUser = testLookupUser
+		deps.DropPrivileges = func(id *identity.Identity) error {
+			calls = append(calls, "drop:"+id.Name)
+			return nil
+		}
+
+		exec := deps.Exec
+		deps.Exec = func(argv0 string, argv []string, envv []string) error {
+			calls = append(calls, "exec")
+			return exec(argv0, argv, envv)
+		}
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--user", "app", "sh"})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Equal([]string{"drop:app", "exec"}, calls)
+		assert.Contains(capturedEnv, "HOME=/home/app")
+		assert.Contains(capturedEnv, "USER=app")
+	})
+
+	t.Run("with unknown user returns error", func(t *testing.T) {
+		var capturedEnv []string
+
+		deps := testEnvDeps(nil, &capturedEnv)
+		deps.LookupUser = testLookupUser
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--user", "nobody", "sh"})
+
+		err := cmd.Execute()
+
+		assert.EqualError(t, err, "invalid --user: unknown user: nobody")
+		assert.Nil(t, capturedEnv)
+	})
+
+	t.Run("with failed drop does not exec", func(t *testing.T) {
+		var capturedEnv []string
+
+		deps := testEnvDeps(nil, &capturedEnv)
+		deps.LookupUser = testLookupUser
+		deps.DropPrivileges = func(id *identity.Identity) error {
+			return errors.New("privileges were not dropped")
+		}
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--user", "app", "sh"})
+
+		err := cmd.Execute()
+
+		assert.EqualError(t, err, "privileges were not dropped")
+		assert.Nil(t, capturedEnv)
+	})
+
+	t.Run("with --supervise starts child as user", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var (
+			config  supervisor.Config
+			dropped bool
+		)
+
+		deps := testSuperviseDeps(&config)
+		deps.LookupUser = testLookupUser
+		deps.DropPrivileges = func(id *identity.Identity) error {
+			dropped = true
+			return nil
+		}
+
+		cmd := NewExecCommand(deps)
+		cmd.SetArgs([]string{"--supervise", "--user", "app", "sh"})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Contains(config.Env, "HOME=/home/app")
+
+		// Unprivileged supervisor can only verify its own identity.
+		if os.Geteuid() == 0 {
+			assert.False(dropped)
+			assert.Equal(&syscall.Credential{Uid: uint32(os.Getuid()), Gid: uint32(os.Getgid()), Groups: []uint32{uint32(os.Getgid())}}, config.Credential)
+		} else {
+			assert.True(dropped)
+			assert.Nil(config.Credential)
+		}
+	})
+}
@@ -9,10 +9,14 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/ixti/ecs-task-helper/pkg/container_metadata"
+	"github.com/ixti/ecs-task-helper/pkg/profile"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 type metadataCmdDeps struct {
@@ -27,37 +31,170 @@ func defaultMetadataCmdDeps() *metadataCmdDeps {
 	}
 }
 
+const (
+	// metadataRequired policy aborts if metadata can't be retrieved.
+	metadataRequired = "required"
+	// metadataOptional policy continues without metadata if it can't be retrieved.
+	metadataOptional = "optional"
+	// metadataSkip policy doesn't retrieve metadata at all.
+	metadataSkip = "skip"
+
+	defaultMetadataPolicy = metadataOptional
+)
+
+// getDefaultMetadataPolicy returns metadata policy from ECSTATIC_METADATA.
+func getDefaultMetadataPolicy() string {
+	if v := os.Getenv("ECSTATIC_METADATA"); v != "" {
+		if isMetadataPolicy(v) {
+			return v
+		}
+
+		slog.Warn(
+			"Invalid ECSTATIC_METADATA, using default",
+			"value", v,
+			"default", defaultMetadataPolicy,
+		)
+	}
+
+	return defaultMetadataPolicy
+}
+
+func isMetadataPolicy(policy string) bool {
+	return policy == metadataRequired || policy == metadataOptional || policy == metadataSkip
+}
+
+type metadataOptions struct {
+	Policy string
+}
+
+func (o *metadataOptions) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&o.Policy, "metadata", getDefaultMetadataPolicy(), "What to do if ECS metadata can't be retrieved: required (abort), optional (continue without it) or skip (don't retrieve it)")
+}
+
+func (o *metadataOptions) Validate() error {
+	if !isMetadataPolicy(o.Policy) {
+		return fmt.Errorf("invalid --metadata: must be %s, %s or %s", metadataRequired, metadataOptional, metadataSkip)
+	}
+
+	return nil
+}
+
+// Fetch retrieves metadata following the policy. Returns nil without error
+// if metadata is skipped, or can't be retrieved and is optional.
+func (o *metadataOptions) Fetch(ctx context.Context, d *metadataCmdDeps) (*container_metadata.Metadata, error) {
+	if o.Policy == metadataSkip {
+		slog.Debug("Skipping ECS task metadata")
+		return nil, nil
+	}
+
+	metadata, err := d.FetchMetadata(ctx, d.Timeout)
+	if err == nil {
+		return metadata, nil
+	}
+
+	if o.Policy == metadataRequired {
+		slog.Error("Can't retrieve ECS task metadata", "error", err)
+		return nil, err
+	}
+
+	if errors.Is(err, container_metadata.ErrMissingMetadataURI) {
+		slog.Warn("Missing ECS metadata URI, continuing without metadata")
+	} else {
+		slog.Warn("Can't retrieve ECS task metadata, continuing without it", "error", err)
+	}
+
+	return nil, nil
+}
+
+type profileOptions struct {
+	Names []string
+	File  string
+}
+
+func (o *profileOptions) AddFlags(flags *pflag.FlagSet) {
+	flags.StringSliceVar(&o.Names, "profile", nil, "Vendor profiles to apply: datadog, sentry, newrelic, honeycomb, or user-defined")
+	flags.StringVar(&o.File, "profiles-file", "", "JSON file with user-defined profiles")
+}
+
+func (o *profileOptions) Resolve() ([]*profile.Profile, error) {
+	registry := profile.Builtin()
+
+	if o.File != "" {
+		if err := registry.LoadFile(o.File); err != nil {
+			return nil, err
+		}
+	}
+
+	return registry.Resolve(o.Names)
+}
+
 func NewMetadataCommand(d *metadataCmdDeps) *cobra.Command {
 	if d == nil {
 		d = defaultMetadataCmdDeps()
 	}
 
 	format := "env"
+	links := false
+	profileOpts := &profileOptions{}
+	metadataOpts := &metadataOptions{}
 
 	runE := func(cmd *cobra.Command, args []string) error {
-		metadata, err := d.FetchMetadata(cmd.Context(), d.Timeout)
+		if err := metadataOpts.Validate(); err != nil {
+			return err
+		}
 
+		profiles, err := profileOpts.Resolve()
 		if err != nil {
-			if errors.Is(err, container_metadata.ErrMissingMetadataURI) {
-				slog.Warn("Missing ECS metadata URI")
+			return err
+		}
 
-				if format == "json" {
-					fmt.Fprintln(cmd.OutOrStdout(), "{}")
-				}
+		metadata, err := metadataOpts.Fetch(cmd.Context(), d)
+		if err != nil {
+			return err
+		}
 
-				return nil
+		if metadata == nil {
+			if format == "json" {
+				fmt.Fprintln(cmd.OutOrStdout(), "{}")
 			}
 
-			slog.Error("Can't retrieve ECS task metadata", "error", err)
-			return err
+			return nil
 		}
 
 		switch format {
 		case "json":
-			data, _ := json.Marshal(metadata)
+			output := struct {
+				*container_metadata.Metadata
+				Links    *container_metadata.ConsoleLinks `json:"links,omitempty"`
+				Profiles map[string]map[string]string     `json:"profiles,omitempty"`
+			}{Metadata: metadata}
+
+			if links {
+				consoleLinks := metadata.ConsoleLinks()
+				output.Links = &consoleLinks
+			}
+
+			for _, p := range profiles {
+				if output.Profiles == nil {
+					output.Profiles = map[string]map[string]string{}
+				}
+
+				output.Profiles[p.Name] = map[string]string{}
+				for _, v := range p.Environ(metadata) {
+					key, value, _ := strings.Cut(v, "=")
+					output.Profiles[p.Name][key] = value
+				}
+			}
+
+			data, _ := json.Marshal(output)
 			fmt.Fprintln(cmd.OutOrStdout(), string(data))
 		case "env":
-			for _, v := range metadata.Environ() {
+			env := metadata.Environ()
+			if links {
+				env = append(env, metadata.ConsoleLinks().Environ()...)
+			}
+
+			for _, v := range profile.EnvironWith(env, metadata, profiles) {
 				fmt.Fprintln(cmd.OutOrStdout(), v)
 			}
 		}
@@ -74,6 +211,9 @@ func NewMetadataCommand(d *metadataCmdDeps) *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&format, "format", format, "Output format: env or json")
+	cmd.Flags().BoolVar(&links, "links", links, "Include AWS console links for task, task definition, cluster and service")
+	profileOpts.AddFlags(cmd.Flags())
+	metadataOpts.AddFlags(cmd.Flags())
 
 	return cmd
 }
@@ -7,6 +7,8 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -102,6 +104,165 @@ func TestNewMetadataCommand(t *testing.T) {
 		assert.Contains(out.String(), `"clusterName":"default"`)
 	})
 
+	t.Run("with --profile outputs profile variables", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		deps := &metadataCmdDeps{
+			FetchMetadata: func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+				return testMetadata(), nil
+			},
+			Timeout: 5 * time.Second,
+		}
+
+		cmd := NewMetadataCommand(deps)
+		cmd.SetArgs([]string{"--profile=datadog,sentry"})
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Contains(out.String(), "ECS_CONTAINER_NAME=curl\n")
+		assert.Contains(out.String(), "DD_ENV=default\n")
+		assert.Contains(out.String(), "DD_VERSION=latest\n")
+		assert.Contains(out.String(), "SENTRY_RELEASE=curltest@latest\n")
+	})
+
+	t.Run("with --profile and --format=json outputs profile variables", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		deps := &metadataCmdDeps{
+			FetchMetadata: func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+				return testMetadata(), nil
+			},
+			Timeout: 5 * time.Second,
+		}
+
+		cmd := NewMetadataCommand(deps)
+		cmd.SetArgs([]string{"--format=json", "--profile=datadog"})
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Contains(out.String(), `"containerName":"curl"`)
+		assert.Contains(out.String(), `"profiles":{"datadog":{"DD_ENV":"default","DD_SERVICE":"curl","DD_VERSION":"latest"}}`)
+	})
+
+	t.Run("with --profiles-file uses user-defined profiles", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		path := filepath.Join(t.TempDir(), "profiles.json")
+		require.NoError(os.WriteFile(path, []byte(`{"custom": {"APP_RELEASE": "{containerImageTag}"}}`), 0o644))
+
+		deps := &metadataCmdDeps{
+			FetchMetadata: func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+				return testMetadata(), nil
+			},
+			Timeout: 5 * time.Second,
+		}
+
+		cmd := NewMetadataCommand(deps)
+		cmd.SetArgs([]string{"--profiles-file", path, "--profile", "custom"})
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Contains(out.String(), "APP_RELEASE=latest\n")
+	})
+
+	t.Run("with unknown profile returns error", func(t *testing.T) {
+		assert := assert.New(t)
+
+		deps := &metadataCmdDeps{
+			FetchMetadata: func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+				return testMetadata(), nil
+			},
+			Timeout: 5 * time.Second,
+		}
+
+		cmd := NewMetadataCommand(deps)
+		cmd.SetArgs([]string{"--profile=unknown"})
+		cmd.SetOut(&bytes.Buffer{})
+
+		err := cmd.Execute()
+
+		assert.ErrorContains(err, "unknown profile: unknown")
+	})
+
+	t.Run("with --links outputs console links", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		deps := &metadataCmdDeps{
+			FetchMetadata: func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+				return testMetadata(), nil
+			},
+			Timeout: 5 * time.Second,
+		}
+
+		cmd := NewMetadataCommand(deps)
+		cmd.SetArgs([]string{"--links"})
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Contains(out.String(), "ECS_CONTAINER_NAME=curl\n")
+		assert.Contains(out.String(), "ECS_TASK_URL=https://us-west-2.console.aws.amazon.com/ecs/v2/clusters/default/tasks/8f03e41243824aea923aca126495f665/configuration?region=us-west-2\n")
+		assert.Contains(out.String(), "ECS_CLUSTER_URL=https://us-west-2.console.aws.amazon.com/ecs/v2/clusters/default/services?region=us-west-2\n")
+	})
+
+	t.Run("with --links and --format=json outputs console links", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		deps := &metadataCmdDeps{
+			FetchMetadata: func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+				return testMetadata(), nil
+			},
+			Timeout: 5 * time.Second,
+		}
+
+		cmd := NewMetadataCommand(deps)
+		cmd.SetArgs([]string{"--links", "--format=json"})
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Contains(out.String(), `"links":{"task":"https://us-west-2.console.aws.amazon.com/ecs/v2/clusters/default/tasks/8f03e41243824aea923aca126495f665/configuration?region=us-west-2"`)
+	})
+
+	t.Run("without --links omits console links", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		deps := &metadataCmdDeps{
+			FetchMetadata: func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+				return testMetadata(), nil
+			},
+			Timeout: 5 * time.Second,
+		}
+
+		cmd := NewMetadataCommand(deps)
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.NotContains(out.String(), "ECS_TASK_URL=")
+	})
+
 	t.Run("with missing metadata URI returns nil without error", func(t *testing.T) {
 		assert := assert.New(t)
 		require := require.New(t)
@@ -145,7 +306,7 @@ func TestNewMetadataCommand(t *testing.T) {
 		assert.Equal("{}\n", out.String())
 	})
 
-	t.Run("with fetch error returns error", func(t *testing.T) {
+	t.Run("with fetch error and --metadata=required returns error", func(t *testing.T) {
 		assert := assert.New(t)
 
 		fetchErr := errors.New("network error")
@@ -157,12 +318,143 @@ func TestNewMetadataCommand(t *testing.T) {
 		}
 
 		cmd := NewMetadataCommand(deps)
+		cmd.SetArgs([]string{"--metadata=required"})
 
 		err := cmd.Execute()
 
 		assert.ErrorIs(err, fetchErr)
 	})
 
+	t.Run("with missing metadata URI and --metadata=required returns error", func(t *testing.T) {
+		deps := &metadataCmdDeps{
+			FetchMetadata: func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+				return nil, container_metadata.ErrMissingMetadataURI
+			},
+			Timeout: 5 * time.Second,
+		}
+
+		cmd := NewMetadataCommand(deps)
+		cmd.SetArgs([]string{"--metadata=required"})
+
+		err := cmd.Execute()
+
+		assert.ErrorIs(t, err, container_metadata.ErrMissingMetadataURI)
+	})
+
+	t.Run("with fetch error outputs nothing", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		deps := &metadataCmdDeps{
+			FetchMetadata: func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+				return nil, errors.New("network error")
+			},
+			Timeout: 5 * time.Second,
+		}
+
+		cmd := NewMetadataCommand(deps)
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Empty(out.String())
+	})
+
+	t.Run("with --metadata=skip does not fetch metadata", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		deps := &metadataCmdDeps{
+			FetchMetadata: func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+				t.Fatal("FetchMetadata must not be called")
+				return nil, nil
+			},
+			Timeout: 5 * time.Second,
+		}
+
+		cmd := NewMetadataCommand(deps)
+		cmd.SetArgs([]string{"--metadata=skip", "--format=json"})
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Equal("{}\n", out.String())
+	})
+
+	t.Run("with missing metadata URI, --metadata=required and --format=json outputs nothing", func(t *testing.T) {
+		assert := assert.New(t)
+
+		deps := &metadataCmdDeps{
+			FetchMetadata: func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+				return nil, container_metadata.ErrMissingMetadataURI
+			},
+			Timeout: 5 * time.Second,
+		}
+
+		cmd := NewMetadataCommand(deps)
+		cmd.SetArgs([]string{"--metadata=required", "--format=json"})
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+
+		err := cmd.Execute()
+
+		assert.ErrorIs(err, container_metadata.ErrMissingMetadataURI)
+		assert.Empty(out.String())
+	})
+
+	t.Run("with --metadata=skip and --format=env outputs nothing", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		deps := &metadataCmdDeps{
+			FetchMetadata: func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+				t.Fatal("FetchMetadata must not be called")
+				return nil, nil
+			},
+			Timeout: 5 * time.Second,
+		}
+
+		cmd := NewMetadataCommand(deps)
+		cmd.SetArgs([]string{"--metadata=skip", "--format=env"})
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		assert.Empty(out.String())
+	})
+
+	t.Run("with invalid --metadata returns error", func(t *testing.T) {
+		cmd := NewMetadataCommand(&metadataCmdDeps{})
+		cmd.SetArgs([]string{"--metadata=always"})
+
+		err := cmd.Execute()
+
+		assert.EqualError(t, err, "invalid --metadata: must be required, optional or skip")
+	})
+
+	t.Run("with ECSTATIC_METADATA uses it as default policy", func(t *testing.T) {
+		t.Setenv("ECSTATIC_METADATA", "required")
+
+		deps := &metadataCmdDeps{
+			FetchMetadata: func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+				return nil, container_metadata.ErrMissingMetadataURI
+			},
+			Timeout: 5 * time.Second,
+		}
+
+		cmd := NewMetadataCommand(deps)
+
+		err := cmd.Execute()
+
+		assert.ErrorIs(t, err, container_metadata.ErrMissingMetadataURI)
+	})
+
 	t.Run("passes timeout to fetch function", func(t *testing.T) {
 		assert := assert.New(t)
 
@@ -210,3 +502,23 @@ func TestNewMetadataCommand(t *testing.T) {
 		assert.NotNil(t, cmd.RunE)
 	})
 }
+
+func TestGetDefaultMetadataPolicy(t *testing.T) {
+	t.Run("returns optional when env var is not set", func(t *testing.T) {
+		t.Setenv("ECSTATIC_METADATA", "")
+
+		assert.Equal(t, "optional", getDefaultMetadataPolicy())
+	})
+
+	t.Run("returns policy from env var", func(t *testing.T) {
+		t.Setenv("ECSTATIC_METADATA", "skip")
+
+		assert.Equal(t, "skip", getDefaultMetadataPolicy())
+	})
+
+	t.Run("returns default when env var is invalid", func(t *testing.T) {
+		t.Setenv("ECSTATIC_METADATA", "always")
+
+		assert.Equal(t, "optional", getDefaultMetadataPolicy())
+	})
+}
@@ -4,6 +4,8 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"time"
@@ -15,6 +17,16 @@ const defaultContainerMetadataTimeout = 5 * time.Second
 
 var version = "dev"
 
+// exitCodeError makes Execute exit with the given code without logging,
+// e.g. to propagate exit status of a supervised command.
+type exitCodeError struct {
+	code int
+}
+
+func (e *exitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
 func getFetchMetadataTimeout() time.Duration {
 	if v := os.Getenv("ECS_CONTAINER_METADATA_URI_V4_TIMEOUT"); v != "" {
 		if d, err := time.ParseDuration(v); err == nil {
@@ -40,13 +52,21 @@ func NewRootCommand() *cobra.Command {
 
 	cmd.AddCommand(NewMetadataCommand(nil))
 	cmd.AddCommand(NewExecCommand(nil))
+	cmd.AddCommand(NewExecChildCommand(nil))
+	cmd.AddCommand(NewRunCommand(nil))
 	cmd.AddCommand(NewCheckCommand(nil))
+	cmd.AddCommand(NewWaitCommand(nil))
 
 	return cmd
 }
 
 func Execute() {
 	if err := NewRootCommand().Execute(); err != nil {
+		var exitErr *exitCodeError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
+
 		slog.Error(err.Error())
 		os.Exit(1)
 	}
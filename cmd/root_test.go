@@ -76,6 +76,30 @@ func TestNewRootCommand(t *testing.T) {
 		assert.Contains(execCmd.Use, "exec")
 	})
 
+	t.Run("has run subcommand", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		cmd := NewRootCommand()
+
+		runCmd, _, err := cmd.Find([]string{"run"})
+
+		require.NoError(err)
+		assert.Equal("run", runCmd.Use)
+	})
+
+	t.Run("has wait subcommand", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		cmd := NewRootCommand()
+
+		waitCmd, _, err := cmd.Find([]string{"wait"})
+
+		require.NoError(err)
+		assert.Contains(waitCmd.Use, "wait")
+	})
+
 	t.Run("shows help without error", func(t *testing.T) {
 		assert := assert.New(t)
 		require := require.New(t)
@@ -0,0 +1,274 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"slices"
+	"time"
+
+	"github.com/ixti/ecs-task-helper/pkg/container_metadata"
+	"github.com/ixti/ecs-task-helper/pkg/environ"
+	"github.com/ixti/ecs-task-helper/pkg/linewriter"
+	"github.com/ixti/ecs-task-helper/pkg/procfile"
+	"github.com/ixti/ecs-task-helper/pkg/supervisor"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// prefixColors are ANSI colors of process output prefixes, assigned in the
+// order of process definition.
+var prefixColors = []string{"36", "33", "32", "35", "34", "96", "93", "92", "95", "94"}
+
+type runCmdDeps struct {
+	metadataCmdDeps
+	Environ  func() []string
+	LookPath func(file string) (string, error)
+	Run      func(ctx context.Context, g *supervisor.Group) (*supervisor.GroupResult, error)
+}
+
+func defaultRunCmdDeps() *runCmdDeps {
+	return &runCmdDeps{
+		metadataCmdDeps: *defaultMetadataCmdDeps(),
+		Environ:         os.Environ,
+		LookPath:        exec.LookPath,
+		Run: func(ctx context.Context, g *supervisor.Group) (*supervisor.GroupResult, error) {
+			return g.Run(ctx)
+		},
+	}
+}
+
+type runOptions struct {
+	Procfile   string
+	Processes  []string
+	Essential  []string
+	Color      string
+	StopSignal string
+	KillAfter  time.Duration
+}
+
+func (o *runOptions) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&o.Procfile, "procfile", "", "Read process definitions from a Procfile")
+	flags.StringArrayVar(&o.Processes, "process", nil, "Process definition as name=command (can be specified multiple times)")
+	flags.StringSliceVar(&o.Essential, "essential", nil, "Process that stops the rest when it exits (can be specified multiple times, defaults to all)")
+	flags.StringVar(&o.Color, "color", "auto", "Colorize output prefixes: auto, always or never")
+	flags.StringVar(&o.StopSignal, "stop-signal", "TERM", "Signal sent to processes to stop them")
+	flags.DurationVar(&o.KillAfter, "kill-after", defaultKillAfter, "Kill processes still running this long after they were stopped, 0 to disable, defaults to ECS_CONTAINER_STOP_TIMEOUT minus 1s if set")
+}
+
+// Parse returns Procfile processes followed by --process ones, with
+// resolved executables.
+func (o *runOptions) Parse(lookPath func(file string) (string, error)) ([]supervisor.Process, error) {
+	var definitions []procfile.Process
+
+	if o.Procfile != "" {
+		var err error
+
+		definitions, err = procfile.ParseFile(o.Procfile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --procfile: %w", err)
+		}
+	}
+
+	for _, s := range o.Processes {
+		definition, err := procfile.ParseProcess(s, "=")
+		if err != nil {
+			return nil, fmt.Errorf("invalid --process: %w", err)
+		}
+
+		definitions = append(definitions, definition)
+	}
+
+	if len(definitions) == 0 {
+		return nil, errors.New("no processes to run, use --procfile or --process")
+	}
+
+	processes := make([]supervisor.Process, 0, len(definitions))
+	names := make([]string, 0, len(definitions))
+
+	for _, definition := range definitions {
+		if slices.Contains(names, definition.Name) {
+			return nil, fmt.Errorf("duplicate process %s", definition.Name)
+		}
+
+		command, err := parseCommand(definition.Command, lookPath)
+		if err != nil {
+			return nil, fmt.Errorf("invalid process %s: %w", definition.Name, err)
+		}
+
+		essential := len(o.Essential) == 0 || slices.Contains(o.Essential, definition.Name)
+
+		processes = append(processes, supervisor.Process{Name: definition.Name, Command: *command, Essential: essential})
+		names = append(names, definition.Name)
+	}
+
+	for _, name := range o.Essential {
+		if !slices.Contains(names, name) {
+			return nil, fmt.Errorf("invalid --essential: unknown process %s", name)
+		}
+	}
+
+	return processes, nil
+}
+
+// Colorize returns whether output prefixes should be colorized.
+func (o *runOptions) Colorize(w io.Writer, env []string) (bool, error) {
+	switch o.Color {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto":
+		_, noColor := environ.Lookup(env, "NO_COLOR")
+		return !noColor && isTerminal(w), nil
+	default:
+		return false, errors.New("invalid --color: must be auto, always or never")
+	}
+}
+
+// prefixOutput makes processes write their output to stdout and stderr line
+// by line, prefixed with the name of the process aligned to the longest one.
+// Returns function flushing incomplete last lines.
+func prefixOutput(processes []supervisor.Process, stdout, stderr io.Writer, colorize bool) func() {
+	width := 0
+	for _, p := range processes {
+		width = max(width, len(p.Name))
+	}
+
+	var writers []*linewriter.Writer
+
+	for i := range processes {
+		prefix := fmt.Sprintf("%-*s | ", width, processes[i].Name)
+		if colorize {
+			prefix = "\x1b[" + prefixColors[i%len(prefixColors)] + "m" + prefix + "\x1b[0m"
+		}
+
+		out := linewriter.NewPrefixed(stdout, prefix)
+		errOut := linewriter.NewPrefixed(stderr, prefix)
+
+		processes[i].Stdout, processes[i].Stderr = out, errOut
+		writers = append(writers, out, errOut)
+	}
+
+	return func() {
+		for _, w := range writers {
+			w.Flush()
+		}
+	}
+}
+
+// isTerminal returns true if w is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func NewRunCommand(d *runCmdDeps) *cobra.Command {
+	if d == nil {
+		d = defaultRunCmdDeps()
+	}
+
+	envOpts := &envOptions{}
+	metadataOpts := &metadataOptions{}
+	runOpts := &runOptions{}
+
+	runE := func(cmd *cobra.Command, args []string) error {
+		if err := metadataOpts.Validate(); err != nil {
+			return err
+		}
+
+		if err := envOpts.Validate(); err != nil {
+			return err
+		}
+
+		processes, err := runOpts.Parse(d.LookPath)
+		if err != nil {
+			return err
+		}
+
+		stopSignal, err := supervisor.ParseSignal(runOpts.StopSignal)
+		if err != nil {
+			return fmt.Errorf("invalid --stop-signal: %w", err)
+		}
+
+		killAfter := runOpts.KillAfter
+		if !cmd.Flags().Changed("kill-after") {
+			killAfter = getDefaultKillAfter()
+		}
+
+		processEnv := d.Environ()
+
+		colorize, err := runOpts.Colorize(cmd.OutOrStdout(), processEnv)
+		if err != nil {
+			return err
+		}
+
+		metadata, err := metadataOpts.Fetch(cmd.Context(), &d.metadataCmdDeps)
+		if err != nil {
+			return err
+		}
+
+		if metadata == nil {
+			metadata = &container_metadata.Metadata{}
+		}
+
+		commandEnv, err := newCommandEnviron(envOpts, metadataOpts, cmd.Flags(), processEnv, metadata)
+		if err != nil {
+			slog.Error("Can't load env file", "error", err)
+			return err
+		}
+
+		env := envOpts.Sanitize(commandEnv.Merge(metadata))
+
+		flush := prefixOutput(processes, cmd.OutOrStdout(), cmd.ErrOrStderr(), colorize)
+		defer flush()
+
+		g := supervisor.NewGroup(supervisor.GroupConfig{
+			Processes:  processes,
+			Env:        env,
+			StopSignal: stopSignal,
+			KillAfter:  killAfter,
+		})
+
+		result, err := d.Run(cmd.Context(), g)
+		if err != nil {
+			slog.Error("Can't run processes", "error", err)
+			return err
+		}
+
+		if result.ExitCode != 0 {
+			// Exit status is propagated as is, there's no error to report.
+			cmd.SilenceErrors = true
+			return &exitCodeError{code: result.ExitCode}
+		}
+
+		return nil
+	}
+
+	cmd := &cobra.Command{
+		Use:          "run",
+		Short:        "Run several processes with ECS metadata environment variables",
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE:         runE,
+	}
+
+	metadataOpts.AddFlags(cmd.Flags())
+	envOpts.AddFlags(cmd.Flags())
+	runOpts.AddFlags(cmd.Flags())
+
+	return cmd
+}
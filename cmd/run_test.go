@@ -0,0 +1,223 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ixti/ecs-task-helper/pkg/container_metadata"
+	"github.com/ixti/ecs-task-helper/pkg/supervisor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func testRunDeps(captured *supervisor.GroupConfig, result *supervisor.GroupResult) *runCmdDeps {
+	return &runCmdDeps{
+		metadataCmdDeps: metadataCmdDeps{
+			FetchMetadata: func(ctx context.Context, timeout time.Duration) (*container_metadata.Metadata, error) {
+				return testMetadata(), nil
+			},
+			Timeout: 5 * time.Second,
+		},
+		Environ:  func() []string { return []string{"PATH=/usr/bin"} },
+		LookPath: func(file string) (string, error) { return "/bin/" + file, nil },
+		Run: func(ctx context.Context, g *supervisor.Group) (*supervisor.GroupResult, error) {
+			*captured = g.Config()
+			return result, nil
+		},
+	}
+}
+
+func TestNewRunCommand(t *testing.T) {
+	t.Run("with procfile and processes runs all of them", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		path := filepath.Join(t.TempDir(), "Procfile")
+		require.NoError(os.WriteFile(path, []byte("web: server --port 8080\n"), 0o644))
+
+		var config supervisor.GroupConfig
+
+		cmd := NewRunCommand(testRunDeps(&config, &supervisor.GroupResult{}))
+		cmd.SetArgs([]string{"--procfile", path, "--process", "shipper=fluent-bit -c 'a b'", "--essential", "web"})
+
+		err := cmd.Execute()
+
+		require.NoError(err)
+		require.Len(config.Processes, 2)
+
+		assert.Equal("web", config.Processes[0].Name)
+		assert.Equal(supervisor.Command{Path: "/bin/server", Args: []string{"server", "--port", "8080"}}, config.Processes[0].Command)
+		assert.True(config.Processes[0].Essential)
+
+		assert.Equal("shipper", config.Processes[1].Name)
+		assert.Equal(supervisor.Command{Path: "/bin/fluent-bit", Args: []string{"fluent-bit", "-c", "a b"}}, config.Processes[1].Command)
+		assert.False(config.Processes[1].Essential)
+
+		assert.Contains(config.Env, "PATH=/usr/bin")
+		assert.Contains(config.Env, "ECS_CONTAINER_NAME=curl")
+		assert.Equal(unix.SIGTERM, config.StopSignal)
+	})
+
+	t.Run("all processes are essential by default", func(t *testing.T) {
+		var config supervisor.GroupConfig
+
+		cmd := NewRunCommand(testRunDeps(&config, &supervisor.GroupResult{}))
+		cmd.SetArgs([]string{"--process", "web=server", "--process", "worker=worker"})
+
+		require.NoError(t, cmd.Execute())
+
+		for _, p := range config.Processes {
+			assert.True(t, p.Essential, p.Name)
+		}
+	})
+
+	t.Run("with stop flags configures stop", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var config supervisor.GroupConfig
+
+		cmd := NewRunCommand(testRunDeps(&config, &supervisor.GroupResult{}))
+		cmd.SetArgs([]string{"--process", "web=nginx", "--stop-signal", "QUIT", "--kill-after", "10s"})
+
+		require.NoError(cmd.Execute())
+		assert.Equal(unix.SIGQUIT, config.StopSignal)
+		assert.Equal(10*time.Second, config.KillAfter)
+	})
+
+	t.Run("prefixes output with aligned process names", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var config supervisor.GroupConfig
+
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+
+		cmd := NewRunCommand(testRunDeps(&config, &supervisor.GroupResult{}))
+		cmd.SetArgs([]string{"--process", "web=server", "--process", "worker=worker"})
+		cmd.SetOut(stdout)
+		cmd.SetErr(stderr)
+
+		require.NoError(cmd.Execute())
+
+		config.Processes[0].Stdout.Write([]byte("listening\n"))
+		config.Processes[1].Stderr.Write([]byte("oops\n"))
+
+		assert.Equal("web    | listening\n", stdout.String())
+		assert.Equal("worker | oops\n", stderr.String())
+	})
+
+	t.Run("with --color=always colorizes prefixes", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var config supervisor.GroupConfig
+
+		stdout := &bytes.Buffer{}
+
+		cmd := NewRunCommand(testRunDeps(&config, &supervisor.GroupResult{}))
+		cmd.SetArgs([]string{"--process", "web=server", "--process", "worker=worker", "--color=always"})
+		cmd.SetOut(stdout)
+
+		require.NoError(cmd.Execute())
+
+		config.Processes[0].Stdout.Write([]byte("a\n"))
+		config.Processes[1].Stdout.Write([]byte("b\n"))
+
+		assert.Equal("\x1b[36mweb    | \x1b[0ma\n\x1b[33mworker | \x1b[0mb\n", stdout.String())
+	})
+
+	t.Run("with failed process returns its exit code", func(t *testing.T) {
+		var config supervisor.GroupConfig
+
+		cmd := NewRunCommand(testRunDeps(&config, &supervisor.GroupResult{Result: supervisor.Result{ExitCode: 3}, Name: "web"}))
+		cmd.SetArgs([]string{"--process", "web=server"})
+
+		err := cmd.Execute()
+
+		var exitErr *exitCodeError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 3, exitErr.code)
+	})
+
+	t.Run("with run error returns error", func(t *testing.T) {
+		d := testRunDeps(&supervisor.GroupConfig{}, nil)
+		d.Run = func(ctx context.Context, g *supervisor.Group) (*supervisor.GroupResult, error) {
+			return nil, errors.New("failed to start web")
+		}
+
+		cmd := NewRunCommand(d)
+		cmd.SetArgs([]string{"--process", "web=server"})
+
+		assert.EqualError(t, cmd.Execute(), "failed to start web")
+	})
+
+	invalid := map[string][]string{
+		"no processes to run, use --procfile or --process":       {},
+		`invalid --process: missing = in "server"`:               {"--process", "server"},
+		"duplicate process web":                                  {"--process", "web=a", "--process", "web=b"},
+		"invalid process web: unterminated quote":                {"--process", "web=server 'a"},
+		"invalid --essential: unknown process worker":            {"--process", "web=server", "--essential", "worker"},
+		"invalid --color: must be auto, always or never":         {"--process", "web=server", "--color", "rainbow"},
+		"invalid --stop-signal: unknown signal: NOPE":            {"--process", "web=server", "--stop-signal", "NOPE"},
+		"invalid --metadata: must be required, optional or skip": {"--process", "web=server", "--metadata", "maybe"},
+	}
+
+	for expected, args := range invalid {
+		t.Run("returns error: "+expected, func(t *testing.T) {
+			var config supervisor.GroupConfig
+
+			cmd := NewRunCommand(testRunDeps(&config, &supervisor.GroupResult{}))
+			cmd.SetArgs(args)
+
+			assert.EqualError(t, cmd.Execute(), expected)
+		})
+	}
+}
+
+func TestRunOptions_Parse(t *testing.T) {
+	lookPath := func(file string) (string, error) { return "/bin/" + file, nil }
+
+	t.Run("with invalid procfile", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "Procfile")
+		require.NoError(t, os.WriteFile(path, []byte("web\n"), 0o644))
+
+		_, err := (&runOptions{Procfile: path}).Parse(lookPath)
+
+		assert.EqualError(t, err, "invalid --procfile: "+path+`:1: missing : in "web"`)
+	})
+
+	t.Run("with duplicate process in procfile and flags", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "Procfile")
+		require.NoError(t, os.WriteFile(path, []byte("web: server\n"), 0o644))
+
+		_, err := (&runOptions{Procfile: path, Processes: []string{"web=other"}}).Parse(lookPath)
+
+		assert.EqualError(t, err, "duplicate process web")
+	})
+}
+
+func TestRunOptions_Colorize(t *testing.T) {
+	t.Run("with auto and non-terminal output", func(t *testing.T) {
+		colorize, err := (&runOptions{Color: "auto"}).Colorize(&bytes.Buffer{}, nil)
+
+		require.NoError(t, err)
+		assert.False(t, colorize)
+	})
+
+	t.Run("with auto and NO_COLOR", func(t *testing.T) {
+		colorize, err := (&runOptions{Color: "auto"}).Colorize(os.Stdout, []string{"NO_COLOR=1"})
+
+		require.NoError(t, err)
+		assert.False(t, colorize)
+	})
+}
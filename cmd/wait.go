@@ -0,0 +1,126 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/ixti/ecs-task-helper/pkg/probe"
+	"github.com/spf13/cobra"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	defaultWaitTimeout  = 1 * time.Minute
+	defaultWaitInterval = 1 * time.Second
+)
+
+type waitCmdDeps struct {
+	Environ  func() []string
+	LookPath func(file string) (string, error)
+	Exec     func(argv0 string, argv []string, envv []string) error
+}
+
+func defaultWaitCmdDeps() *waitCmdDeps {
+	return &waitCmdDeps{
+		Environ:  os.Environ,
+		LookPath: exec.LookPath,
+		Exec:     unix.Exec,
+	}
+}
+
+func NewWaitCommand(d *waitCmdDeps) *cobra.Command {
+	if d == nil {
+		d = defaultWaitCmdDeps()
+	}
+
+	var (
+		timeout time.Duration
+		opts    probe.Options
+	)
+
+	runE := func(cmd *cobra.Command, args []string) error {
+		targetArgs, command := args, []string(nil)
+		if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+			targetArgs, command = args[:dash], args[dash:]
+		}
+
+		if len(targetArgs) == 0 {
+			return errors.New("at least one target is required")
+		}
+
+		if opts.Interval <= 0 {
+			return errors.New("invalid --interval: must be positive")
+		}
+
+		targets := make([]*probe.Target, 0, len(targetArgs))
+		for _, arg := range targetArgs {
+			target, err := probe.Parse(arg)
+			if err != nil {
+				return err
+			}
+
+			targets = append(targets, target)
+		}
+
+		ctx := cmd.Context()
+
+		if timeout > 0 {
+			var cancel context.CancelFunc
+
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		if err := probe.WaitAll(ctx, targets, opts); err != nil {
+			slog.Error("Targets are not ready", "error", err)
+			return err
+		}
+
+		if len(command) == 0 {
+			return nil
+		}
+
+		argv0, err := d.LookPath(command[0])
+		if err != nil {
+			slog.Error("Can't find command", "command", command[0], "error", err)
+			return err
+		}
+
+		if err := d.Exec(argv0, append([]string{argv0}, command[1:]...), d.Environ()); err != nil {
+			slog.Error("Command execution failed", "command", command[0], "error", err)
+			return err
+		}
+
+		// This is effectively unreachable in real world, as Exec replaces the process.
+		return nil
+	}
+
+	cmd := &cobra.Command{
+		Use:   "wait [flags] target... [-- command [args...]]",
+		Short: "Wait for TCP, HTTP, DNS or file targets to become ready",
+		Long: `Wait for targets to become ready, and optionally execute a command.
+
+Targets:
+  tcp://host:port        port accepts connections
+  http(s)://host/path    responds with 2xx status
+  dns://name             name resolves
+  file:///path           file exists`,
+		SilenceUsage: true,
+		Args:         cobra.MinimumNArgs(1),
+		RunE:         runE,
+	}
+
+	cmd.Flags().DurationVar(&timeout, "timeout", defaultWaitTimeout, "Timeout of waiting for all targets, 0 to disable")
+	cmd.Flags().DurationVar(&opts.TargetTimeout, "target-timeout", 0, "Timeout of waiting for each target, 0 to disable")
+	cmd.Flags().DurationVar(&opts.Interval, "interval", defaultWaitInterval, "Interval between probes of each target")
+	cmd.Flags().BoolVar(&opts.Any, "any", false, "Wait for any of the targets instead of all of them")
+
+	return cmd
+}
@@ -0,0 +1,129 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type capturedExec struct {
+	argv0 string
+	argv  []string
+	env   []string
+}
+
+func testWaitDeps(captured *capturedExec) *waitCmdDeps {
+	return &waitCmdDeps{
+		Environ:  func() []string { return []string{"PATH=/usr/bin"} },
+		LookPath: func(file string) (string, error) { return "/bin/" + file, nil },
+		Exec: func(argv0 string, argv []string, envv []string) error {
+			*captured = capturedExec{argv0, argv, envv}
+			return nil
+		},
+	}
+}
+
+func TestNewWaitCommand(t *testing.T) {
+	t.Run("with ready targets executes command", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(err)
+		defer l.Close()
+
+		path := filepath.Join(t.TempDir(), "ready")
+		require.NoError(os.WriteFile(path, nil, 0o644))
+
+		var captured capturedExec
+
+		cmd := NewWaitCommand(testWaitDeps(&captured))
+		cmd.SetArgs([]string{"tcp://" + l.Addr().String(), "file://" + path, "--", "sh", "-c", "echo ready"})
+
+		err = cmd.Execute()
+
+		require.NoError(err)
+		assert.Equal(capturedExec{"/bin/sh", []string{"/bin/sh", "-c", "echo ready"}, []string{"PATH=/usr/bin"}}, captured)
+	})
+
+	t.Run("without command returns once ready", func(t *testing.T) {
+		var captured capturedExec
+
+		cmd := NewWaitCommand(testWaitDeps(&captured))
+		cmd.SetArgs([]string{"dns://localhost"})
+
+		err := cmd.Execute()
+
+		require.NoError(t, err)
+		assert.Empty(t, captured.argv0)
+	})
+
+	t.Run("with timeout does not execute command", func(t *testing.T) {
+		assert := assert.New(t)
+
+		var captured capturedExec
+
+		cmd := NewWaitCommand(testWaitDeps(&captured))
+		cmd.SetArgs([]string{"--timeout", "50ms", "--interval", "10ms", "file:///nonexistent", "--", "sh"})
+
+		err := cmd.Execute()
+
+		assert.ErrorContains(err, "file:///nonexistent is not ready")
+		assert.Empty(captured.argv0)
+	})
+
+	t.Run("with --any", func(t *testing.T) {
+		var captured capturedExec
+
+		cmd := NewWaitCommand(testWaitDeps(&captured))
+		cmd.SetArgs([]string{"--any", "--timeout", "1s", "file:///nonexistent", "dns://localhost"})
+
+		err := cmd.Execute()
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("with invalid target", func(t *testing.T) {
+		var captured capturedExec
+
+		cmd := NewWaitCommand(testWaitDeps(&captured))
+		cmd.SetArgs([]string{"udp://localhost:53"})
+
+		err := cmd.Execute()
+
+		assert.ErrorContains(t, err, "unsupported scheme")
+	})
+
+	t.Run("without targets", func(t *testing.T) {
+		var captured capturedExec
+
+		cmd := NewWaitCommand(testWaitDeps(&captured))
+		cmd.SetArgs([]string{"--", "sh"})
+
+		err := cmd.Execute()
+
+		assert.EqualError(t, err, "at least one target is required")
+	})
+
+	t.Run("with non-positive interval", func(t *testing.T) {
+		var captured capturedExec
+
+		cmd := NewWaitCommand(testWaitDeps(&captured))
+		cmd.SetArgs([]string{"--interval", "0s", "dns://localhost"})
+
+		err := cmd.Execute()
+
+		assert.EqualError(t, err, "invalid --interval: must be positive")
+	})
+
+	t.Run("with nil deps uses defaults", func(t *testing.T) {
+		assert.NotNil(t, NewWaitCommand(nil))
+	})
+}
@@ -0,0 +1,306 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+// Package cgroup reads resource limits, usage and events of the cgroup of the
+// current process, supporting both the unified (v2) and legacy (v1)
+// hierarchies.
+package cgroup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultRoot is where cgroup hierarchy of the container is mounted.
+const DefaultRoot = "/sys/fs/cgroup"
+
+// unlimitedV1 is the lowest value v1 reports for unlimited memory. The exact
+// value depends on the page size.
+const unlimitedV1 = 1 << 62
+
+// Group is the cgroup mounted at a root.
+type Group struct {
+	root string
+	v2   bool
+}
+
+// Open returns cgroup mounted at root. The hierarchy version is detected by
+// presence of cgroup.controllers, which only exists in v2.
+func Open(root string) *Group {
+	_, err := os.Stat(filepath.Join(root, "cgroup.controllers"))
+
+	return &Group{root: root, v2: err == nil}
+}
+
+// IsV2 returns true if the cgroup uses the unified hierarchy.
+func (g *Group) IsV2() bool {
+	return g.v2
+}
+
+// CPULimit returns CPU quota as a number of CPUs, zero if not limited.
+func (g *Group) CPULimit() (float64, error) {
+	var quota, period string
+
+	if g.v2 {
+		value, err := g.read("cpu.max")
+		if err != nil {
+			return 0, err
+		}
+
+		quota, period, _ = strings.Cut(value, " ")
+	} else {
+		var err error
+
+		if quota, err = g.read("cpu/cpu.cfs_quota_us"); err != nil {
+			return 0, err
+		}
+
+		if period, err = g.read("cpu/cpu.cfs_period_us"); err != nil {
+			return 0, err
+		}
+	}
+
+	if quota == "max" || quota == "-1" {
+		return 0, nil
+	}
+
+	q, err := strconv.ParseFloat(quota, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid CPU quota: %s", quota)
+	}
+
+	p, err := strconv.ParseFloat(period, 64)
+	if err != nil || p <= 0 {
+		return 0, fmt.Errorf("invalid CPU period: %s", period)
+	}
+
+	return q / p, nil
+}
+
+// MemoryLimit returns memory limit in bytes, zero if not limited.
+func (g *Group) MemoryLimit() (int64, error) {
+	name := "memory.max"
+	if !g.v2 {
+		name = "memory/memory.limit_in_bytes"
+	}
+
+	value, err := g.read(name)
+	if err != nil {
+		return 0, err
+	}
+
+	if value == "max" {
+		return 0, nil
+	}
+
+	limit, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory limit: %s", value)
+	}
+
+	if limit >= unlimitedV1 {
+		return 0, nil
+	}
+
+	return limit, nil
+}
+
+// MemoryUsage returns current memory usage in bytes, including page cache.
+func (g *Group) MemoryUsage() (int64, error) {
+	name := "memory.current"
+	if !g.v2 {
+		name = "memory/memory.usage_in_bytes"
+	}
+
+	value, err := g.read(name)
+	if err != nil {
+		return 0, err
+	}
+
+	usage, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory usage: %s", value)
+	}
+
+	return usage, nil
+}
+
+// MemoryEvents are counters of memory events of the cgroup.
+type MemoryEvents struct {
+	// High is the number of times usage exceeded the throttling threshold.
+	// Always zero in v1.
+	High int64
+	// Max is the number of times usage was about to exceed the limit.
+	Max int64
+	// OOM is the number of times the limit was reached and reclaim failed.
+	// Always zero in v1.
+	OOM int64
+	// OOMKill is the number of processes killed by the OOM killer.
+	OOMKill int64
+}
+
+// MemoryEvents returns memory event counters. In v1 they're read from
+// memory.failcnt and memory.oom_control.
+func (g *Group) MemoryEvents() (*MemoryEvents, error) {
+	if g.v2 {
+		counters, err := g.readCounters("memory.events")
+		if err != nil {
+			return nil, err
+		}
+
+		return &MemoryEvents{
+			High:    counters["high"],
+			Max:     counters["max"],
+			OOM:     counters["oom"],
+			OOMKill: counters["oom_kill"],
+		}, nil
+	}
+
+	counters, err := g.readCounters("memory/memory.oom_control")
+	if err != nil {
+		return nil, err
+	}
+
+	// Legacy hierarchy has the counter since Linux 4.13.
+	kills, ok := counters["oom_kill"]
+	if !ok {
+		return nil, errors.New("cgroup file memory/memory.oom_control has no oom_kill counter")
+	}
+
+	value, err := g.read("memory/memory.failcnt")
+	if err != nil {
+		return nil, err
+	}
+
+	failures, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid memory failure counter: %s", value)
+	}
+
+	return &MemoryEvents{Max: failures, OOMKill: kills}, nil
+}
+
+// OOMKills returns the number of processes of the cgroup killed by the OOM
+// killer.
+func (g *Group) OOMKills() (int64, error) {
+	events, err := g.MemoryEvents()
+	if err != nil {
+		return 0, err
+	}
+
+	return events.OOMKill, nil
+}
+
+// Pressure is pressure stall information of a resource.
+type Pressure struct {
+	// Some is the share of time at least some tasks were stalled.
+	Some PressureStats
+	// Full is the share of time all non-idle tasks were stalled at once.
+	Full PressureStats
+}
+
+// PressureStats are percentages of time tasks were stalled on average
+// within the last 10, 60 and 300 seconds, and the total stall time.
+type PressureStats struct {
+	Avg10, Avg60, Avg300 float64
+	Total                time.Duration
+}
+
+// MemoryPressure returns memory pressure stall information. Only v2 reports
+// pressure, there is no fallback for v1.
+func (g *Group) MemoryPressure() (*Pressure, error) {
+	if !g.v2 {
+		return nil, fmt.Errorf("memory pressure requires cgroup v2: %w", errors.ErrUnsupported)
+	}
+
+	content, err := g.read("memory.pressure")
+	if err != nil {
+		return nil, err
+	}
+
+	pressure := &Pressure{}
+
+	for line := range strings.Lines(content) {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		var stats *PressureStats
+
+		switch fields[0] {
+		case "some":
+			stats = &pressure.Some
+		case "full":
+			stats = &pressure.Full
+		default:
+			continue
+		}
+
+		for _, field := range fields[1:] {
+			key, value, _ := strings.Cut(field, "=")
+
+			var err error
+
+			switch key {
+			case "avg10":
+				stats.Avg10, err = strconv.ParseFloat(value, 64)
+			case "avg60":
+				stats.Avg60, err = strconv.ParseFloat(value, 64)
+			case "avg300":
+				stats.Avg300, err = strconv.ParseFloat(value, 64)
+			case "total":
+				var total int64
+				total, err = strconv.ParseInt(value, 10, 64)
+				stats.Total = time.Duration(total) * time.Microsecond
+			}
+
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %s in cgroup file memory.pressure: %s", fields[0], key, value)
+			}
+		}
+	}
+
+	return pressure, nil
+}
+
+// readCounters reads file of "key value" lines, e.g. memory.events.
+func (g *Group) readCounters(name string) (map[string]int64, error) {
+	content, err := g.read(name)
+	if err != nil {
+		return nil, err
+	}
+
+	counters := map[string]int64{}
+
+	for line := range strings.Lines(content) {
+		key, value, _ := strings.Cut(strings.TrimSpace(line), " ")
+
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s counter in cgroup file %s: %s", key, name, value)
+		}
+
+		counters[key] = n
+	}
+
+	return counters, nil
+}
+
+func (g *Group) read(name string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(g.root, name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("cgroup file %s not found: %w", name, err)
+		}
+
+		return "", fmt.Errorf("failed to read cgroup file %s: %w", name, err)
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
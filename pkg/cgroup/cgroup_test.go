@@ -0,0 +1,220 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package cgroup
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCgroup creates cgroup tree with the given files.
+func fakeCgroup(t *testing.T, files map[string]string) string {
+	root := t.TempDir()
+
+	for name, content := range files {
+		path := filepath.Join(root, name)
+
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	return root
+}
+
+func TestGroup_CPULimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected float64
+	}{
+		{"with v2 quota", map[string]string{"cgroup.controllers": "cpu memory\n", "cpu.max": "150000 100000\n"}, 1.5},
+		{"with v2 unlimited", map[string]string{"cgroup.controllers": "cpu memory\n", "cpu.max": "max 100000\n"}, 0},
+		{"with v1 quota", map[string]string{"cpu/cpu.cfs_quota_us": "50000\n", "cpu/cpu.cfs_period_us": "100000\n"}, 0.5},
+		{"with v1 unlimited", map[string]string{"cpu/cpu.cfs_quota_us": "-1\n", "cpu/cpu.cfs_period_us": "100000\n"}, 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			limit, err := Open(fakeCgroup(t, tc.files)).CPULimit()
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, limit)
+		})
+	}
+
+	t.Run("with invalid quota", func(t *testing.T) {
+		root := fakeCgroup(t, map[string]string{"cgroup.controllers": "", "cpu.max": "invalid 100000"})
+
+		_, err := Open(root).CPULimit()
+
+		assert.EqualError(t, err, "invalid CPU quota: invalid")
+	})
+
+	t.Run("without cpu controller", func(t *testing.T) {
+		_, err := Open(fakeCgroup(t, map[string]string{"cgroup.controllers": ""})).CPULimit()
+
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestGroup_MemoryLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected int64
+	}{
+		{"with v2 limit", map[string]string{"cgroup.controllers": "", "memory.max": "536870912\n"}, 536870912},
+		{"with v2 unlimited", map[string]string{"cgroup.controllers": "", "memory.max": "max\n"}, 0},
+		{"with v1 limit", map[string]string{"memory/memory.limit_in_bytes": "268435456\n"}, 268435456},
+		{"with v1 unlimited", map[string]string{"memory/memory.limit_in_bytes": "9223372036854771712\n"}, 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			limit, err := Open(fakeCgroup(t, tc.files)).MemoryLimit()
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, limit)
+		})
+	}
+
+	t.Run("without memory controller", func(t *testing.T) {
+		_, err := Open(t.TempDir()).MemoryLimit()
+
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestGroup_MemoryUsage(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected int64
+	}{
+		{"with v2", map[string]string{"cgroup.controllers": "", "memory.current": "104857600\n"}, 104857600},
+		{"with v1", map[string]string{"memory/memory.usage_in_bytes": "52428800\n"}, 52428800},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			usage, err := Open(fakeCgroup(t, tc.files)).MemoryUsage()
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, usage)
+		})
+	}
+
+	t.Run("with invalid usage", func(t *testing.T) {
+		_, err := Open(fakeCgroup(t, map[string]string{"cgroup.controllers": "", "memory.current": "lots\n"})).MemoryUsage()
+
+		assert.EqualError(t, err, "invalid memory usage: lots")
+	})
+}
+
+func TestGroup_MemoryEvents(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected *MemoryEvents
+	}{
+		{
+			"with v2",
+			map[string]string{"cgroup.controllers": "", "memory.events": "low 0\nhigh 5\nmax 12\noom 2\noom_kill 1\noom_group_kill 0\n"},
+			&MemoryEvents{High: 5, Max: 12, OOM: 2, OOMKill: 1},
+		},
+		{
+			"with v1",
+			map[string]string{"memory/memory.oom_control": "oom_kill_disable 0\nunder_oom 0\noom_kill 3\n", "memory/memory.failcnt": "7\n"},
+			&MemoryEvents{Max: 7, OOMKill: 3},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			events, err := Open(fakeCgroup(t, tc.files)).MemoryEvents()
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, events)
+		})
+	}
+
+	t.Run("with v1 before Linux 4.13", func(t *testing.T) {
+		root := fakeCgroup(t, map[string]string{"memory/memory.oom_control": "oom_kill_disable 0\nunder_oom 0\n", "memory/memory.failcnt": "0\n"})
+
+		_, err := Open(root).MemoryEvents()
+
+		assert.EqualError(t, err, "cgroup file memory/memory.oom_control has no oom_kill counter")
+	})
+
+	t.Run("with invalid counter", func(t *testing.T) {
+		root := fakeCgroup(t, map[string]string{"cgroup.controllers": "", "memory.events": "oom_kill many\n"})
+
+		_, err := Open(root).MemoryEvents()
+
+		assert.EqualError(t, err, "invalid oom_kill counter in cgroup file memory.events: many")
+	})
+
+	t.Run("without memory controller", func(t *testing.T) {
+		_, err := Open(fakeCgroup(t, map[string]string{"cgroup.controllers": ""})).MemoryEvents()
+
+		assert.ErrorContains(t, err, "cgroup file memory.events not found")
+	})
+}
+
+func TestGroup_OOMKills(t *testing.T) {
+	t.Run("with v2", func(t *testing.T) {
+		root := fakeCgroup(t, map[string]string{"cgroup.controllers": "", "memory.events": "max 12\noom 2\noom_kill 1\n"})
+
+		kills, err := Open(root).OOMKills()
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), kills)
+	})
+
+	t.Run("without memory controller", func(t *testing.T) {
+		_, err := Open(t.TempDir()).OOMKills()
+
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestGroup_MemoryPressure(t *testing.T) {
+	t.Run("with v2", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		root := fakeCgroup(t, map[string]string{
+			"cgroup.controllers": "",
+			"memory.pressure":    "some avg10=12.50 avg60=3.00 avg300=0.75 total=2500000\nfull avg10=1.25 avg60=0.00 avg300=0.00 total=120\n",
+		})
+
+		pressure, err := Open(root).MemoryPressure()
+
+		require.NoError(err)
+		assert.Equal(&Pressure{
+			Some: PressureStats{Avg10: 12.5, Avg60: 3, Avg300: 0.75, Total: 2500 * time.Millisecond},
+			Full: PressureStats{Avg10: 1.25, Total: 120 * time.Microsecond},
+		}, pressure)
+	})
+
+	t.Run("with invalid value", func(t *testing.T) {
+		root := fakeCgroup(t, map[string]string{"cgroup.controllers": "", "memory.pressure": "some avg10=high\n"})
+
+		_, err := Open(root).MemoryPressure()
+
+		assert.EqualError(t, err, "invalid some avg10 in cgroup file memory.pressure: high")
+	})
+
+	t.Run("with v1", func(t *testing.T) {
+		_, err := Open(t.TempDir()).MemoryPressure()
+
+		assert.ErrorIs(t, err, errors.ErrUnsupported)
+	})
+}
@@ -0,0 +1,53 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package container_metadata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseARN(t *testing.T) {
+	t.Run("with valid ARN", func(t *testing.T) {
+		assert := assert.New(t)
+
+		arn, ok := ParseARN("arn:aws:ecs:us-west-2:111122223333:task/default/8f03e41243824aea923aca126495f665")
+
+		assert.True(ok)
+		assert.Equal(ARN{
+			Partition: "aws",
+			Service:   "ecs",
+			Region:    "us-west-2",
+			AccountID: "111122223333",
+			Resource:  "task/default/8f03e41243824aea923aca126495f665",
+		}, arn)
+	})
+
+	t.Run("with colons in resource", func(t *testing.T) {
+		assert := assert.New(t)
+
+		arn, ok := ParseARN("arn:aws:logs:us-west-2:111122223333:log-group:/ecs/app:*")
+
+		assert.True(ok)
+		assert.Equal("log-group:/ecs/app:*", arn.Resource)
+	})
+
+	t.Run("with invalid ARN", func(t *testing.T) {
+		assert := assert.New(t)
+
+		_, ok := ParseARN("default")
+		assert.False(ok)
+
+		_, ok = ParseARN("urn:aws:ecs:us-west-2:111122223333:task/default")
+		assert.False(ok)
+	})
+}
+
+func TestARN_String(t *testing.T) {
+	s := "arn:aws-cn:ecs:cn-north-1:111122223333:cluster/default"
+	arn, _ := ParseARN(s)
+
+	assert.Equal(t, s, arn.String())
+}
@@ -0,0 +1,111 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package container_metadata
+
+import (
+	"net/url"
+	"strings"
+)
+
+// ConsoleLinks holds AWS console links for the running task.
+type ConsoleLinks struct {
+	Task           string `json:"task,omitempty"`
+	TaskDefinition string `json:"taskDefinition,omitempty"`
+	Cluster        string `json:"cluster,omitempty"`
+	Service        string `json:"service,omitempty"`
+	Logs           string `json:"logs,omitempty"`
+}
+
+// Environ returns links as environment variables.
+// Logs link is omitted as it's always exported as ECS_LOG_URL.
+func (l ConsoleLinks) Environ() []string {
+	return []string{
+		"ECS_TASK_URL=" + l.Task,
+		"ECS_TASK_DEFINITION_URL=" + l.TaskDefinition,
+		"ECS_CLUSTER_URL=" + l.Cluster,
+		"ECS_SERVICE_URL=" + l.Service,
+	}
+}
+
+// ConsoleLinks returns AWS console links for the task, its definition revision,
+// cluster, service and log stream. Links that can't be built from the metadata
+// are left empty.
+func (m *Metadata) ConsoleLinks() ConsoleLinks {
+	return ConsoleLinks{
+		Task:           m.TaskURL(),
+		TaskDefinition: m.TaskDefinitionURL(),
+		Cluster:        m.ClusterURL(),
+		Service:        m.ServiceURL(),
+		Logs:           m.LogURL(),
+	}
+}
+
+// TaskURL returns ECS console link to the task.
+func (m *Metadata) TaskURL() string {
+	cluster, taskID := m.clusterShortName(), m.TaskID()
+	if cluster == "" || taskID == "" {
+		return ""
+	}
+
+	return m.ecsConsoleURL("clusters/" + url.PathEscape(cluster) + "/tasks/" + url.PathEscape(taskID) + "/configuration")
+}
+
+// TaskDefinitionURL returns ECS console link to the task definition revision.
+func (m *Metadata) TaskDefinitionURL() string {
+	if m.TaskDefinitionFamily == "" || m.TaskDefinitionVersion == "" {
+		return ""
+	}
+
+	return m.ecsConsoleURL("task-definitions/" + url.PathEscape(m.TaskDefinitionFamily) + "/" + url.PathEscape(m.TaskDefinitionVersion) + "/containers")
+}
+
+// ClusterURL returns ECS console link to the cluster.
+func (m *Metadata) ClusterURL() string {
+	cluster := m.clusterShortName()
+	if cluster == "" {
+		return ""
+	}
+
+	return m.ecsConsoleURL("clusters/" + url.PathEscape(cluster) + "/services")
+}
+
+// ServiceURL returns ECS console link to the service that started the task.
+func (m *Metadata) ServiceURL() string {
+	cluster := m.clusterShortName()
+	if cluster == "" || m.ServiceName == "" {
+		return ""
+	}
+
+	return m.ecsConsoleURL("clusters/" + url.PathEscape(cluster) + "/services/" + url.PathEscape(m.ServiceName) + "/health")
+}
+
+func (m *Metadata) ecsConsoleURL(path string) string {
+	arn, ok := ParseARN(m.TaskARN)
+	if !ok || arn.Region == "" {
+		return ""
+	}
+
+	return consoleBaseURL(arn.Partition, arn.Region) + "/ecs/v2/" + path + "?region=" + arn.Region
+}
+
+// clusterShortName returns ClusterName, stripping the ARN part if any.
+func (m *Metadata) clusterShortName() string {
+	if arn, ok := ParseARN(m.ClusterName); ok {
+		return strings.TrimPrefix(arn.Resource, "cluster/")
+	}
+
+	return m.ClusterName
+}
+
+// consoleBaseURL returns AWS console URL for the partition and region.
+func consoleBaseURL(partition string, region string) string {
+	switch partition {
+	case "aws-us-gov":
+		return "https://console.amazonaws-us-gov.com"
+	case "aws-cn":
+		return "https://console.amazonaws.cn"
+	default:
+		return "https://" + region + ".console.aws.amazon.com"
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package container_metadata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadata_ConsoleLinks(t *testing.T) {
+	t.Run("with commercial partition", func(t *testing.T) {
+		metadata := testMetadata()
+		metadata.ServiceName = "curltest-service"
+
+		assert.Equal(t, ConsoleLinks{
+			Task:           "https://us-west-2.console.aws.amazon.com/ecs/v2/clusters/default/tasks/8f03e41243824aea923aca126495f665/configuration?region=us-west-2",
+			TaskDefinition: "https://us-west-2.console.aws.amazon.com/ecs/v2/task-definitions/curltest/24/containers?region=us-west-2",
+			Cluster:        "https://us-west-2.console.aws.amazon.com/ecs/v2/clusters/default/services?region=us-west-2",
+			Service:        "https://us-west-2.console.aws.amazon.com/ecs/v2/clusters/default/services/curltest-service/health?region=us-west-2",
+		}, metadata.ConsoleLinks())
+	})
+
+	t.Run("with GovCloud partition", func(t *testing.T) {
+		metadata := &Metadata{
+			TaskARN:     "arn:aws-us-gov:ecs:us-gov-west-1:111122223333:task/default/8f03e41243824aea923aca126495f665",
+			ClusterName: "arn:aws-us-gov:ecs:us-gov-west-1:111122223333:cluster/default",
+		}
+
+		assert.Equal(t, "https://console.amazonaws-us-gov.com/ecs/v2/clusters/default/tasks/8f03e41243824aea923aca126495f665/configuration?region=us-gov-west-1", metadata.TaskURL())
+	})
+
+	t.Run("with China partition", func(t *testing.T) {
+		metadata := &Metadata{
+			TaskARN:     "arn:aws-cn:ecs:cn-north-1:111122223333:task/default/8f03e41243824aea923aca126495f665",
+			ClusterName: "default",
+			LogDriver:   "awslogs",
+			LogOptions:  map[string]string{"awslogs-group": "app"},
+		}
+
+		assert.Equal(t, "https://console.amazonaws.cn/ecs/v2/clusters/default/services?region=cn-north-1", metadata.ClusterURL())
+		assert.Equal(t, "https://console.amazonaws.cn/cloudwatch/home?region=cn-north-1#logsV2:log-groups/log-group/app", metadata.LogURL())
+	})
+
+	t.Run("without service name", func(t *testing.T) {
+		assert.Equal(t, "", testMetadata().ServiceURL())
+	})
+
+	t.Run("with empty metadata", func(t *testing.T) {
+		assert.Equal(t, ConsoleLinks{}, (&Metadata{}).ConsoleLinks())
+	})
+}
+
+func TestConsoleLinks_Environ(t *testing.T) {
+	links := ConsoleLinks{Task: "task", TaskDefinition: "definition", Cluster: "cluster", Logs: "logs"}
+
+	assert.Equal(t, []string{
+		"ECS_TASK_URL=task",
+		"ECS_TASK_DEFINITION_URL=definition",
+		"ECS_CLUSTER_URL=cluster",
+		"ECS_SERVICE_URL=",
+	}, links.Environ())
+}
@@ -7,21 +7,41 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"time"
 )
 
+const (
+	cpuUnitsPerVCPU = 1024
+	minCPUShares    = 2
+)
+
 type metadataPayload struct {
-	ContainerARN   string `json:"ContainerARN"`
-	ContainerName  string `json:"Name"`
-	ContainerImage string `json:"Image"`
-	Labels         struct {
-		Cluster               string `json:"com.amazonaws.ecs.cluster"`
-		TaskARN               string `json:"com.amazonaws.ecs.task-arn"`
-		TaskDefinitionFamily  string `json:"com.amazonaws.ecs.task-definition-family"`
-		TaskDefinitionVersion string `json:"com.amazonaws.ecs.task-definition-version"`
-	} `json:"Labels"`
+	ContainerARN   string            `json:"ContainerARN"`
+	ContainerID    string            `json:"DockerId"`
+	ContainerName  string            `json:"Name"`
+	ContainerImage string            `json:"Image"`
+	ImageID        string            `json:"ImageID"`
+	Labels         map[string]string `json:"Labels"`
+	LogDriver      string            `json:"LogDriver"`
+	LogOptions     map[string]string `json:"LogOptions"`
+	Limits         limitsPayload     `json:"Limits"`
+}
+
+type taskPayload struct {
+	AvailabilityZone string        `json:"AvailabilityZone"`
+	LaunchType       string        `json:"LaunchType"`
+	ServiceName      string        `json:"ServiceName"`
+	Limits           limitsPayload `json:"Limits"`
+}
+
+// limitsPayload holds CPU in CPU units for containers and in vCPUs for tasks,
+// and memory in MiB.
+type limitsPayload struct {
+	CPU    float64 `json:"CPU"`
+	Memory int64   `json:"Memory"`
 }
 
 func Fetch(ctx context.Context, timeout time.Duration) (*Metadata, error) {
@@ -35,34 +55,80 @@ func Fetch(ctx context.Context, timeout time.Duration) (*Metadata, error) {
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	metadata := &metadataPayload{}
+	if err := fetchJSON(ctx, "metadata", endpoint, metadata); err != nil {
+		return nil, err
+	}
+
+	// Task metadata only adds a few fields, so failing to fetch it should not
+	// discard metadata of the container.
+	task := &taskPayload{}
+	if err := fetchJSON(ctx, "task metadata", endpoint+"/task", task); err != nil {
+		slog.Warn("Can't fetch ECS task metadata, task fields are left empty", "error", err)
+		task = &taskPayload{}
+	}
+
+	return &Metadata{
+		ContainerARN:          metadata.ContainerARN,
+		ContainerID:           metadata.ContainerID,
+		ContainerName:         metadata.ContainerName,
+		ContainerImage:        metadata.ContainerImage,
+		ContainerImageID:      metadata.ImageID,
+		TaskARN:               metadata.Labels["com.amazonaws.ecs.task-arn"],
+		TaskDefinitionFamily:  metadata.Labels["com.amazonaws.ecs.task-definition-family"],
+		TaskDefinitionVersion: metadata.Labels["com.amazonaws.ecs.task-definition-version"],
+		ClusterName:           metadata.Labels["com.amazonaws.ecs.cluster"],
+		ServiceName:           task.ServiceName,
+		AvailabilityZone:      task.AvailabilityZone,
+		LaunchType:            task.LaunchType,
+		LogDriver:             metadata.LogDriver,
+		LogOptions:            metadata.LogOptions,
+		Labels:                metadata.Labels,
+		Limits:                effectiveLimits(metadata.Limits, task.Limits),
+	}, nil
+}
+
+// effectiveLimits returns the lowest of container and task limits, or nil if
+// neither is limited.
+func effectiveLimits(container limitsPayload, task limitsPayload) *Limits {
+	limits := &Limits{CPU: task.CPU, Memory: task.Memory}
+
+	// Containers without cpu get the minimum of 2 CPU shares, not a limit.
+	if cpu := container.CPU / cpuUnitsPerVCPU; container.CPU > minCPUShares && (limits.CPU == 0 || cpu < limits.CPU) {
+		limits.CPU = cpu
+	}
+
+	if container.Memory > 0 && (limits.Memory == 0 || container.Memory < limits.Memory) {
+		limits.Memory = container.Memory
+	}
+
+	if *limits == (Limits{}) {
+		return nil
+	}
+
+	return limits
+}
+
+func fetchJSON(ctx context.Context, name string, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to prepare metadata request: %w", err)
+		return fmt.Errorf("failed to prepare %s request: %w", name, err)
 	}
 
 	res, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute metadata request: %w", err)
+		return fmt.Errorf("failed to execute %s request: %w", name, err)
 	}
 
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("metadata request failed with status %d", res.StatusCode)
+		return fmt.Errorf("%s request failed with status %d", name, res.StatusCode)
 	}
 
-	metadata := &metadataPayload{}
-	if err := json.NewDecoder(res.Body).Decode(metadata); err != nil {
-		return nil, fmt.Errorf("failed to decode metadata response: %w", err)
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", name, err)
 	}
 
-	return &Metadata{
-		ContainerARN:          metadata.ContainerARN,
-		ContainerName:         metadata.ContainerName,
-		ContainerImage:        metadata.ContainerImage,
-		TaskARN:               metadata.Labels.TaskARN,
-		TaskDefinitionFamily:  metadata.Labels.TaskDefinitionFamily,
-		TaskDefinitionVersion: metadata.Labels.TaskDefinitionVersion,
-		ClusterName:           metadata.Labels.Cluster,
-	}, nil
+	return nil
 }
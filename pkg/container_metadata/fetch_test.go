@@ -35,16 +35,38 @@ func TestFetch(t *testing.T) {
 
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusOK)
+
+			if r.URL.Path == "/task" {
+				w.Write([]byte(`{
+					"AvailabilityZone": "us-west-2b",
+					"LaunchType": "FARGATE",
+					"ServiceName": "curltest-service",
+					"Limits": {"CPU": 0.5, "Memory": 1024}
+				}`))
+
+				return
+			}
+
 			w.Write([]byte(`{
+				"DockerId": "ea32192c8553fbff06c9340478a2ff089b2bb5646fb718b4ee206641c9086d66",
 				"ContainerARN": "arn:aws:ecs:us-west-2:111122223333:container/0206b271-b33f-47ab-86c6-a0ba208a70a9",
 				"Name": "curl",
 				"Image": "111122223333.dkr.ecr.us-west-2.amazonaws.com/curltest:latest",
+				"ImageID": "sha256:d691691e9652791a60114e67b365688d20d19940dde7c4736ea30e660d8d3553",
 				"Labels": {
 					"com.amazonaws.ecs.cluster": "default",
 					"com.amazonaws.ecs.task-arn": "arn:aws:ecs:us-west-2:111122223333:task/default/8f03e41243824aea923aca126495f665",
 					"com.amazonaws.ecs.task-definition-family": "curltest",
-					"com.amazonaws.ecs.task-definition-version": "24"
-				}
+					"com.amazonaws.ecs.task-definition-version": "24",
+					"com.example.environment": "production"
+				},
+				"LogDriver": "awslogs",
+				"LogOptions": {
+					"awslogs-group": "/ecs/metadata",
+					"awslogs-region": "us-west-2",
+					"awslogs-stream": "ecs/curl/8f03e41243824aea923aca126495f665"
+				},
+				"Limits": {"CPU": 2, "Memory": 512}
 			}`))
 		}))
 		defer server.Close()
@@ -57,12 +79,31 @@ func TestFetch(t *testing.T) {
 
 		assert.Equal(&Metadata{
 			ContainerARN:          "arn:aws:ecs:us-west-2:111122223333:container/0206b271-b33f-47ab-86c6-a0ba208a70a9",
+			ContainerID:           "ea32192c8553fbff06c9340478a2ff089b2bb5646fb718b4ee206641c9086d66",
 			ContainerName:         "curl",
 			ContainerImage:        "111122223333.dkr.ecr.us-west-2.amazonaws.com/curltest:latest",
+			ContainerImageID:      "sha256:d691691e9652791a60114e67b365688d20d19940dde7c4736ea30e660d8d3553",
 			TaskARN:               "arn:aws:ecs:us-west-2:111122223333:task/default/8f03e41243824aea923aca126495f665",
 			TaskDefinitionFamily:  "curltest",
 			TaskDefinitionVersion: "24",
 			ClusterName:           "default",
+			ServiceName:           "curltest-service",
+			AvailabilityZone:      "us-west-2b",
+			LaunchType:            "FARGATE",
+			LogDriver:             "awslogs",
+			LogOptions: map[string]string{
+				"awslogs-group":  "/ecs/metadata",
+				"awslogs-region": "us-west-2",
+				"awslogs-stream": "ecs/curl/8f03e41243824aea923aca126495f665",
+			},
+			Labels: map[string]string{
+				"com.amazonaws.ecs.cluster":                 "default",
+				"com.amazonaws.ecs.task-arn":                "arn:aws:ecs:us-west-2:111122223333:task/default/8f03e41243824aea923aca126495f665",
+				"com.amazonaws.ecs.task-definition-family":  "curltest",
+				"com.amazonaws.ecs.task-definition-version": "24",
+				"com.example.environment":                   "production",
+			},
+			Limits: &Limits{CPU: 0.5, Memory: 512},
 		}, metadata)
 	})
 
@@ -82,6 +123,55 @@ func TestFetch(t *testing.T) {
 		assert.ErrorContains(err, "metadata request failed with status 500")
 	})
 
+	t.Run("with non-OK task metadata status", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/task" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"Name": "curl", "Limits": {"CPU": 512, "Memory": 256}}`))
+		}))
+		defer server.Close()
+
+		t.Setenv("ECS_CONTAINER_METADATA_URI_V4", server.URL)
+
+		metadata, err := Fetch(context.Background(), 5*time.Second)
+
+		require.NoError(err)
+		assert.Equal(&Metadata{ContainerName: "curl", Limits: &Limits{CPU: 0.5, Memory: 256}}, metadata)
+	})
+
+	t.Run("with invalid task metadata JSON", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+
+			if r.URL.Path == "/task" {
+				w.Write([]byte(`{"ServiceName": "web", "Limits": invalid`))
+				return
+			}
+
+			w.Write([]byte(`{"Name": "curl"}`))
+		}))
+		defer server.Close()
+
+		t.Setenv("ECS_CONTAINER_METADATA_URI_V4", server.URL)
+
+		metadata, err := Fetch(context.Background(), 5*time.Second)
+
+		require.NoError(err)
+		assert.Equal(&Metadata{ContainerName: "curl"}, metadata)
+	})
+
 	t.Run("with invalid JSON", func(t *testing.T) {
 		assert := assert.New(t)
 
@@ -137,3 +227,24 @@ func TestFetch(t *testing.T) {
 		assert.ErrorContains(err, "failed to execute metadata request")
 	})
 }
+
+func TestEffectiveLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		container limitsPayload
+		task      limitsPayload
+		expected  *Limits
+	}{
+		{"without limits", limitsPayload{}, limitsPayload{}, nil},
+		{"with task limits", limitsPayload{CPU: 2}, limitsPayload{CPU: 0.25, Memory: 512}, &Limits{CPU: 0.25, Memory: 512}},
+		{"with container limits", limitsPayload{CPU: 1024, Memory: 256}, limitsPayload{}, &Limits{CPU: 1, Memory: 256}},
+		{"with lower container limits", limitsPayload{CPU: 512, Memory: 256}, limitsPayload{CPU: 2, Memory: 4096}, &Limits{CPU: 0.5, Memory: 256}},
+		{"with lower task limits", limitsPayload{CPU: 4096, Memory: 8192}, limitsPayload{CPU: 2, Memory: 4096}, &Limits{CPU: 2, Memory: 4096}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, effectiveLimits(tc.container, tc.task))
+		})
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package container_metadata
+
+import "strings"
+
+const (
+	defaultRegistry       = "docker.io"
+	defaultRepositoryPath = "library/"
+)
+
+// ImageReference is a parsed container image reference.
+type ImageReference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseImageReference splits image reference into registry, repository, tag
+// and digest. Docker Hub shorthands are normalized, e.g. "nginx" becomes
+// "docker.io/library/nginx". Tag is empty unless explicitly given.
+// Returns false if s is not a well-formed image reference.
+func ParseImageReference(s string) (ImageReference, bool) {
+	ref := ImageReference{}
+
+	name, digest, hasDigest := strings.Cut(s, "@")
+	if hasDigest {
+		if !isDigest(digest) {
+			return ImageReference{}, false
+		}
+
+		ref.Digest = digest
+	}
+
+	// A colon after the last slash separates the tag. Any other colon
+	// belongs to the registry host port.
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, ref.Tag = name[:i], name[i+1:]
+
+		if ref.Tag == "" {
+			return ImageReference{}, false
+		}
+	}
+
+	// The first path component is a registry only if it looks like a host.
+	if host, path, ok := strings.Cut(name, "/"); ok && (strings.ContainsAny(host, ".:") || host == "localhost") {
+		ref.Registry, ref.Repository = host, path
+	} else {
+		ref.Registry, ref.Repository = defaultRegistry, name
+
+		if !ok {
+			ref.Repository = defaultRepositoryPath + name
+		}
+	}
+
+	if ref.Repository == "" || strings.HasPrefix(ref.Repository, "/") || strings.HasSuffix(ref.Repository, "/") || strings.Contains(ref.Repository, "//") {
+		return ImageReference{}, false
+	}
+
+	return ref, true
+}
+
+// Name returns fully qualified image name without tag and digest.
+func (r ImageReference) Name() string {
+	if r.Repository == "" {
+		return ""
+	}
+
+	return r.Registry + "/" + r.Repository
+}
+
+func (r ImageReference) String() string {
+	s := r.Name()
+
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+
+	return s
+}
+
+func isDigest(s string) bool {
+	algorithm, hex, ok := strings.Cut(s, ":")
+
+	return ok && algorithm != "" && hex != ""
+}
@@ -0,0 +1,91 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package container_metadata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseImageReference(t *testing.T) {
+	valid := []struct {
+		name     string
+		image    string
+		expected ImageReference
+	}{
+		{
+			name:     "official image shorthand",
+			image:    "nginx",
+			expected: ImageReference{Registry: "docker.io", Repository: "library/nginx"},
+		},
+		{
+			name:     "official image with tag",
+			image:    "nginx:1.27-alpine",
+			expected: ImageReference{Registry: "docker.io", Repository: "library/nginx", Tag: "1.27-alpine"},
+		},
+		{
+			name:     "user image shorthand",
+			image:    "ixti/ecstatic:v1.0.0",
+			expected: ImageReference{Registry: "docker.io", Repository: "ixti/ecstatic", Tag: "v1.0.0"},
+		},
+		{
+			name:     "ECR image",
+			image:    "111122223333.dkr.ecr.us-west-2.amazonaws.com/team/curltest:latest",
+			expected: ImageReference{Registry: "111122223333.dkr.ecr.us-west-2.amazonaws.com", Repository: "team/curltest", Tag: "latest"},
+		},
+		{
+			name:     "registry with port",
+			image:    "registry.example.com:5000/app",
+			expected: ImageReference{Registry: "registry.example.com:5000", Repository: "app"},
+		},
+		{
+			name:     "registry with port and tag",
+			image:    "registry.example.com:5000/app:v2",
+			expected: ImageReference{Registry: "registry.example.com:5000", Repository: "app", Tag: "v2"},
+		},
+		{
+			name:     "localhost registry",
+			image:    "localhost/app:dev",
+			expected: ImageReference{Registry: "localhost", Repository: "app", Tag: "dev"},
+		},
+		{
+			name:     "digest",
+			image:    "ghcr.io/ixti/ecstatic@sha256:d691691e9652791a60114e67b365688d20d19940dde7c4736ea30e660d8d3553",
+			expected: ImageReference{Registry: "ghcr.io", Repository: "ixti/ecstatic", Digest: "sha256:d691691e9652791a60114e67b365688d20d19940dde7c4736ea30e660d8d3553"},
+		},
+		{
+			name:     "tag and digest",
+			image:    "ghcr.io/ixti/ecstatic:latest@sha256:d691691e",
+			expected: ImageReference{Registry: "ghcr.io", Repository: "ixti/ecstatic", Tag: "latest", Digest: "sha256:d691691e"},
+		},
+	}
+
+	for _, tc := range valid {
+		t.Run(tc.name, func(t *testing.T) {
+			ref, ok := ParseImageReference(tc.image)
+
+			assert.True(t, ok)
+			assert.Equal(t, tc.expected, ref)
+		})
+	}
+
+	for _, image := range []string{"", "nginx:", "nginx@", "nginx@sha256", "ghcr.io/", "ghcr.io//app", "ixti/"} {
+		t.Run("invalid "+image, func(t *testing.T) {
+			_, ok := ParseImageReference(image)
+
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestImageReference_String(t *testing.T) {
+	assert := assert.New(t)
+
+	ref, _ := ParseImageReference("nginx:1.27@sha256:d691691e")
+	assert.Equal("docker.io/library/nginx:1.27@sha256:d691691e", ref.String())
+
+	ref, _ = ParseImageReference("localhost:5000/app")
+	assert.Equal("localhost:5000/app", ref.String())
+}
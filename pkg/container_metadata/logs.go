@@ -0,0 +1,81 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package container_metadata
+
+import (
+	"net/url"
+	"strings"
+)
+
+// LogGroup returns CloudWatch Logs group of the container.
+// Returns empty string unless the container uses awslogs log driver.
+func (m *Metadata) LogGroup() string {
+	return m.awslogsOption("awslogs-group")
+}
+
+// LogRegion returns CloudWatch Logs region of the container.
+// Defaults to the task region when not configured explicitly.
+func (m *Metadata) LogRegion() string {
+	if m.LogGroup() == "" {
+		return ""
+	}
+
+	if region := m.awslogsOption("awslogs-region"); region != "" {
+		return region
+	}
+
+	return m.Region()
+}
+
+// LogStream returns CloudWatch Logs stream of the container.
+// When the agent does not report the stream name, it's computed from the
+// stream prefix as prefix/container-name/task-id.
+func (m *Metadata) LogStream() string {
+	if stream := m.awslogsOption("awslogs-stream"); stream != "" {
+		return stream
+	}
+
+	prefix := m.awslogsOption("awslogs-stream-prefix")
+	taskID := m.TaskID()
+
+	if prefix == "" || m.ContainerName == "" || taskID == "" {
+		return ""
+	}
+
+	return prefix + "/" + m.ContainerName + "/" + taskID
+}
+
+// LogURL returns CloudWatch console link to the container log stream,
+// or to the log group if the stream is unknown.
+func (m *Metadata) LogURL() string {
+	group := m.LogGroup()
+	region := m.LogRegion()
+
+	if group == "" || region == "" {
+		return ""
+	}
+
+	fragment := "logsV2:log-groups/log-group/" + consoleEscape(group)
+	if stream := m.LogStream(); stream != "" {
+		fragment += "/log-events/" + consoleEscape(stream)
+	}
+
+	arn, _ := ParseARN(m.TaskARN)
+
+	return consoleBaseURL(arn.Partition, region) + "/cloudwatch/home?region=" + region + "#" + fragment
+}
+
+func (m *Metadata) awslogsOption(name string) string {
+	if m.LogDriver != "awslogs" {
+		return ""
+	}
+
+	return m.LogOptions[name]
+}
+
+// consoleEscape encodes value the way CloudWatch console expects in URL
+// fragments: URL-encoded, with percent signs encoded once more as "$25".
+func consoleEscape(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "%", "$25")
+}
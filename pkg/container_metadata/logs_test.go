@@ -0,0 +1,95 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package container_metadata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testLogsMetadata() *Metadata {
+	metadata := testMetadata()
+	metadata.LogDriver = "awslogs"
+	metadata.LogOptions = map[string]string{
+		"awslogs-group":         "/ecs/curltest",
+		"awslogs-region":        "eu-central-1",
+		"awslogs-stream-prefix": "ecs",
+	}
+
+	return metadata
+}
+
+func TestMetadata_LogGroup(t *testing.T) {
+	t.Run("with awslogs driver", func(t *testing.T) {
+		assert.Equal(t, "/ecs/curltest", testLogsMetadata().LogGroup())
+	})
+
+	t.Run("with other driver", func(t *testing.T) {
+		metadata := testLogsMetadata()
+		metadata.LogDriver = "json-file"
+
+		assert.Equal(t, "", metadata.LogGroup())
+	})
+}
+
+func TestMetadata_LogRegion(t *testing.T) {
+	t.Run("with explicit region", func(t *testing.T) {
+		assert.Equal(t, "eu-central-1", testLogsMetadata().LogRegion())
+	})
+
+	t.Run("defaults to task region", func(t *testing.T) {
+		metadata := testLogsMetadata()
+		delete(metadata.LogOptions, "awslogs-region")
+
+		assert.Equal(t, "us-west-2", metadata.LogRegion())
+	})
+
+	t.Run("without log group", func(t *testing.T) {
+		assert.Equal(t, "", testMetadata().LogRegion())
+	})
+}
+
+func TestMetadata_LogStream(t *testing.T) {
+	t.Run("computed from stream prefix", func(t *testing.T) {
+		assert.Equal(t, "ecs/curl/8f03e41243824aea923aca126495f665", testLogsMetadata().LogStream())
+	})
+
+	t.Run("reported by agent", func(t *testing.T) {
+		metadata := testLogsMetadata()
+		metadata.LogOptions["awslogs-stream"] = "custom/curl/8f03e41243824aea923aca126495f665"
+
+		assert.Equal(t, "custom/curl/8f03e41243824aea923aca126495f665", metadata.LogStream())
+	})
+
+	t.Run("without stream prefix", func(t *testing.T) {
+		metadata := testLogsMetadata()
+		delete(metadata.LogOptions, "awslogs-stream-prefix")
+
+		assert.Equal(t, "", metadata.LogStream())
+	})
+}
+
+func TestMetadata_LogURL(t *testing.T) {
+	t.Run("with log stream", func(t *testing.T) {
+		assert.Equal(t,
+			"https://eu-central-1.console.aws.amazon.com/cloudwatch/home?region=eu-central-1#logsV2:log-groups/log-group/$252Fecs$252Fcurltest/log-events/ecs$252Fcurl$252F8f03e41243824aea923aca126495f665",
+			testLogsMetadata().LogURL(),
+		)
+	})
+
+	t.Run("without log stream", func(t *testing.T) {
+		metadata := testLogsMetadata()
+		delete(metadata.LogOptions, "awslogs-stream-prefix")
+
+		assert.Equal(t,
+			"https://eu-central-1.console.aws.amazon.com/cloudwatch/home?region=eu-central-1#logsV2:log-groups/log-group/$252Fecs$252Fcurltest",
+			metadata.LogURL(),
+		)
+	})
+
+	t.Run("without awslogs driver", func(t *testing.T) {
+		assert.Equal(t, "", testMetadata().LogURL())
+	})
+}
@@ -3,70 +3,165 @@
 
 package container_metadata
 
-import "strings"
+import (
+	"strings"
+
+	"github.com/ixti/ecs-task-helper/pkg/environ"
+)
 
 type Metadata struct {
-	ContainerARN          string `json:"containerARN"`
-	ContainerName         string `json:"containerName"`
-	ContainerImage        string `json:"containerImage"`
-	TaskARN               string `json:"taskARN"`
-	TaskDefinitionFamily  string `json:"taskDefinitionFamily"`
-	TaskDefinitionVersion string `json:"taskDefinitionVersion"`
-	ClusterName           string `json:"clusterName"`
+	ContainerARN          string            `json:"containerARN"`
+	ContainerID           string            `json:"containerID"`
+	ContainerName         string            `json:"containerName"`
+	ContainerImage        string            `json:"containerImage"`
+	ContainerImageID      string            `json:"containerImageID"`
+	TaskARN               string            `json:"taskARN"`
+	TaskDefinitionFamily  string            `json:"taskDefinitionFamily"`
+	TaskDefinitionVersion string            `json:"taskDefinitionVersion"`
+	ClusterName           string            `json:"clusterName"`
+	ServiceName           string            `json:"serviceName"`
+	AvailabilityZone      string            `json:"availabilityZone"`
+	LaunchType            string            `json:"launchType"`
+	LogDriver             string            `json:"logDriver"`
+	LogOptions            map[string]string `json:"logOptions,omitempty"`
+	Labels                map[string]string `json:"labels,omitempty"`
+	Limits                *Limits           `json:"limits,omitempty"`
+}
+
+// Limits holds resource limits of the container, zero if not limited.
+type Limits struct {
+	// CPU is the number of vCPUs.
+	CPU float64 `json:"cpu,omitempty"`
+	// Memory is in MiB.
+	Memory int64 `json:"memory,omitempty"`
 }
 
 // TaskID returns TaskID part of TaskARN.
 func (m *Metadata) TaskID() string {
-	if m.ClusterName == "" {
+	cluster := m.clusterShortName()
+	if cluster == "" {
 		return ""
 	}
 
-	if _, id, ok := strings.Cut(m.TaskARN, ":task/"+m.ClusterName+"/"); ok {
+	if _, id, ok := strings.Cut(m.TaskARN, ":task/"+cluster+"/"); ok {
 		return id
 	}
 
 	return ""
 }
 
+// ContainerImageReference returns parsed ContainerImage.
+// If the image is not referenced by digest, ContainerImageID is used as one.
+func (m *Metadata) ContainerImageReference() ImageReference {
+	ref, _ := ParseImageReference(m.ContainerImage)
+
+	if ref.Digest == "" && isDigest(m.ContainerImageID) {
+		ref.Digest = m.ContainerImageID
+	}
+
+	return ref
+}
+
+// ContainerImageName returns ContainerImage without tag and digest.
+func (m *Metadata) ContainerImageName() string {
+	return m.ContainerImageReference().Name()
+}
+
+// ContainerImageTag returns tag part of ContainerImage.
+func (m *Metadata) ContainerImageTag() string {
+	return m.ContainerImageReference().Tag
+}
+
+// Region returns AWS region part of TaskARN.
+func (m *Metadata) Region() string {
+	if arn, ok := ParseARN(m.TaskARN); ok {
+		return arn.Region
+	}
+
+	return ""
+}
+
+// AccountID returns AWS account ID part of TaskARN.
+func (m *Metadata) AccountID() string {
+	if arn, ok := ParseARN(m.TaskARN); ok {
+		return arn.AccountID
+	}
+
+	return ""
+}
+
+// ClusterARN returns ARN of the cluster the task is running in.
+// ClusterName is returned as is if it's already an ARN, otherwise
+// the ARN is built from the TaskARN components.
+func (m *Metadata) ClusterARN() string {
+	if m.ClusterName == "" {
+		return ""
+	}
+
+	if _, ok := ParseARN(m.ClusterName); ok {
+		return m.ClusterName
+	}
+
+	arn, ok := ParseARN(m.TaskARN)
+	if !ok {
+		return ""
+	}
+
+	arn.Resource = "cluster/" + m.ClusterName
+
+	return arn.String()
+}
+
 // EnvironWith returns ECS metadata as environment variables.
 // If base is nil, returns only the ECS metadata variables.
 // If base is provided, returns base with ECS metadata variables merged in
-// (overriding any existing).
-func (m *Metadata) EnvironWith(base []string) []string {
-	overrides := []string{
+// (overriding any existing). Each of layers, e.g. variables of env files,
+// overrides variables of base and preceding layers, but not ECS metadata.
+func (m *Metadata) EnvironWith(base []string, layers ...[]string) []string {
+	return environ.Merge(append(append([][]string{base}, layers...), m.variables())...)
+}
+
+// EnvironWithDefaults is like EnvironWith, but ECS metadata variables only
+// fill in variables that are unset or empty after merging layers into base.
+func (m *Metadata) EnvironWithDefaults(base []string, layers ...[]string) []string {
+	env := environ.Merge(append([][]string{base}, layers...)...)
+
+	for _, v := range m.variables() {
+		key, value, _ := strings.Cut(v, "=")
+
+		if existing, _ := environ.Lookup(env, key); existing == "" {
+			env = environ.Set(env, key, value)
+		}
+	}
+
+	return env
+}
+
+func (m *Metadata) variables() []string {
+	image := m.ContainerImageReference()
+
+	return []string{
 		"ECS_CONTAINER_ARN=" + m.ContainerARN,
 		"ECS_CONTAINER_NAME=" + m.ContainerName,
 		"ECS_CONTAINER_IMAGE=" + m.ContainerImage,
+		"ECS_CONTAINER_IMAGE_REGISTRY=" + image.Registry,
+		"ECS_CONTAINER_IMAGE_REPOSITORY=" + image.Repository,
+		"ECS_CONTAINER_IMAGE_TAG=" + image.Tag,
+		"ECS_CONTAINER_IMAGE_DIGEST=" + image.Digest,
 		"ECS_TASK_ARN=" + m.TaskARN,
 		"ECS_TASK_ID=" + m.TaskID(),
 		"ECS_TASK_DEFINITION_FAMILY=" + m.TaskDefinitionFamily,
 		"ECS_TASK_DEFINITION_VERSION=" + m.TaskDefinitionVersion,
 		"ECS_CLUSTER_NAME=" + m.ClusterName,
+		"ECS_LOG_GROUP=" + m.LogGroup(),
+		"ECS_LOG_STREAM=" + m.LogStream(),
+		"ECS_LOG_REGION=" + m.LogRegion(),
+		"ECS_LOG_URL=" + m.LogURL(),
 	}
-
-	if base == nil {
-		return overrides
-	}
-
-	keys := make(map[string]struct{}, len(overrides))
-	for _, v := range overrides {
-		key, _, _ := strings.Cut(v, "=")
-		keys[key] = struct{}{}
-	}
-
-	merged := make([]string, 0, len(base)+len(overrides))
-	for _, v := range base {
-		key, _, _ := strings.Cut(v, "=")
-		if _, exists := keys[key]; !exists {
-			merged = append(merged, v)
-		}
-	}
-
-	return append(merged, overrides...)
 }
 
 // Environ returns only the ECS metadata environment variables.
 // Equivalent to EnvironWith(nil).
 func (m *Metadata) Environ() []string {
-	return m.EnvironWith(nil)
+	return m.variables()
 }
@@ -28,11 +28,19 @@ func expectedOverrides() []string {
 		"ECS_CONTAINER_ARN=arn:aws:ecs:us-west-2:111122223333:container/0206b271-b33f-47ab-86c6-a0ba208a70a9",
 		"ECS_CONTAINER_NAME=curl",
 		"ECS_CONTAINER_IMAGE=111122223333.dkr.ecr.us-west-2.amazonaws.com/curltest:latest",
+		"ECS_CONTAINER_IMAGE_REGISTRY=111122223333.dkr.ecr.us-west-2.amazonaws.com",
+		"ECS_CONTAINER_IMAGE_REPOSITORY=curltest",
+		"ECS_CONTAINER_IMAGE_TAG=latest",
+		"ECS_CONTAINER_IMAGE_DIGEST=",
 		"ECS_TASK_ARN=arn:aws:ecs:us-west-2:111122223333:task/default/8f03e41243824aea923aca126495f665",
 		"ECS_TASK_ID=8f03e41243824aea923aca126495f665",
 		"ECS_TASK_DEFINITION_FAMILY=curltest",
 		"ECS_TASK_DEFINITION_VERSION=24",
 		"ECS_CLUSTER_NAME=default",
+		"ECS_LOG_GROUP=",
+		"ECS_LOG_STREAM=",
+		"ECS_LOG_REGION=",
+		"ECS_LOG_URL=",
 	}
 }
 
@@ -55,12 +63,99 @@ func TestMetadata_TaskID(t *testing.T) {
 		assert.Equal(t, "", metadata.TaskID())
 	})
 
+	t.Run("with cluster ARN", func(t *testing.T) {
+		metadata := &Metadata{
+			ClusterName: "arn:aws:ecs:us-west-2:111122223333:cluster/default",
+			TaskARN:     "arn:aws:ecs:us-west-2:111122223333:task/default/8f03e41243824aea923aca126495f665",
+		}
+
+		assert.Equal(t, "8f03e41243824aea923aca126495f665", metadata.TaskID())
+	})
+
 	t.Run("with blank TaskARN", func(t *testing.T) {
 		metadata := &Metadata{}
 		assert.Equal(t, "", metadata.TaskID())
 	})
 }
 
+func TestMetadata_ContainerImageReference(t *testing.T) {
+	t.Run("with tag", func(t *testing.T) {
+		assert.Equal(t, ImageReference{
+			Registry:   "111122223333.dkr.ecr.us-west-2.amazonaws.com",
+			Repository: "curltest",
+			Tag:        "latest",
+		}, testMetadata().ContainerImageReference())
+	})
+
+	t.Run("with image ID uses it as digest", func(t *testing.T) {
+		metadata := testMetadata()
+		metadata.ContainerImageID = "sha256:d691691e9652791a60114e67b365688d20d19940dde7c4736ea30e660d8d3553"
+
+		assert.Equal(t, "sha256:d691691e9652791a60114e67b365688d20d19940dde7c4736ea30e660d8d3553", metadata.ContainerImageReference().Digest)
+	})
+
+	t.Run("with digest in reference ignores image ID", func(t *testing.T) {
+		metadata := &Metadata{
+			ContainerImage:   "nginx@sha256:aaaa",
+			ContainerImageID: "sha256:bbbb",
+		}
+
+		assert.Equal(t, "sha256:aaaa", metadata.ContainerImageReference().Digest)
+	})
+
+	t.Run("with empty image", func(t *testing.T) {
+		assert.Equal(t, ImageReference{}, (&Metadata{}).ContainerImageReference())
+	})
+}
+
+func TestMetadata_ContainerImageName(t *testing.T) {
+	assert.Equal(t, "111122223333.dkr.ecr.us-west-2.amazonaws.com/curltest", testMetadata().ContainerImageName())
+	assert.Equal(t, "docker.io/library/nginx", (&Metadata{ContainerImage: "nginx:1.27"}).ContainerImageName())
+	assert.Equal(t, "", (&Metadata{}).ContainerImageName())
+}
+
+func TestMetadata_ContainerImageTag(t *testing.T) {
+	assert.Equal(t, "latest", testMetadata().ContainerImageTag())
+	assert.Equal(t, "", (&Metadata{ContainerImage: "localhost:5000/app"}).ContainerImageTag())
+}
+
+func TestMetadata_Region(t *testing.T) {
+	assert.Equal(t, "us-west-2", testMetadata().Region())
+	assert.Equal(t, "", (&Metadata{}).Region())
+}
+
+func TestMetadata_AccountID(t *testing.T) {
+	assert.Equal(t, "111122223333", testMetadata().AccountID())
+	assert.Equal(t, "", (&Metadata{}).AccountID())
+}
+
+func TestMetadata_ClusterARN(t *testing.T) {
+	t.Run("with cluster name", func(t *testing.T) {
+		assert.Equal(t, "arn:aws:ecs:us-west-2:111122223333:cluster/default", testMetadata().ClusterARN())
+	})
+
+	t.Run("with cluster ARN", func(t *testing.T) {
+		metadata := testMetadata()
+		metadata.ClusterName = "arn:aws:ecs:us-west-2:111122223333:cluster/production"
+
+		assert.Equal(t, "arn:aws:ecs:us-west-2:111122223333:cluster/production", metadata.ClusterARN())
+	})
+
+	t.Run("with blank cluster name", func(t *testing.T) {
+		metadata := testMetadata()
+		metadata.ClusterName = ""
+
+		assert.Equal(t, "", metadata.ClusterARN())
+	})
+
+	t.Run("with invalid TaskARN", func(t *testing.T) {
+		metadata := testMetadata()
+		metadata.TaskARN = "invalid"
+
+		assert.Equal(t, "", metadata.ClusterARN())
+	})
+}
+
 func TestMetadata_ToJSON(t *testing.T) {
 	assert := assert.New(t)
 	require := require.New(t)
@@ -73,12 +168,18 @@ func TestMetadata_ToJSON(t *testing.T) {
 
 	assert.Equal(map[string]string{
 		"containerARN":          "arn:aws:ecs:us-west-2:111122223333:container/0206b271-b33f-47ab-86c6-a0ba208a70a9",
+		"containerID":           "",
 		"containerName":         "curl",
 		"containerImage":        "111122223333.dkr.ecr.us-west-2.amazonaws.com/curltest:latest",
+		"containerImageID":      "",
 		"taskARN":               "arn:aws:ecs:us-west-2:111122223333:task/default/8f03e41243824aea923aca126495f665",
 		"taskDefinitionFamily":  "curltest",
 		"taskDefinitionVersion": "24",
 		"clusterName":           "default",
+		"serviceName":           "",
+		"availabilityZone":      "",
+		"launchType":            "",
+		"logDriver":             "",
 	}, result)
 }
 
@@ -150,4 +251,35 @@ func TestMetadata_EnvironWith(t *testing.T) {
 		assert.Equal(envLen, len(base)+overridesLen)
 		assert.Equal(overrides, env[envLen-overridesLen:])
 	})
+
+	t.Run("with layers", func(t *testing.T) {
+		assert := assert.New(t)
+
+		base := []string{"PATH=/usr/bin", "APP_ENV=test", "APP_PORT=80"}
+		layers := [][]string{
+			{"APP_ENV=development", "APP_PORT=8080"},
+			{"APP_ENV=production", "ECS_CONTAINER_NAME=fake"},
+		}
+
+		env := testMetadata().EnvironWith(base, layers...)
+
+		assert.Equal([]string{"PATH=/usr/bin", "APP_PORT=8080", "APP_ENV=production"}, env[:3])
+		assert.Equal(expectedOverrides(), env[3:])
+	})
+}
+
+func TestMetadata_EnvironWithDefaults(t *testing.T) {
+	t.Run("keeps existing values", func(t *testing.T) {
+		assert := assert.New(t)
+
+		base := []string{"PATH=/usr/bin", "ECS_CLUSTER_NAME=local", "ECS_TASK_ID="}
+		layers := [][]string{{"ECS_CONTAINER_NAME=fake"}}
+
+		env := testMetadata().EnvironWithDefaults(base, layers...)
+
+		assert.Equal([]string{"PATH=/usr/bin", "ECS_CLUSTER_NAME=local", "ECS_CONTAINER_NAME=fake"}, env[:3])
+		assert.Contains(env, "ECS_TASK_ID=8f03e41243824aea923aca126495f665")
+		assert.Contains(env, "ECS_TASK_ARN=arn:aws:ecs:us-west-2:111122223333:task/default/8f03e41243824aea923aca126495f665")
+		assert.Len(env, 1+len(expectedOverrides()))
+	})
 }
@@ -0,0 +1,345 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+// Package dotenv parses environment files in the dotenv format:
+//
+//	# comment
+//	export NAME=value # inline comment
+//	SINGLE='literal $value'
+//	DOUBLE="line\nbreak, ${NAME} and \$escaped"
+//	INTERPOLATED=${NAME:-default}/path
+//
+// Single-quoted values are literal. Double-quoted values may span multiple
+// lines and support backslash escapes. Unquoted and double-quoted values
+// expand $NAME, ${NAME} and ${NAME:-default} references.
+package dotenv
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var ErrUnterminatedQuote = errors.New("unterminated quote")
+
+// LookupFunc returns the value of a variable referenced by an env file that
+// is not defined by the file itself.
+type LookupFunc func(name string) (string, bool)
+
+// ParseFile parses env file at path. See Parse.
+func ParseFile(path string, lookup LookupFunc) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read env file: %w", err)
+	}
+
+	env, err := Parse(string(data), lookup)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", path, err)
+	}
+
+	return env, nil
+}
+
+// Parse parses env file content into "KEY=value" entries in the order of
+// definition. References are resolved against variables defined earlier in
+// the file, and then with lookup, which may be nil. Errors are prefixed with
+// the line number.
+func Parse(content string, lookup LookupFunc) ([]string, error) {
+	p := &parser{input: content, line: 1, lookup: lookup, values: map[string]string{}}
+
+	var env []string
+
+	for {
+		p.skipBlank()
+
+		if p.eof() {
+			return env, nil
+		}
+
+		line := p.line
+
+		name, value, err := p.parseEntry()
+		if err != nil {
+			return nil, fmt.Errorf("%d: %w", line, err)
+		}
+
+		p.values[name] = value
+		env = append(env, name+"="+value)
+	}
+}
+
+type parser struct {
+	input  string
+	pos    int
+	line   int
+	lookup LookupFunc
+	values map[string]string
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+
+	return p.input[p.pos]
+}
+
+func (p *parser) next() byte {
+	c := p.input[p.pos]
+	p.pos++
+
+	if c == '\n' {
+		p.line++
+	}
+
+	return c
+}
+
+// skipBlank skips whitespace, empty lines and comment lines.
+func (p *parser) skipBlank() {
+	for !p.eof() {
+		switch p.peek() {
+		case ' ', '\t', '\r', '\n':
+			p.next()
+		case '#':
+			p.skipLine()
+		default:
+			return
+		}
+	}
+}
+
+func (p *parser) skipSpaces() {
+	for isSpace(p.peek()) {
+		p.next()
+	}
+}
+
+func (p *parser) skipLine() {
+	for !p.eof() && p.next() != '\n' {
+	}
+}
+
+func (p *parser) parseEntry() (string, string, error) {
+	name := p.parseName()
+
+	if name == "export" && isSpace(p.peek()) {
+		p.skipSpaces()
+		name = p.parseName()
+	}
+
+	if name == "" {
+		return "", "", errors.New("invalid variable name")
+	}
+
+	p.skipSpaces()
+
+	if p.peek() != '=' {
+		return "", "", fmt.Errorf("missing = after %s", name)
+	}
+
+	p.next()
+	p.skipSpaces()
+
+	var (
+		value string
+		err   error
+	)
+
+	switch p.peek() {
+	case '\'':
+		value, err = p.parseSingleQuoted()
+	case '"':
+		value, err = p.parseDoubleQuoted()
+	default:
+		return name, p.parseUnquoted(), nil
+	}
+
+	if err != nil {
+		return "", "", fmt.Errorf("%w in value of %s", err, name)
+	}
+
+	// Only a comment may follow the closing quote.
+	p.skipSpaces()
+
+	switch p.peek() {
+	case '#', '\r', '\n', 0:
+		p.skipLine()
+	default:
+		return "", "", fmt.Errorf("unexpected character after quoted value of %s", name)
+	}
+
+	return name, value, nil
+}
+
+func (p *parser) parseName() string {
+	start := p.pos
+
+	for !p.eof() && isNameChar(p.peek(), p.pos == start) {
+		p.next()
+	}
+
+	return p.input[start:p.pos]
+}
+
+func (p *parser) parseSingleQuoted() (string, error) {
+	p.next()
+
+	start := p.pos
+
+	for !p.eof() {
+		if p.next() == '\'' {
+			return p.input[start : p.pos-1], nil
+		}
+	}
+
+	return "", ErrUnterminatedQuote
+}
+
+func (p *parser) parseDoubleQuoted() (string, error) {
+	p.next()
+
+	var sb strings.Builder
+
+	for !p.eof() {
+		switch c := p.next(); c {
+		case '"':
+			return sb.String(), nil
+
+		case '\\':
+			if p.eof() {
+				return "", ErrUnterminatedQuote
+			}
+
+			sb.WriteString(unescape(p.next()))
+
+		case '$':
+			sb.WriteString(p.parseReference())
+
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return "", ErrUnterminatedQuote
+}
+
+// parseUnquoted reads value until the end of line or inline comment, which
+// must be preceded by whitespace. Trailing whitespace is trimmed.
+func (p *parser) parseUnquoted() string {
+	var sb strings.Builder
+
+	for !p.eof() {
+		c := p.peek()
+
+		if c == '\n' || (c == '#' && isSpace(p.input[p.pos-1])) {
+			break
+		}
+
+		p.next()
+
+		switch {
+		case c == '\\' && p.peek() == '$':
+			sb.WriteByte(p.next())
+		case c == '$':
+			sb.WriteString(p.parseReference())
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	p.skipLine()
+
+	return strings.TrimRight(sb.String(), " \t\r")
+}
+
+// parseReference expands reference following "$". A "$" not followed by
+// a valid reference is literal.
+func (p *parser) parseReference() string {
+	if p.peek() != '{' {
+		name := p.parseName()
+		if name == "" {
+			return "$"
+		}
+
+		value, _ := p.resolve(name)
+
+		return value
+	}
+
+	start := p.pos
+	p.next()
+
+	name := p.parseName()
+
+	switch {
+	case name != "" && p.peek() == '}':
+		p.next()
+
+		value, _ := p.resolve(name)
+
+		return value
+
+	case name != "" && strings.HasPrefix(p.input[p.pos:], ":-"):
+		end := strings.IndexAny(p.input[p.pos:], "}\n")
+		if end < 0 || p.input[p.pos+end] != '}' {
+			break
+		}
+
+		fallback := p.input[p.pos+2 : p.pos+end]
+		p.pos += end + 1
+
+		if value, _ := p.resolve(name); value != "" {
+			return value
+		}
+
+		return fallback
+	}
+
+	// Not a valid reference, keep it as is.
+	p.pos = start
+
+	return "$"
+}
+
+func (p *parser) resolve(name string) (string, bool) {
+	if value, ok := p.values[name]; ok {
+		return value, true
+	}
+
+	if p.lookup != nil {
+		return p.lookup(name)
+	}
+
+	return "", false
+}
+
+func unescape(c byte) string {
+	switch c {
+	case 'n':
+		return "\n"
+	case 'r':
+		return "\r"
+	case 't':
+		return "\t"
+	case '\n':
+		// Line continuation.
+		return ""
+	default:
+		return string(c)
+	}
+}
+
+func isNameChar(c byte, first bool) bool {
+	return c == '_' || ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || (!first && '0' <= c && c <= '9')
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
@@ -0,0 +1,118 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package dotenv
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lookupMap(m map[string]string) LookupFunc {
+	return func(name string) (string, bool) {
+		v, ok := m[name]
+		return v, ok
+	}
+}
+
+func TestParse(t *testing.T) {
+	valid := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"empty", "", nil},
+		{"comments and blank lines", "# comment\n\n  # indented\nA=1\n", []string{"A=1"}},
+		{"export prefix", "export A=1\nexport\tB=2", []string{"A=1", "B=2"}},
+		{"variable named export", "export=1", []string{"export=1"}},
+		{"spaces around =", "A = 1 ", []string{"A=1"}},
+		{"empty value", "A=\nB=", []string{"A=", "B="}},
+		{"inline comment", "A=1 # comment\nB=a#b", []string{"A=1", "B=a#b"}},
+		{"CRLF line endings", "A=1\r\nB='2'\r\n", []string{"A=1", "B=2"}},
+		{"single quotes are literal", `A='$HOME \n # x'`, []string{`A=$HOME \n # x`}},
+		{"double quote escapes", `A="a\"b\\c\$d\n\te"`, []string{"A=a\"b\\c$d\n\te"}},
+		{"multi-line double quotes", "A=\"line 1\nline 2\"\nB=2", []string{"A=line 1\nline 2", "B=2"}},
+		{"comment after quotes", `A="1" # comment`, []string{"A=1"}},
+		{"interpolation", "A=1\nB=$A-${A}\nC=\"${B}\"", []string{"A=1", "B=1-1", "C=1-1"}},
+		{"default", "A=${MISSING:-fallback}\nE=\nB=${E:-empty}\nC=${A:-x}", []string{"A=fallback", "E=", "B=empty", "C=fallback"}},
+		{"missing variable", "A=x${MISSING}y", []string{"A=xy"}},
+		{"escaped reference", `A=\$HOME`, []string{"A=$HOME"}},
+		{"literal dollar", "A=$ 5\nB=${\nC=${ A}", []string{"A=$ 5", "B=${", "C=${ A}"}},
+		{"later definition wins", "A=1\nA=2", []string{"A=1", "A=2"}},
+	}
+
+	for _, tc := range valid {
+		t.Run(tc.name, func(t *testing.T) {
+			env, err := Parse(tc.input, nil)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, env)
+		})
+	}
+
+	t.Run("with lookup", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		lookup := lookupMap(map[string]string{"HOME": "/home/test", "A": "outer"})
+
+		env, err := Parse("CACHE=$HOME/.cache\nA=inner\nB=$A", lookup)
+
+		require.NoError(err)
+		assert.Equal([]string{"CACHE=/home/test/.cache", "A=inner", "B=inner"}, env)
+	})
+
+	invalid := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{"invalid name", "\n1A=1", "2: invalid variable name"},
+		{"missing =", "A", "1: missing = after A"},
+		{"unterminated single quote", "A='1", "1: unterminated quote in value of A"},
+		{"unterminated double quote", "A=\"1\nB=2", "1: unterminated quote in value of A"},
+		{"garbage after quotes", `A="1"2`, "1: unexpected character after quoted value of A"},
+	}
+
+	for _, tc := range invalid {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse(tc.input, nil)
+
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	t.Run("with valid file", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		path := filepath.Join(t.TempDir(), ".env")
+		require.NoError(os.WriteFile(path, []byte("A=1\n"), 0o644))
+
+		env, err := ParseFile(path, nil)
+
+		require.NoError(err)
+		assert.Equal([]string{"A=1"}, env)
+	})
+
+	t.Run("with invalid file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), ".env")
+		require.NoError(t, os.WriteFile(path, []byte("A=1\nB\n"), 0o644))
+
+		_, err := ParseFile(path, nil)
+
+		assert.EqualError(t, err, path+":2: missing = after B")
+	})
+
+	t.Run("with missing file", func(t *testing.T) {
+		_, err := ParseFile(filepath.Join(t.TempDir(), ".env"), nil)
+
+		assert.ErrorContains(t, err, "failed to read env file")
+	})
+}
@@ -0,0 +1,79 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+// Package environ provides helpers for manipulating environment lists in
+// the "KEY=value" form used by os.Environ and execve(2).
+package environ
+
+import "strings"
+
+// Lookup returns the value of the last entry for key in env.
+func Lookup(env []string, key string) (string, bool) {
+	for i := len(env) - 1; i >= 0; i-- {
+		if k, v, _ := strings.Cut(env[i], "="); k == key {
+			return v, true
+		}
+	}
+
+	return "", false
+}
+
+// Set returns env with key set to value.
+// All existing entries for key are removed and the new one is appended.
+func Set(env []string, key string, value string) []string {
+	return append(Unset(env, key), key+"="+value)
+}
+
+// Unset returns env without entries for key.
+func Unset(env []string, key string) []string {
+	result := make([]string, 0, len(env)+1)
+	for _, v := range env {
+		if k, _, _ := strings.Cut(v, "="); k != key {
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
+
+// Filter returns env with only entries for keys keep returns true for.
+func Filter(env []string, keep func(key string) bool) []string {
+	result := make([]string, 0, len(env))
+	for _, v := range env {
+		if k, _, _ := strings.Cut(v, "="); keep(k) {
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
+
+// Merge merges layers of environment. Entries of each layer override entries
+// with the same key of preceding layers, and the last entry wins within
+// a layer. Order of the surviving entries is preserved.
+func Merge(layers ...[]string) []string {
+	type position struct{ layer, index int }
+
+	winners := map[string]position{}
+	size := 0
+
+	for i, layer := range layers {
+		for j, v := range layer {
+			k, _, _ := strings.Cut(v, "=")
+			winners[k] = position{i, j}
+		}
+
+		size += len(layer)
+	}
+
+	merged := make([]string, 0, min(size, len(winners)))
+	for i, layer := range layers {
+		for j, v := range layer {
+			if k, _, _ := strings.Cut(v, "="); winners[k] == (position{i, j}) {
+				merged = append(merged, v)
+			}
+		}
+	}
+
+	return merged
+}
@@ -0,0 +1,101 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package environ
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookup(t *testing.T) {
+	t.Run("with existing key", func(t *testing.T) {
+		assert := assert.New(t)
+
+		value, ok := Lookup([]string{"PATH=/usr/bin", "HOME=/home/test"}, "HOME")
+
+		assert.True(ok)
+		assert.Equal("/home/test", value)
+	})
+
+	t.Run("with duplicate keys returns last", func(t *testing.T) {
+		assert := assert.New(t)
+
+		value, ok := Lookup([]string{"HOME=/root", "HOME=/home/test"}, "HOME")
+
+		assert.True(ok)
+		assert.Equal("/home/test", value)
+	})
+
+	t.Run("with empty value", func(t *testing.T) {
+		assert := assert.New(t)
+
+		value, ok := Lookup([]string{"EMPTY="}, "EMPTY")
+
+		assert.True(ok)
+		assert.Equal("", value)
+	})
+
+	t.Run("with missing key", func(t *testing.T) {
+		assert := assert.New(t)
+
+		_, ok := Lookup([]string{"PATH=/usr/bin"}, "PATHS")
+
+		assert.False(ok)
+	})
+}
+
+func TestSet(t *testing.T) {
+	t.Run("appends new key", func(t *testing.T) {
+		assert.Equal(t, []string{"PATH=/usr/bin", "HOME=/home/test"}, Set([]string{"PATH=/usr/bin"}, "HOME", "/home/test"))
+	})
+
+	t.Run("replaces existing keys", func(t *testing.T) {
+		env := []string{"HOME=/root", "PATH=/usr/bin", "HOME=/tmp"}
+
+		assert.Equal(t, []string{"PATH=/usr/bin", "HOME=/home/test"}, Set(env, "HOME", "/home/test"))
+	})
+
+	t.Run("does not modify original", func(t *testing.T) {
+		env := []string{"HOME=/root", "PATH=/usr/bin"}
+
+		Set(env, "HOME", "/home/test")
+
+		assert.Equal(t, []string{"HOME=/root", "PATH=/usr/bin"}, env)
+	})
+}
+
+func TestUnset(t *testing.T) {
+	env := []string{"HOME=/root", "PATH=/usr/bin", "HOME=/tmp"}
+
+	assert.Equal(t, []string{"PATH=/usr/bin"}, Unset(env, "HOME"))
+}
+
+func TestFilter(t *testing.T) {
+	env := []string{"PATH=/usr/bin", "HOME=/root", "LC_ALL=C", "MALFORMED"}
+
+	result := Filter(env, func(key string) bool { return key != "HOME" })
+
+	assert.Equal(t, []string{"PATH=/usr/bin", "LC_ALL=C", "MALFORMED"}, result)
+}
+
+func TestMerge(t *testing.T) {
+	t.Run("later layers override earlier ones", func(t *testing.T) {
+		merged := Merge(
+			[]string{"PATH=/usr/bin", "HOME=/root", "USER=root"},
+			[]string{"HOME=/home/test"},
+			[]string{"USER=test", "LANG=C"},
+		)
+
+		assert.Equal(t, []string{"PATH=/usr/bin", "HOME=/home/test", "USER=test", "LANG=C"}, merged)
+	})
+
+	t.Run("last entry wins within layer", func(t *testing.T) {
+		assert.Equal(t, []string{"HOME=/home/test"}, Merge([]string{"HOME=/root", "HOME=/home/test"}))
+	})
+
+	t.Run("without layers", func(t *testing.T) {
+		assert.Empty(t, Merge())
+	})
+}
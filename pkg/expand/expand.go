@@ -0,0 +1,245 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+// Package expand expands variable references in environment values and
+// command arguments:
+//
+//	${NAME}            value of NAME, empty if unset
+//	${NAME:-default}   default if NAME is unset or empty
+//	${NAME:?message}   error if NAME is unset or empty
+//	$$                 literal "$"
+//
+// Defaults and messages may contain references themselves. A "$" followed by
+// anything else is literal.
+package expand
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrCycle = errors.New("reference cycle")
+
+// LookupFunc returns the value of a referenced variable.
+type LookupFunc func(name string) (string, bool, error)
+
+// String expands references in s with lookup.
+func String(s string, lookup LookupFunc) (string, error) {
+	var sb strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+
+		switch s[i+1] {
+		case '$':
+			sb.WriteByte('$')
+			i++
+
+		case '{':
+			end := closingBrace(s, i+2)
+			if end < 0 {
+				return "", fmt.Errorf("unterminated reference: %s", s[i:])
+			}
+
+			value, err := expandReference(s[i+2:end], lookup)
+			if err != nil {
+				return "", err
+			}
+
+			sb.WriteString(value)
+			i = end
+
+		default:
+			sb.WriteByte('$')
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// Environ expands references in values of env against the variables of env
+// itself. Referenced values are expanded first, so the result does not
+// depend on the order of variables.
+func Environ(env []string) ([]string, error) {
+	return EnvironMatching(env, func(string) bool { return true })
+}
+
+// EnvironMatching is like Environ, but only expands values of variables
+// matching match. Values of other variables are kept, and referenced, as is.
+func EnvironMatching(env []string, match func(name string) bool) ([]string, error) {
+	e := &environExpander{raw: map[string]string{}, expanded: map[string]string{}, visiting: map[string]bool{}, match: match}
+
+	for _, v := range env {
+		name, value, _ := strings.Cut(v, "=")
+		e.raw[name] = value
+	}
+
+	result := make([]string, 0, len(env))
+
+	for _, v := range env {
+		name, _, hasValue := strings.Cut(v, "=")
+		if !hasValue || !match(name) {
+			result = append(result, v)
+			continue
+		}
+
+		value, _, err := e.lookup(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to expand %s: %w", name, err)
+		}
+
+		result = append(result, name+"="+value)
+	}
+
+	return result, nil
+}
+
+// LookupEnviron returns LookupFunc of variables of env.
+func LookupEnviron(env []string) LookupFunc {
+	values := make(map[string]string, len(env))
+	for _, v := range env {
+		name, value, _ := strings.Cut(v, "=")
+		values[name] = value
+	}
+
+	return func(name string) (string, bool, error) {
+		value, ok := values[name]
+		return value, ok, nil
+	}
+}
+
+type environExpander struct {
+	raw      map[string]string
+	expanded map[string]string
+	visiting map[string]bool
+	path     []string
+	match    func(name string) bool
+}
+
+func (e *environExpander) lookup(name string) (string, bool, error) {
+	if value, ok := e.expanded[name]; ok {
+		return value, true, nil
+	}
+
+	raw, ok := e.raw[name]
+	if !ok {
+		return "", false, nil
+	}
+
+	if !e.match(name) {
+		return raw, true, nil
+	}
+
+	if e.visiting[name] {
+		return "", false, fmt.Errorf("%w: %s -> %s", ErrCycle, strings.Join(e.path, " -> "), name)
+	}
+
+	e.visiting[name] = true
+	e.path = append(e.path, name)
+
+	value, err := String(raw, e.lookup)
+
+	e.path = e.path[:len(e.path)-1]
+	delete(e.visiting, name)
+
+	if err != nil {
+		return "", false, err
+	}
+
+	e.expanded[name] = value
+
+	return value, true, nil
+}
+
+// expandReference expands body of "${...}".
+func expandReference(body string, lookup LookupFunc) (string, error) {
+	name := body
+	operator, word := "", ""
+
+	if i := strings.Index(body, ":"); i >= 0 {
+		name, operator, word = body[:i], body[i:min(i+2, len(body))], body[min(i+2, len(body)):]
+	}
+
+	if !isName(name) {
+		return "", fmt.Errorf("invalid reference: ${%s}", body)
+	}
+
+	value, ok, err := lookup(name)
+	if err != nil {
+		return "", err
+	}
+
+	switch operator {
+	case "":
+		return value, nil
+
+	case ":-":
+		if ok && value != "" {
+			return value, nil
+		}
+
+		return String(word, lookup)
+
+	case ":?":
+		if ok && value != "" {
+			return value, nil
+		}
+
+		message, err := String(word, lookup)
+		if err != nil {
+			return "", err
+		}
+
+		if message == "" {
+			message = "parameter null or not set"
+		}
+
+		return "", fmt.Errorf("%s: %s", name, message)
+
+	default:
+		return "", fmt.Errorf("invalid reference: ${%s}", body)
+	}
+}
+
+// closingBrace returns index of "}" closing reference body starting at start,
+// taking nested references into account, or -1.
+func closingBrace(s string, start int) int {
+	depth := 0
+
+	for i := start; i < len(s); i++ {
+		switch {
+		case s[i] == '$' && i+1 < len(s) && s[i+1] == '$':
+			i++
+		case s[i] == '$' && i+1 < len(s) && s[i+1] == '{':
+			depth++
+			i++
+		case s[i] == '}':
+			if depth == 0 {
+				return i
+			}
+
+			depth--
+		}
+	}
+
+	return -1
+}
+
+func isName(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c == '_' || ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || (i > 0 && '0' <= c && c <= '9')) {
+			return false
+		}
+	}
+
+	return true
+}
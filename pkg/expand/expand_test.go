@@ -0,0 +1,149 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package expand
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestString(t *testing.T) {
+	lookup := LookupEnviron([]string{"TASK_ID=abc123", "NAME=web", "EMPTY="})
+
+	valid := []struct {
+		input    string
+		expected string
+	}{
+		{"", ""},
+		{"plain", "plain"},
+		{"${NAME}-${TASK_ID}", "web-abc123"},
+		{"--node-name=${TASK_ID}", "--node-name=abc123"},
+		{"${MISSING}", ""},
+		{"${MISSING:-default}", "default"},
+		{"${EMPTY:-default}", "default"},
+		{"${NAME:-default}", "web"},
+		{"${MISSING:-}", ""},
+		{"${MISSING:-${NAME}:${TASK_ID}}", "web:abc123"},
+		{"${MISSING:-{braces\\}}", "{braces\\}"},
+		{"${NAME:?required}", "web"},
+		{"$$", "$"},
+		{"$${NAME}", "${NAME}"},
+		{"$$$${NAME}", "$${NAME}"},
+		{"$NAME", "$NAME"},
+		{"price: 5$", "price: 5$"},
+		{"$ {NAME}", "$ {NAME}"},
+	}
+
+	for _, tc := range valid {
+		t.Run(tc.input, func(t *testing.T) {
+			value, err := String(tc.input, lookup)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, value)
+		})
+	}
+
+	invalid := []struct {
+		input string
+		err   string
+	}{
+		{"${MISSING:?must be set}", "MISSING: must be set"},
+		{"${EMPTY:?}", "EMPTY: parameter null or not set"},
+		{"${MISSING:?${NAME} needs it}", "MISSING: web needs it"},
+		{"${NAME", "unterminated reference: ${NAME"},
+		{"${}", "invalid reference: ${}"},
+		{"${server.port}", "invalid reference: ${server.port}"},
+		{"${NAME:+alt}", "invalid reference: ${NAME:+alt}"},
+		{"${NAME-default}", "invalid reference: ${NAME-default}"},
+	}
+
+	for _, tc := range invalid {
+		t.Run(tc.input, func(t *testing.T) {
+			_, err := String(tc.input, lookup)
+
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestEnviron(t *testing.T) {
+	t.Run("expands references regardless of order", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		env, err := Environ([]string{
+			"LOG_PREFIX=${ECS_CONTAINER_NAME}-${ECS_TASK_ID}",
+			"URL=http://${HOST:-localhost}:${PORT}",
+			"ECS_CONTAINER_NAME=web",
+			"ECS_TASK_ID=abc123",
+			"PORT=${DEFAULT_PORT}",
+			"DEFAULT_PORT=8080",
+			"LITERAL=$${HOME}",
+		})
+
+		require.NoError(err)
+		assert.Equal([]string{
+			"LOG_PREFIX=web-abc123",
+			"URL=http://localhost:8080",
+			"ECS_CONTAINER_NAME=web",
+			"ECS_TASK_ID=abc123",
+			"PORT=8080",
+			"DEFAULT_PORT=8080",
+			"LITERAL=${HOME}",
+		}, env)
+	})
+
+	t.Run("does not expand escaped references twice", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		env, err := Environ([]string{"A=$${B}", "B=x", "C=${A}"})
+
+		require.NoError(err)
+		assert.Equal([]string{"A=${B}", "B=x", "C=${B}"}, env)
+	})
+
+	t.Run("with reference cycle", func(t *testing.T) {
+		_, err := Environ([]string{"A=${B}", "B=${C:-x}", "C=${A}"})
+
+		assert.ErrorIs(t, err, ErrCycle)
+		assert.EqualError(t, err, "failed to expand A: reference cycle: A -> B -> C -> A")
+	})
+
+	t.Run("with self reference", func(t *testing.T) {
+		_, err := Environ([]string{"PATH=${PATH}:/app/bin"})
+
+		assert.EqualError(t, err, "failed to expand PATH: reference cycle: PATH -> PATH")
+	})
+
+	t.Run("with required variable", func(t *testing.T) {
+		_, err := Environ([]string{"URL=${DATABASE_URL:?is required}"})
+
+		assert.EqualError(t, err, "failed to expand URL: DATABASE_URL: is required")
+	})
+}
+
+func TestEnvironMatching(t *testing.T) {
+	t.Run("keeps values of other variables as is", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		env, err := EnvironMatching([]string{
+			"LOG_PREFIX=${NAME}-${PASSWORD}",
+			"NAME=${ECS_CONTAINER_NAME}",
+			"PASSWORD=pa$$w${rd",
+			"ECS_CONTAINER_NAME=web",
+		}, func(name string) bool { return name == "LOG_PREFIX" })
+
+		require.NoError(err)
+		assert.Equal([]string{
+			"LOG_PREFIX=${ECS_CONTAINER_NAME}-pa$$w${rd",
+			"NAME=${ECS_CONTAINER_NAME}",
+			"PASSWORD=pa$$w${rd",
+			"ECS_CONTAINER_NAME=web",
+		}, env)
+	})
+}
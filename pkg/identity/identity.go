@@ -0,0 +1,225 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+// Package identity resolves user and group specifications the way gosu and
+// su-exec do, and drops privileges of the current process. Users and groups
+// are looked up in /etc/passwd and /etc/group, if present, so it works in
+// scratch images and without cgo.
+package identity
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/ixti/ecs-task-helper/pkg/environ"
+)
+
+const (
+	passwdFile = "/etc/passwd"
+	groupFile  = "/etc/group"
+
+	// defaultHome is used for users without passwd entry.
+	defaultHome = "/"
+)
+
+// Identity is a set of credentials to run a process with.
+type Identity struct {
+	// Name is empty for numeric users without passwd entry.
+	Name   string
+	UID    int
+	GID    int
+	Groups []int
+	Home   string
+}
+
+// Resolve resolves "user[:group]" specification, where user and group are
+// names or numeric IDs. Without group, the primary group of the user and all
+// groups listing the user as a member are used. Numeric users without passwd
+// entry get group with the same ID.
+func Resolve(spec string) (*Identity, error) {
+	return resolve(spec, passwdFile, groupFile)
+}
+
+func resolve(spec string, passwdPath string, groupPath string) (*Identity, error) {
+	userSpec, groupSpec, hasGroup := strings.Cut(spec, ":")
+
+	if userSpec == "" || (hasGroup && groupSpec == "") {
+		return nil, fmt.Errorf("invalid user specification: %q", spec)
+	}
+
+	users, err := readEntries(passwdPath, 7)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := readEntries(groupPath, 4)
+	if err != nil {
+		return nil, err
+	}
+
+	id := &Identity{Home: defaultHome}
+
+	if entry := findEntry(users, userSpec); entry != nil {
+		id.Name, id.Home = entry[0], entry[5]
+		id.UID, _ = strconv.Atoi(entry[2])
+		id.GID, _ = strconv.Atoi(entry[3])
+	} else if uid, err := parseID(userSpec); err == nil {
+		id.UID, id.GID = uid, uid
+	} else {
+		return nil, fmt.Errorf("unknown user: %s", userSpec)
+	}
+
+	if hasGroup {
+		if entry := findEntry(groups, groupSpec); entry != nil {
+			id.GID, _ = strconv.Atoi(entry[2])
+		} else if gid, err := parseID(groupSpec); err == nil {
+			id.GID = gid
+		} else {
+			return nil, fmt.Errorf("unknown group: %s", groupSpec)
+		}
+
+		id.Groups = []int{id.GID}
+
+		return id, nil
+	}
+
+	id.Groups = []int{id.GID}
+
+	for _, entry := range groups {
+		gid, err := strconv.Atoi(entry[2])
+		if err != nil || slices.Contains(id.Groups, gid) {
+			continue
+		}
+
+		if id.Name != "" && slices.Contains(strings.Split(entry[3], ","), id.Name) {
+			id.Groups = append(id.Groups, gid)
+		}
+	}
+
+	return id, nil
+}
+
+// readEntries reads colon-separated entries with at least n fields.
+// Missing file has no entries.
+func readEntries(path string, n int) ([][]string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	defer f.Close()
+
+	var entries [][]string
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if fields := strings.Split(line, ":"); len(fields) >= n {
+			entries = append(entries, fields)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return entries, nil
+}
+
+// findEntry returns entry with the given name, or the given numeric ID if
+// there's no entry with such name.
+func findEntry(entries [][]string, spec string) []string {
+	for _, entry := range entries {
+		if entry[0] == spec {
+			return entry
+		}
+	}
+
+	for _, entry := range entries {
+		if entry[2] == spec {
+			return entry
+		}
+	}
+
+	return nil
+}
+
+func parseID(s string) (int, error) {
+	id, err := strconv.ParseUint(s, 10, 31)
+	return int(id), err
+}
+
+// Credential returns credential for starting processes with the identity.
+func (id *Identity) Credential() *syscall.Credential {
+	groups := make([]uint32, len(id.Groups))
+	for i, gid := range id.Groups {
+		groups[i] = uint32(gid)
+	}
+
+	return &syscall.Credential{Uid: uint32(id.UID), Gid: uint32(id.GID), Groups: groups}
+}
+
+// EnvironWith returns env with HOME and USER of the identity. USER is only
+// set for users with passwd entry.
+func (id *Identity) EnvironWith(env []string) []string {
+	env = environ.Set(env, "HOME", id.Home)
+
+	if id.Name != "" {
+		env = environ.Set(env, "USER", id.Name)
+	}
+
+	return env
+}
+
+// Drop sets supplementary groups, group and user of the current process,
+// in this order, and verifies that the drop took effect and can't be undone.
+func (id *Identity) Drop() error {
+	// Unprivileged process can only keep its own identity.
+	if syscall.Geteuid() != 0 {
+		return id.verify()
+	}
+
+	if err := syscall.Setgroups(id.Groups); err != nil {
+		return fmt.Errorf("failed to set supplementary groups: %w", err)
+	}
+
+	if err := syscall.Setgid(id.GID); err != nil {
+		return fmt.Errorf("failed to set group: %w", err)
+	}
+
+	if err := syscall.Setuid(id.UID); err != nil {
+		return fmt.Errorf("failed to set user: %w", err)
+	}
+
+	return id.verify()
+}
+
+func (id *Identity) verify() error {
+	if uid, euid := syscall.Getuid(), syscall.Geteuid(); uid != id.UID || euid != id.UID {
+		return fmt.Errorf("privileges were not dropped: uid=%d euid=%d", uid, euid)
+	}
+
+	if gid, egid := syscall.Getgid(), syscall.Getegid(); gid != id.GID || egid != id.GID {
+		return fmt.Errorf("privileges were not dropped: gid=%d egid=%d", gid, egid)
+	}
+
+	if id.UID != 0 && syscall.Setuid(0) == nil {
+		return errors.New("privileges were not dropped: root can be regained")
+	}
+
+	return nil
+}
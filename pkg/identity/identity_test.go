@@ -0,0 +1,166 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package identity
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testPasswd = `root:x:0:0:root:/root:/bin/sh
+# comment
+app:x:1000:1000:App:/home/app:/sbin/nologin
+nobody:x:65534:65534:nobody:/nonexistent:/sbin/nologin
+invalid line
+`
+
+	testGroup = `root:x:0:
+app:x:1000:
+www-data:x:33:app,nobody
+docker:x:999:app
+nogroup:x:65534:
+`
+)
+
+func resolveWithFiles(t *testing.T, spec string) (*Identity, error) {
+	dir := t.TempDir()
+
+	passwd := filepath.Join(dir, "passwd")
+	require.NoError(t, os.WriteFile(passwd, []byte(testPasswd), 0o644))
+
+	group := filepath.Join(dir, "group")
+	require.NoError(t, os.WriteFile(group, []byte(testGroup), 0o644))
+
+	return resolve(spec, passwd, group)
+}
+
+func TestResolve(t *testing.T) {
+	valid := []struct {
+		spec     string
+		expected *Identity
+	}{
+		{"app", &Identity{Name: "app", UID: 1000, GID: 1000, Groups: []int{1000, 33, 999}, Home: "/home/app"}},
+		{"1000", &Identity{Name: "app", UID: 1000, GID: 1000, Groups: []int{1000, 33, 999}, Home: "/home/app"}},
+		{"app:www-data", &Identity{Name: "app", UID: 1000, GID: 33, Groups: []int{33}, Home: "/home/app"}},
+		{"app:33", &Identity{Name: "app", UID: 1000, GID: 33, Groups: []int{33}, Home: "/home/app"}},
+		{"nobody:4242", &Identity{Name: "nobody", UID: 65534, GID: 4242, Groups: []int{4242}, Home: "/nonexistent"}},
+		{"root", &Identity{Name: "root", UID: 0, GID: 0, Groups: []int{0}, Home: "/root"}},
+		{"4242", &Identity{UID: 4242, GID: 4242, Groups: []int{4242}, Home: "/"}},
+		{"4242:1000", &Identity{UID: 4242, GID: 1000, Groups: []int{1000}, Home: "/"}},
+	}
+
+	for _, tc := range valid {
+		t.Run(tc.spec, func(t *testing.T) {
+			id, err := resolveWithFiles(t, tc.spec)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, id)
+		})
+	}
+
+	invalid := map[string]string{
+		"":            `invalid user specification: ""`,
+		"app:":        `invalid user specification: "app:"`,
+		":app":        `invalid user specification: ":app"`,
+		"unknown":     "unknown user: unknown",
+		"-1":          "unknown user: -1",
+		"app:unknown": "unknown group: unknown",
+	}
+
+	for spec, message := range invalid {
+		t.Run(spec, func(t *testing.T) {
+			_, err := resolveWithFiles(t, spec)
+
+			assert.EqualError(t, err, message)
+		})
+	}
+
+	t.Run("without passwd and group files", func(t *testing.T) {
+		dir := t.TempDir()
+
+		id, err := resolve("1000:1000", filepath.Join(dir, "passwd"), filepath.Join(dir, "group"))
+
+		require.NoError(t, err)
+		assert.Equal(t, &Identity{UID: 1000, GID: 1000, Groups: []int{1000}, Home: "/"}, id)
+	})
+}
+
+func TestIdentity_Credential(t *testing.T) {
+	id := &Identity{UID: 1000, GID: 33, Groups: []int{33, 999}}
+
+	assert.Equal(t, &syscall.Credential{Uid: 1000, Gid: 33, Groups: []uint32{33, 999}}, id.Credential())
+}
+
+func TestIdentity_EnvironWith(t *testing.T) {
+	t.Run("with named user", func(t *testing.T) {
+		id := &Identity{Name: "app", Home: "/home/app"}
+
+		env := id.EnvironWith([]string{"PATH=/usr/bin", "HOME=/root", "USER=root"})
+
+		assert.Equal(t, []string{"PATH=/usr/bin", "HOME=/home/app", "USER=app"}, env)
+	})
+
+	t.Run("with numeric user", func(t *testing.T) {
+		id := &Identity{UID: 4242, Home: "/"}
+
+		env := id.EnvironWith([]string{"HOME=/root", "USER=root"})
+
+		assert.Equal(t, []string{"USER=root", "HOME=/"}, env)
+	})
+}
+
+// TestIdentity_Drop drops privileges of a test binary subprocess, as the drop
+// can't be undone.
+func TestIdentity_Drop(t *testing.T) {
+	if os.Getenv("IDENTITY_DROP_HELPER") == "1" {
+		id := &Identity{UID: 65534, GID: 65534, Groups: []int{65534}}
+
+		if err := id.Drop(); err != nil {
+			os.Stderr.WriteString(err.Error())
+			os.Exit(1)
+		}
+
+		os.Exit(0)
+	}
+
+	t.Run("drops privileges", func(t *testing.T) {
+		if os.Geteuid() != 0 {
+			t.Skip("requires root")
+		}
+
+		cmd := exec.Command(os.Args[0], "-test.run=^TestIdentity_Drop$")
+		cmd.Env = append(os.Environ(), "IDENTITY_DROP_HELPER=1")
+
+		out, err := cmd.CombinedOutput()
+
+		assert.NoError(t, err, string(out))
+	})
+
+	t.Run("without privileges keeps own identity", func(t *testing.T) {
+		if os.Geteuid() == 0 {
+			t.Skip("requires unprivileged user")
+		}
+
+		id := &Identity{UID: os.Getuid(), GID: os.Getgid()}
+
+		assert.NoError(t, id.Drop())
+	})
+
+	t.Run("without privileges fails to change identity", func(t *testing.T) {
+		if os.Geteuid() == 0 {
+			t.Skip("requires unprivileged user")
+		}
+
+		id := &Identity{UID: os.Getuid() + 1, GID: os.Getgid()}
+
+		assert.ErrorContains(t, id.Drop(), "privileges were not dropped")
+	})
+}
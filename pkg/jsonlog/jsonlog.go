@@ -0,0 +1,106 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+// Package jsonlog wraps lines of process output into JSON objects, so that
+// log consumers can filter them by fields without changes to the process:
+//
+//	listening on :8080
+//	{"level":"warn","msg":"slow request"}
+//
+// become
+//
+//	{"timestamp":"2026-01-02T03:04:05.678Z","stream":"stdout","message":"listening on :8080","ecs_task_id":"..."}
+//	{"level":"warn","msg":"slow request","timestamp":"2026-01-02T03:04:05.679Z","stream":"stdout","ecs_task_id":"..."}
+package jsonlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/ixti/ecs-task-helper/pkg/linewriter"
+)
+
+// Field is a string field added to every line.
+type Field struct {
+	Key   string
+	Value string
+}
+
+// NewWriter returns Writer copying lines to w formatted with Format.
+func NewWriter(w io.Writer, stream string, fields []Field) *linewriter.Writer {
+	return linewriter.New(func(line []byte) {
+		w.Write(Format(line, stream, time.Now(), fields))
+	})
+}
+
+// Format returns line wrapped into JSON object with timestamp, stream,
+// message and fields, followed by newline. Lines that are JSON objects
+// already get these fields merged in, unless they have fields with the same
+// keys. Their own fields are kept as is.
+func Format(line []byte, stream string, now time.Time, fields []Field) []byte {
+	timestamp := Field{"timestamp", now.UTC().Format(time.RFC3339Nano)}
+
+	if object, keys, ok := parseObject(line); ok {
+		out := bytes.TrimRight(object[:len(object)-1], " \t\r\n")
+		out = appendFields(out, append([]Field{timestamp, {"stream", stream}}, fields...), keys)
+
+		return append(out, '}', '\n')
+	}
+
+	out := appendFields([]byte{'{'}, append([]Field{timestamp, {"stream", stream}, {"message", string(line)}}, fields...), nil)
+
+	return append(out, '}', '\n')
+}
+
+// appendFields appends fields to the JSON object being built in out, except
+// those with keys already present.
+func appendFields(out []byte, fields []Field, present map[string]json.RawMessage) []byte {
+	needsComma := out[len(out)-1] != '{'
+
+	for _, field := range fields {
+		if _, ok := present[field.Key]; ok {
+			continue
+		}
+
+		if needsComma {
+			out = append(out, ',')
+		}
+
+		out = appendString(out, field.Key)
+		out = append(out, ':')
+		out = appendString(out, field.Value)
+		needsComma = true
+	}
+
+	return out
+}
+
+// parseObject returns copy of line without surrounding whitespace, and its
+// keys, if the line is a JSON object.
+func parseObject(line []byte) ([]byte, map[string]json.RawMessage, bool) {
+	trimmed := bytes.TrimSpace(line)
+	if len(trimmed) < 2 || trimmed[0] != '{' {
+		return nil, nil, false
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &object); err != nil {
+		return nil, nil, false
+	}
+
+	return bytes.Clone(trimmed), object, true
+}
+
+// appendString appends s as JSON string. Unlike json.Marshal, it does not
+// escape HTML characters.
+func appendString(out []byte, s string) []byte {
+	buf := bytes.NewBuffer(out)
+
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte{'\n'})
+}
@@ -0,0 +1,116 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package jsonlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 678000000, time.FixedZone("JST", 9*60*60))
+	fields := []Field{{"ecs_task_id", "abc"}, {"ecs_container_name", "web"}}
+
+	tests := []struct {
+		name     string
+		line     string
+		expected string
+	}{
+		{
+			"plain line",
+			"listening on :8080",
+			`{"timestamp":"2026-01-01T18:04:05.678Z","stream":"stdout","message":"listening on :8080","ecs_task_id":"abc","ecs_container_name":"web"}`,
+		},
+		{
+			"special characters",
+			"<a href=\"x\">\t\x01</a>",
+			`{"timestamp":"2026-01-01T18:04:05.678Z","stream":"stdout","message":"<a href=\"x\">\t\u0001</a>","ecs_task_id":"abc","ecs_container_name":"web"}`,
+		},
+		{
+			"empty line",
+			"",
+			`{"timestamp":"2026-01-01T18:04:05.678Z","stream":"stdout","message":"","ecs_task_id":"abc","ecs_container_name":"web"}`,
+		},
+		{
+			"JSON object",
+			`{"level":"warn","msg":"slow request"}`,
+			`{"level":"warn","msg":"slow request","timestamp":"2026-01-01T18:04:05.678Z","stream":"stdout","ecs_task_id":"abc","ecs_container_name":"web"}`,
+		},
+		{
+			"JSON object with own fields",
+			` { "timestamp": 1767236645, "ecs_task_id": null } `,
+			`{ "timestamp": 1767236645, "ecs_task_id": null,"stream":"stdout","ecs_container_name":"web"}`,
+		},
+		{
+			"empty JSON object",
+			"{}",
+			`{"timestamp":"2026-01-01T18:04:05.678Z","stream":"stdout","ecs_task_id":"abc","ecs_container_name":"web"}`,
+		},
+		{
+			"JSON array",
+			`[1, 2]`,
+			`{"timestamp":"2026-01-01T18:04:05.678Z","stream":"stdout","message":"[1, 2]","ecs_task_id":"abc","ecs_container_name":"web"}`,
+		},
+		{
+			"malformed JSON object",
+			`{"level":"warn"`,
+			`{"timestamp":"2026-01-01T18:04:05.678Z","stream":"stdout","message":"{\"level\":\"warn\"","ecs_task_id":"abc","ecs_container_name":"web"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			out := Format([]byte(tc.line), "stdout", now, fields)
+
+			assert.Equal(t, tc.expected+"\n", string(out))
+			assert.True(t, json.Valid(out), "output is not valid JSON")
+		})
+	}
+
+	t.Run("does not modify buffer of JSON object line", func(t *testing.T) {
+		buf := []byte(`{"a":1}` + "remainder")
+
+		Format(buf[:7], "stdout", now, fields)
+
+		assert.Equal(t, `{"a":1}remainder`, string(buf))
+	})
+}
+
+func TestNewWriter(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	var out bytes.Buffer
+
+	w := NewWriter(&out, "stderr", []Field{{"ecs_task_id", "abc"}})
+	w.Write([]byte("first\n{\"msg\":\"second\"}\nthird"))
+	w.Flush()
+
+	lines := bytes.Split(bytes.TrimSuffix(out.Bytes(), []byte{'\n'}), []byte{'\n'})
+	require.Len(lines, 3)
+
+	var entries []map[string]any
+
+	for _, line := range lines {
+		var entry map[string]any
+
+		require.NoError(json.Unmarshal(line, &entry))
+		entries = append(entries, entry)
+	}
+
+	assert.Equal("first", entries[0]["message"])
+	assert.Equal("second", entries[1]["msg"])
+	assert.Equal("third", entries[2]["message"])
+
+	for _, entry := range entries {
+		assert.Equal("stderr", entry["stream"])
+		assert.Equal("abc", entry["ecs_task_id"])
+		assert.NotEmpty(entry["timestamp"])
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+// Package linewriter provides an io.Writer that processes output of child
+// processes line by line.
+package linewriter
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// MaxLineSize is the default size after which a line without newline is
+// handled as if it was complete.
+const MaxLineSize = 64 * 1024
+
+// Writer splits written data into lines and passes each of them, without the
+// trailing newline, to the handler. Incomplete last line is buffered until
+// more data is written or Flush is called. Writer is safe for concurrent use.
+type Writer struct {
+	mu      sync.Mutex
+	buf     []byte
+	maxSize int
+	handle  func(line []byte)
+}
+
+func New(handle func(line []byte)) *Writer {
+	return NewWithMaxSize(handle, MaxLineSize)
+}
+
+// NewWithMaxSize returns Writer splitting lines longer than maxSize, instead
+// of MaxLineSize, e.g. to leave it to the handler.
+func NewWithMaxSize(handle func(line []byte), maxSize int) *Writer {
+	return &Writer{handle: handle, maxSize: maxSize}
+}
+
+// NewPrefixed returns Writer copying lines to w with prefix prepended.
+func NewPrefixed(w io.Writer, prefix string) *Writer {
+	return New(func(line []byte) {
+		out := make([]byte, 0, len(prefix)+len(line)+1)
+		out = append(out, prefix...)
+		out = append(out, line...)
+		out = append(out, '\n')
+
+		w.Write(out)
+	})
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+
+		if i < 0 && len(w.buf) < w.maxSize {
+			break
+		}
+
+		if i < 0 || i > w.maxSize {
+			w.handle(w.buf[:w.maxSize])
+			w.buf = w.buf[w.maxSize:]
+
+			continue
+		}
+
+		w.handle(bytes.TrimSuffix(w.buf[:i], []byte{'\r'}))
+		w.buf = w.buf[i+1:]
+	}
+
+	// Don't let the buffer grow unbounded on long-running streams.
+	if len(w.buf) == 0 {
+		w.buf = nil
+	}
+
+	return len(p), nil
+}
+
+// Flush handles buffered incomplete line, if any.
+func (w *Writer) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) > 0 {
+		w.handle(w.buf)
+		w.buf = nil
+	}
+}
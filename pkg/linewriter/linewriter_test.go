@@ -0,0 +1,85 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package linewriter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func collect() (*Writer, *[]string) {
+	var lines []string
+
+	return New(func(line []byte) { lines = append(lines, string(line)) }), &lines
+}
+
+func TestWriter(t *testing.T) {
+	t.Run("splits lines", func(t *testing.T) {
+		assert := assert.New(t)
+
+		w, lines := collect()
+
+		n, err := w.Write([]byte("one\ntwo\r\n\nthree"))
+
+		assert.NoError(err)
+		assert.Equal(15, n)
+		assert.Equal([]string{"one", "two", ""}, *lines)
+
+		w.Write([]byte(" and a half\n"))
+		assert.Equal([]string{"one", "two", "", "three and a half"}, *lines)
+	})
+
+	t.Run("flushes incomplete line", func(t *testing.T) {
+		assert := assert.New(t)
+
+		w, lines := collect()
+
+		w.Write([]byte("partial"))
+		assert.Empty(*lines)
+
+		w.Flush()
+		assert.Equal([]string{"partial"}, *lines)
+
+		w.Flush()
+		assert.Len(*lines, 1)
+	})
+
+	t.Run("splits too long lines", func(t *testing.T) {
+		assert := assert.New(t)
+
+		w, lines := collect()
+
+		w.Write([]byte(strings.Repeat("x", MaxLineSize+10) + "\n"))
+
+		assert.Len(*lines, 2)
+		assert.Len((*lines)[0], MaxLineSize)
+		assert.Len((*lines)[1], 10)
+	})
+}
+
+func TestNewWithMaxSize(t *testing.T) {
+	assert := assert.New(t)
+
+	var lines []string
+
+	w := NewWithMaxSize(func(line []byte) { lines = append(lines, string(line)) }, MaxLineSize*2)
+	w.Write([]byte(strings.Repeat("x", MaxLineSize+10) + "\n" + strings.Repeat("y", MaxLineSize*2+1)))
+
+	assert.Len(lines, 2)
+	assert.Len(lines[0], MaxLineSize+10)
+	assert.Len(lines[1], MaxLineSize*2)
+}
+
+func TestNewPrefixed(t *testing.T) {
+	var out bytes.Buffer
+
+	w := NewPrefixed(&out, "[pre] ")
+	w.Write([]byte("hello\nworld"))
+	w.Flush()
+
+	assert.Equal(t, "[pre] hello\n[pre] world\n", out.String())
+}
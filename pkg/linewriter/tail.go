@@ -0,0 +1,51 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package linewriter
+
+import (
+	"slices"
+	"sync"
+)
+
+// Tail is Writer keeping the last lines written to it.
+type Tail struct {
+	*Writer
+
+	mu    sync.Mutex
+	lines []string
+	size  int
+}
+
+// NewTail returns Tail keeping up to size lines.
+func NewTail(size int) *Tail {
+	t := &Tail{size: size}
+	t.Writer = New(t.add)
+
+	return t
+}
+
+func (t *Tail) add(line []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.size <= 0 {
+		return
+	}
+
+	t.lines = append(t.lines, string(line))
+
+	if len(t.lines) > t.size {
+		t.lines = t.lines[len(t.lines)-t.size:]
+	}
+}
+
+// Lines flushes incomplete last line, and returns the kept lines.
+func (t *Tail) Lines() []string {
+	t.Flush()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return slices.Clone(t.lines)
+}
@@ -0,0 +1,42 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package linewriter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTail(t *testing.T) {
+	t.Run("keeps last lines", func(t *testing.T) {
+		assert := assert.New(t)
+
+		tail := NewTail(2)
+
+		tail.Write([]byte("one\ntwo\nthree\nfour"))
+
+		assert.Equal([]string{"three", "four"}, tail.Lines())
+	})
+
+	t.Run("with fewer lines", func(t *testing.T) {
+		assert := assert.New(t)
+
+		tail := NewTail(5)
+
+		tail.Write([]byte("one\n"))
+
+		assert.Equal([]string{"one"}, tail.Lines())
+	})
+
+	t.Run("with zero size", func(t *testing.T) {
+		assert := assert.New(t)
+
+		tail := NewTail(0)
+
+		tail.Write([]byte("one\n"))
+
+		assert.Empty(tail.Lines())
+	})
+}
@@ -0,0 +1,187 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+// Package multiline groups lines of process output into records, e.g. a log
+// message followed by a stack trace, so that log drivers splitting output by
+// lines don't split the record into separate events.
+package multiline
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxEventSize is the maximum size of a CloudWatch Logs event: 256 KiB minus
+// 26 bytes of per event overhead.
+const MaxEventSize = 256*1024 - 26
+
+// presets hold patterns of continuation lines of stack traces.
+var presets = map[string]*regexp.Regexp{
+	// Exception line following the log message, at com.example.Foo.bar(Foo.java:10),
+	// ... 5 more, Caused by: and Suppressed: lines.
+	"java": regexp.MustCompile(`^([\w$.]+(Exception|Error|Throwable)(: |$)|\s+at |\s+\.\.\. \d+ (more|common frames omitted)|\s*Caused by: |\s+Suppressed: )`),
+	// Indented frames and source lines, and the exception line ending the
+	// traceback, e.g. ValueError: invalid literal.
+	"python": regexp.MustCompile(`^(\s|[\w.]+(Error|Exception|Exit|Interrupt|Warning)(: |$))`),
+	// Blank lines, goroutine headers, function calls, indented file:line and
+	// created by lines following panic: or fatal error:.
+	"go": regexp.MustCompile(`^(\s|$|goroutine \d+ \[|created by |\[signal |[\w./*()\[\]{}-]+\(.*\)$)`),
+	// from app.rb:7:in `<main>'
+	"ruby": regexp.MustCompile(`^\s+from `),
+}
+
+// Presets returns names of the presets.
+func Presets() []string {
+	return slices.Sorted(maps.Keys(presets))
+}
+
+// Rule decides which lines start a new record.
+type Rule struct {
+	// Start matches lines starting a new record. Nil matches any line.
+	Start *regexp.Regexp
+	// Continue matches lines continuing the current record, even if they
+	// match Start. Nil matches none.
+	Continue *regexp.Regexp
+}
+
+// NewRule returns rule with start pattern, which may be empty, and
+// continuation patterns of the presets.
+func NewRule(start string, names []string) (*Rule, error) {
+	rule := &Rule{}
+
+	if start != "" {
+		re, err := regexp.Compile(start)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start pattern: %w", err)
+		}
+
+		rule.Start = re
+	}
+
+	patterns := make([]string, 0, len(names))
+
+	for _, name := range names {
+		preset, ok := presets[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown preset: %s", name)
+		}
+
+		if pattern := preset.String(); !slices.Contains(patterns, pattern) {
+			patterns = append(patterns, pattern)
+		}
+	}
+
+	if len(patterns) > 0 {
+		rule.Continue = regexp.MustCompile("(" + strings.Join(patterns, ")|(") + ")")
+	}
+
+	return rule, nil
+}
+
+// IsStart reports whether line starts a new record.
+func (r *Rule) IsStart(line []byte) bool {
+	if r.Continue != nil && r.Continue.Match(line) {
+		return false
+	}
+
+	return r.Start == nil || r.Start.Match(line)
+}
+
+// Coalescer groups lines into records, and passes each of them, with lines
+// separated by newline, to the handler. Record is complete when a line
+// starting the next one is received, when no lines are received for
+// Timeout, or when adding the next line would exceed MaxSize. Lines longer
+// than MaxSize are split. Coalescer is safe for concurrent use.
+type Coalescer struct {
+	rule    *Rule
+	timeout time.Duration
+	maxSize int
+	handle  func(record []byte)
+
+	mu      sync.Mutex
+	buf     []byte
+	pending bool
+	timer   *time.Timer
+	// generation of the timer, so that stale timers don't flush the record.
+	generation uint64
+}
+
+// New returns Coalescer. Zero timeout disables flushing on timeout, and zero
+// maxSize defaults to MaxEventSize.
+func New(rule *Rule, timeout time.Duration, maxSize int, handle func(record []byte)) *Coalescer {
+	if maxSize <= 0 {
+		maxSize = MaxEventSize
+	}
+
+	return &Coalescer{rule: rule, timeout: timeout, maxSize: maxSize, handle: handle}
+}
+
+// Line adds line, without the trailing newline, to the pending record.
+func (c *Coalescer) Line(line []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.pending && (c.rule.IsStart(line) || len(c.buf)+1+len(line) > c.maxSize) {
+		c.flush()
+	}
+
+	for len(line) > c.maxSize {
+		c.handle(line[:c.maxSize])
+		line = line[c.maxSize:]
+	}
+
+	if c.pending {
+		c.buf = append(c.buf, '\n')
+	}
+
+	c.buf, c.pending = append(c.buf, line...), true
+
+	if c.timeout > 0 {
+		if c.timer != nil {
+			c.timer.Stop()
+		}
+
+		c.generation++
+
+		generation := c.generation
+		c.timer = time.AfterFunc(c.timeout, func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+
+			if c.generation == generation {
+				c.flush()
+			}
+		})
+	}
+}
+
+// Flush handles the pending record, if any.
+func (c *Coalescer) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.flush()
+}
+
+func (c *Coalescer) flush() {
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+
+	c.generation++
+
+	if !c.pending {
+		return
+	}
+
+	record := c.buf
+	c.buf, c.pending = nil, false
+
+	c.handle(record)
+}
@@ -0,0 +1,190 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package multiline
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collect feeds lines of input to coalescer with rule, and returns records.
+func collect(t *testing.T, rule *Rule, maxSize int, input string) []string {
+	var records []string
+
+	c := New(rule, 0, maxSize, func(record []byte) {
+		records = append(records, string(record))
+	})
+
+	for line := range strings.SplitSeq(input, "\n") {
+		c.Line([]byte(line))
+	}
+
+	c.Flush()
+
+	return records
+}
+
+func mustRule(t *testing.T, start string, names ...string) *Rule {
+	rule, err := NewRule(start, names)
+	require.NoError(t, err)
+
+	return rule
+}
+
+func TestPresets(t *testing.T) {
+	assert.Equal(t, []string{"go", "java", "python", "ruby"}, Presets())
+}
+
+func TestNewRule(t *testing.T) {
+	t.Run("with invalid start pattern", func(t *testing.T) {
+		_, err := NewRule("(", nil)
+
+		assert.ErrorContains(t, err, "invalid start pattern: error parsing regexp")
+	})
+
+	t.Run("with unknown preset", func(t *testing.T) {
+		_, err := NewRule("", []string{"java", "cobol"})
+
+		assert.EqualError(t, err, "unknown preset: cobol")
+	})
+
+	t.Run("combines presets", func(t *testing.T) {
+		assert := assert.New(t)
+
+		rule := mustRule(t, "", "java", "ruby", "java")
+
+		assert.False(rule.IsStart([]byte("\tat com.example.Foo.bar(Foo.java:10)")))
+		assert.False(rule.IsStart([]byte("\tfrom app.rb:7:in `<main>'")))
+		assert.True(rule.IsStart([]byte("INFO started")))
+	})
+}
+
+func TestCoalescer(t *testing.T) {
+	t.Run("with start pattern", func(t *testing.T) {
+		records := collect(t, mustRule(t, `^\d{4}-\d{2}-\d{2} `), 0, strings.Join([]string{
+			"2026-01-02 ERROR failed",
+			"details",
+			"  more details",
+			"2026-01-02 INFO next",
+		}, "\n"))
+
+		assert.Equal(t, []string{"2026-01-02 ERROR failed\ndetails\n  more details", "2026-01-02 INFO next"}, records)
+	})
+
+	t.Run("with leading continuation lines", func(t *testing.T) {
+		records := collect(t, mustRule(t, `^START`), 0, "orphan\nSTART\nmore")
+
+		assert.Equal(t, []string{"orphan", "START\nmore"}, records)
+	})
+
+	t.Run("keeps empty lines", func(t *testing.T) {
+		records := collect(t, mustRule(t, `^START`), 0, "START\n\nmore\n")
+
+		assert.Equal(t, []string{"START\n\nmore\n"}, records)
+	})
+
+	presets := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			"java",
+			"ERROR Request failed\njava.lang.IllegalStateException: boom\n\tat com.example.Foo.bar(Foo.java:10)\n\tat com.example.Main.main(Main.java:5)\nCaused by: java.io.IOException: closed\n\t... 2 more\n\tSuppressed: java.lang.Exception\nINFO next",
+			[]string{
+				"ERROR Request failed\njava.lang.IllegalStateException: boom\n\tat com.example.Foo.bar(Foo.java:10)\n\tat com.example.Main.main(Main.java:5)\nCaused by: java.io.IOException: closed\n\t... 2 more\n\tSuppressed: java.lang.Exception",
+				"INFO next",
+			},
+		},
+		{
+			"python",
+			"Traceback (most recent call last):\n  File \"app.py\", line 3, in <module>\n    int(\"x\")\nValueError: invalid literal for int() with base 10: 'x'\nINFO next",
+			[]string{
+				"Traceback (most recent call last):\n  File \"app.py\", line 3, in <module>\n    int(\"x\")\nValueError: invalid literal for int() with base 10: 'x'",
+				"INFO next",
+			},
+		},
+		{
+			"go",
+			"panic: runtime error: index out of range [1] with length 0\n\ngoroutine 1 [running]:\nmain.main()\n\t/app/main.go:5 +0x1d\ncreated by main.start in goroutine 1\n\t/app/main.go:9 +0x25\nINFO next",
+			[]string{
+				"panic: runtime error: index out of range [1] with length 0\n\ngoroutine 1 [running]:\nmain.main()\n\t/app/main.go:5 +0x1d\ncreated by main.start in goroutine 1\n\t/app/main.go:9 +0x25",
+				"INFO next",
+			},
+		},
+		{
+			"ruby",
+			"app.rb:3:in `foo': undefined method `bar' for nil (NoMethodError)\n\tfrom app.rb:7:in `<main>'\nINFO next",
+			[]string{
+				"app.rb:3:in `foo': undefined method `bar' for nil (NoMethodError)\n\tfrom app.rb:7:in `<main>'",
+				"INFO next",
+			},
+		},
+	}
+
+	for _, tc := range presets {
+		t.Run("with "+tc.name+" preset", func(t *testing.T) {
+			records := collect(t, mustRule(t, "", tc.name), 0, tc.input)
+
+			assert.Equal(t, tc.expected, records)
+		})
+	}
+
+	t.Run("with start pattern and preset", func(t *testing.T) {
+		records := collect(t, mustRule(t, `^\[`, "java"), 0, "[ERROR] failed\ndetails\n\tat Foo.bar(Foo.java:1)\n[INFO] next")
+
+		assert.Equal(t, []string{"[ERROR] failed\ndetails\n\tat Foo.bar(Foo.java:1)", "[INFO] next"}, records)
+	})
+
+	t.Run("with record exceeding max size", func(t *testing.T) {
+		records := collect(t, mustRule(t, `^START`), 10, "START\n1234\n56789\n0")
+
+		assert.Equal(t, []string{"START\n1234", "56789\n0"}, records)
+	})
+
+	t.Run("with line exceeding max size", func(t *testing.T) {
+		records := collect(t, mustRule(t, `^START`), 4, "START\n1234567890")
+
+		assert.Equal(t, []string{"STAR", "T", "1234", "5678", "90"}, records)
+	})
+
+	t.Run("flushes record on timeout", func(t *testing.T) {
+		assert := assert.New(t)
+
+		var (
+			mu      sync.Mutex
+			records []string
+		)
+
+		c := New(mustRule(t, `^START`), 50*time.Millisecond, 0, func(record []byte) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			records = append(records, string(record))
+		})
+
+		c.Line([]byte("START"))
+		c.Line([]byte("more"))
+
+		assert.Eventually(func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+
+			return len(records) == 1
+		}, time.Second, 10*time.Millisecond)
+
+		c.Line([]byte("late"))
+		c.Flush()
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		assert.Equal([]string{"START\nmore", "late"}, records)
+	})
+}
@@ -0,0 +1,151 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+// Package otel derives OpenTelemetry resource attributes from ECS metadata.
+package otel
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ixti/ecs-task-helper/pkg/container_metadata"
+	"github.com/ixti/ecs-task-helper/pkg/environ"
+)
+
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// ResourceAttributes returns AWS ECS semantic convention resource attributes.
+// Attributes with empty values are omitted. Returns nil if metadata has no
+// TaskARN, i.e. was not fetched from the ECS metadata endpoint.
+//
+// See: https://opentelemetry.io/docs/specs/semconv/resource/cloud-provider/aws/ecs/
+func ResourceAttributes(m *container_metadata.Metadata) []Attribute {
+	if m.TaskARN == "" {
+		return nil
+	}
+
+	attrs := []Attribute{
+		{"cloud.provider", "aws"},
+		{"cloud.platform", "aws_ecs"},
+		{"cloud.region", m.Region()},
+		{"cloud.account.id", m.AccountID()},
+		{"cloud.availability_zone", m.AvailabilityZone},
+		{"cloud.resource_id", m.ContainerARN},
+		{"aws.ecs.container.arn", m.ContainerARN},
+		{"aws.ecs.cluster.arn", m.ClusterARN()},
+		{"aws.ecs.launchtype", strings.ToLower(m.LaunchType)},
+		{"aws.ecs.task.arn", m.TaskARN},
+		{"aws.ecs.task.id", m.TaskID()},
+		{"aws.ecs.task.family", m.TaskDefinitionFamily},
+		{"aws.ecs.task.revision", m.TaskDefinitionVersion},
+		{"container.id", m.ContainerID},
+		{"container.name", m.ContainerName},
+		{"container.image.name", m.ContainerImageName()},
+		{"container.image.tag", m.ContainerImageTag()},
+	}
+
+	attrs = append(attrs, logAttributes(m)...)
+
+	result := make([]Attribute, 0, len(attrs))
+	for _, attr := range attrs {
+		if attr.Value != "" {
+			result = append(result, attr)
+		}
+	}
+
+	return result
+}
+
+// EnvironWith returns base with OTEL_RESOURCE_ATTRIBUTES and OTEL_SERVICE_NAME
+// derived from the metadata merged in. Keys already present in base take
+// precedence over derived ones.
+func EnvironWith(base []string, m *container_metadata.Metadata) []string {
+	attrs := ResourceAttributes(m)
+	if len(attrs) == 0 {
+		return base
+	}
+
+	existing, _ := environ.Lookup(base, "OTEL_RESOURCE_ATTRIBUTES")
+	keys := parseKeys(existing)
+
+	pairs := make([]string, 0, len(attrs)+1)
+	if strings.TrimSpace(existing) != "" {
+		pairs = append(pairs, existing)
+	}
+
+	for _, attr := range attrs {
+		if _, exists := keys[attr.Key]; !exists {
+			pairs = append(pairs, attr.Key+"="+escape(attr.Value))
+		}
+	}
+
+	env := environ.Set(base, "OTEL_RESOURCE_ATTRIBUTES", strings.Join(pairs, ","))
+
+	serviceName, _ := environ.Lookup(base, "OTEL_SERVICE_NAME")
+	if _, exists := keys["service.name"]; !exists && serviceName == "" && m.ContainerName != "" {
+		env = environ.Set(env, "OTEL_SERVICE_NAME", m.ContainerName)
+	}
+
+	return env
+}
+
+func logAttributes(m *container_metadata.Metadata) []Attribute {
+	group := m.LogGroup()
+	if group == "" {
+		return nil
+	}
+
+	arn, _ := container_metadata.ParseARN(m.TaskARN)
+	arn.Service = "logs"
+	arn.Region = m.LogRegion()
+	arn.Resource = "log-group:" + group
+
+	attrs := []Attribute{
+		{"aws.log.group.names", group},
+		{"aws.log.group.arns", arn.String() + ":*"},
+	}
+
+	if stream := m.LogStream(); stream != "" {
+		arn.Resource = "log-group:" + group + ":log-stream:" + stream
+		attrs = append(attrs,
+			Attribute{"aws.log.stream.names", stream},
+			Attribute{"aws.log.stream.arns", arn.String()},
+		)
+	}
+
+	return attrs
+}
+
+func parseKeys(attrs string) map[string]struct{} {
+	keys := map[string]struct{}{}
+
+	for _, pair := range strings.Split(attrs, ",") {
+		if key, _, ok := strings.Cut(pair, "="); ok {
+			keys[strings.TrimSpace(key)] = struct{}{}
+		}
+	}
+
+	return keys
+}
+
+// escape percent-encodes characters that are not allowed in
+// OTEL_RESOURCE_ATTRIBUTES values (W3C Baggage value octets).
+func escape(value string) string {
+	var sb strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+
+		switch {
+		case c <= 0x20, c >= 0x7f, c == '"', c == ',', c == ';', c == '\\', c == '%':
+			fmt.Fprintf(&sb, "%%%02X", c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,141 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package otel
+
+import (
+	"testing"
+
+	"github.com/ixti/ecs-task-helper/pkg/container_metadata"
+	"github.com/ixti/ecs-task-helper/pkg/environ"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMetadata() *container_metadata.Metadata {
+	return &container_metadata.Metadata{
+		ContainerARN:          "arn:aws:ecs:us-west-2:111122223333:container/0206b271-b33f-47ab-86c6-a0ba208a70a9",
+		ContainerID:           "ea32192c8553fbff06c9340478a2ff089b2bb5646fb718b4ee206641c9086d66",
+		ContainerName:         "curl",
+		ContainerImage:        "111122223333.dkr.ecr.us-west-2.amazonaws.com/curltest:latest",
+		TaskARN:               "arn:aws:ecs:us-west-2:111122223333:task/default/8f03e41243824aea923aca126495f665",
+		TaskDefinitionFamily:  "curltest",
+		TaskDefinitionVersion: "24",
+		ClusterName:           "default",
+		AvailabilityZone:      "us-west-2b",
+		LaunchType:            "FARGATE",
+		LogDriver:             "awslogs",
+		LogOptions: map[string]string{
+			"awslogs-group":  "/ecs/metadata",
+			"awslogs-region": "us-west-2",
+			"awslogs-stream": "ecs/curl/8f03e41243824aea923aca126495f665",
+		},
+	}
+}
+
+func TestResourceAttributes(t *testing.T) {
+	t.Run("with full metadata", func(t *testing.T) {
+		assert.Equal(t, []Attribute{
+			{"cloud.provider", "aws"},
+			{"cloud.platform", "aws_ecs"},
+			{"cloud.region", "us-west-2"},
+			{"cloud.account.id", "111122223333"},
+			{"cloud.availability_zone", "us-west-2b"},
+			{"cloud.resource_id", "arn:aws:ecs:us-west-2:111122223333:container/0206b271-b33f-47ab-86c6-a0ba208a70a9"},
+			{"aws.ecs.container.arn", "arn:aws:ecs:us-west-2:111122223333:container/0206b271-b33f-47ab-86c6-a0ba208a70a9"},
+			{"aws.ecs.cluster.arn", "arn:aws:ecs:us-west-2:111122223333:cluster/default"},
+			{"aws.ecs.launchtype", "fargate"},
+			{"aws.ecs.task.arn", "arn:aws:ecs:us-west-2:111122223333:task/default/8f03e41243824aea923aca126495f665"},
+			{"aws.ecs.task.id", "8f03e41243824aea923aca126495f665"},
+			{"aws.ecs.task.family", "curltest"},
+			{"aws.ecs.task.revision", "24"},
+			{"container.id", "ea32192c8553fbff06c9340478a2ff089b2bb5646fb718b4ee206641c9086d66"},
+			{"container.name", "curl"},
+			{"container.image.name", "111122223333.dkr.ecr.us-west-2.amazonaws.com/curltest"},
+			{"container.image.tag", "latest"},
+			{"aws.log.group.names", "/ecs/metadata"},
+			{"aws.log.group.arns", "arn:aws:logs:us-west-2:111122223333:log-group:/ecs/metadata:*"},
+			{"aws.log.stream.names", "ecs/curl/8f03e41243824aea923aca126495f665"},
+			{"aws.log.stream.arns", "arn:aws:logs:us-west-2:111122223333:log-group:/ecs/metadata:log-stream:ecs/curl/8f03e41243824aea923aca126495f665"},
+		}, ResourceAttributes(testMetadata()))
+	})
+
+	t.Run("omits empty values", func(t *testing.T) {
+		assert := assert.New(t)
+
+		metadata := testMetadata()
+		metadata.AvailabilityZone = ""
+		metadata.LogDriver = "json-file"
+
+		attrs := ResourceAttributes(metadata)
+
+		for _, attr := range attrs {
+			assert.NotEqual("cloud.availability_zone", attr.Key)
+			assert.NotEqual("aws.log.group.names", attr.Key)
+		}
+	})
+
+	t.Run("with empty metadata", func(t *testing.T) {
+		assert.Nil(t, ResourceAttributes(&container_metadata.Metadata{}))
+	})
+}
+
+func TestEnvironWith(t *testing.T) {
+	t.Run("without existing variables", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		env := EnvironWith([]string{"PATH=/usr/bin"}, testMetadata())
+
+		attrs, ok := environ.Lookup(env, "OTEL_RESOURCE_ATTRIBUTES")
+		require.True(ok)
+		assert.Contains(attrs, "cloud.provider=aws,cloud.platform=aws_ecs,")
+		assert.Contains(attrs, ",aws.ecs.task.family=curltest,")
+
+		serviceName, _ := environ.Lookup(env, "OTEL_SERVICE_NAME")
+		assert.Equal("curl", serviceName)
+		assert.Contains(env, "PATH=/usr/bin")
+	})
+
+	t.Run("preserves user-set attributes", func(t *testing.T) {
+		assert := assert.New(t)
+
+		base := []string{"OTEL_RESOURCE_ATTRIBUTES=deployment.environment=production, cloud.region=eu-central-1"}
+		env := EnvironWith(base, testMetadata())
+
+		attrs, _ := environ.Lookup(env, "OTEL_RESOURCE_ATTRIBUTES")
+		assert.Contains(attrs, "deployment.environment=production, cloud.region=eu-central-1,cloud.provider=aws,")
+		assert.NotContains(attrs, "cloud.region=us-west-2")
+	})
+
+	t.Run("preserves user-set service name", func(t *testing.T) {
+		assert := assert.New(t)
+
+		env := EnvironWith([]string{"OTEL_SERVICE_NAME=myapp"}, testMetadata())
+
+		serviceName, _ := environ.Lookup(env, "OTEL_SERVICE_NAME")
+		assert.Equal("myapp", serviceName)
+	})
+
+	t.Run("does not set service name when provided as attribute", func(t *testing.T) {
+		assert := assert.New(t)
+
+		env := EnvironWith([]string{"OTEL_RESOURCE_ATTRIBUTES=service.name=myapp"}, testMetadata())
+
+		_, ok := environ.Lookup(env, "OTEL_SERVICE_NAME")
+		assert.False(ok)
+	})
+
+	t.Run("with empty metadata returns base", func(t *testing.T) {
+		base := []string{"PATH=/usr/bin"}
+
+		assert.Equal(t, base, EnvironWith(base, &container_metadata.Metadata{}))
+	})
+}
+
+func TestEscape(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("arn:aws:ecs:us-west-2:111122223333:task/default", escape("arn:aws:ecs:us-west-2:111122223333:task/default"))
+	assert.Equal("a%2Cb%3Bc%20d%25", escape("a,b;c d%"))
+}
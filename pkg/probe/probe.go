@@ -0,0 +1,189 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+// Package probe checks readiness of TCP, HTTP, DNS and file targets.
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// AttemptTimeout limits a single probe attempt.
+const AttemptTimeout = 5 * time.Second
+
+// Target is a readiness target:
+//
+//	tcp://host:port     port accepts connections
+//	http(s)://host/path responds with 2xx status
+//	dns://name          name resolves
+//	file:///path        file exists
+type Target struct {
+	url *url.URL
+	raw string
+}
+
+func Parse(s string) (*Target, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target %s: %w", s, err)
+	}
+
+	switch u.Scheme {
+	case "tcp":
+		if u.Hostname() == "" || u.Port() == "" {
+			return nil, fmt.Errorf("invalid target %s: host and port are required", s)
+		}
+
+	case "http", "https", "dns":
+		if u.Host == "" {
+			return nil, fmt.Errorf("invalid target %s: host is required", s)
+		}
+
+	case "file":
+		if (u.Host != "" && u.Host != "localhost") || u.Path == "" {
+			return nil, fmt.Errorf("invalid target %s: absolute path is required", s)
+		}
+
+	default:
+		return nil, fmt.Errorf("invalid target %s: unsupported scheme", s)
+	}
+
+	return &Target{url: u, raw: s}, nil
+}
+
+func (t *Target) String() string {
+	return t.raw
+}
+
+// Probe checks once if the target is ready.
+func (t *Target) Probe(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, AttemptTimeout)
+	defer cancel()
+
+	switch t.url.Scheme {
+	case "tcp":
+		conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", t.url.Host)
+		if err != nil {
+			return err
+		}
+
+		return conn.Close()
+
+	case "dns":
+		addrs, err := net.DefaultResolver.LookupHost(ctx, t.url.Hostname())
+		if err == nil && len(addrs) == 0 {
+			err = errors.New("no addresses")
+		}
+
+		return err
+
+	case "file":
+		_, err := os.Stat(t.url.Path)
+		return err
+
+	default:
+		return t.probeHTTP(ctx)
+	}
+}
+
+func (t *Target) probeHTTP(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.raw, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+// Wait probes the target every interval until it's ready or ctx is done.
+func (t *Target) Wait(ctx context.Context, interval time.Duration) error {
+	for {
+		err := t.Probe(ctx)
+		if err == nil {
+			return nil
+		}
+
+		slog.Debug("Target is not ready", "target", t.raw, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s is not ready: %w", t.raw, err)
+		case <-time.After(interval):
+		}
+	}
+}
+
+type Options struct {
+	// Interval between probe attempts of each target.
+	Interval time.Duration
+	// TargetTimeout limits waiting for each target. Zero means no limit.
+	TargetTimeout time.Duration
+	// Any makes WaitAll return once any target is ready.
+	Any bool
+}
+
+// WaitAll probes targets concurrently until all of them (or any of them) are
+// ready. Fails as soon as a target times out, unless waiting for any target,
+// in which case fails only if all of them time out.
+func WaitAll(ctx context.Context, targets []*Target, opts Options) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make(chan error, len(targets))
+
+	for _, target := range targets {
+		go func() {
+			ctx := ctx
+
+			if opts.TargetTimeout > 0 {
+				var cancel context.CancelFunc
+
+				ctx, cancel = context.WithTimeout(ctx, opts.TargetTimeout)
+				defer cancel()
+			}
+
+			err := target.Wait(ctx, opts.Interval)
+			if err == nil {
+				slog.Info("Target is ready", "target", target.raw)
+			}
+
+			errs <- err
+		}()
+	}
+
+	var failures []error
+
+	for range targets {
+		err := <-errs
+
+		switch {
+		case err == nil && opts.Any:
+			return nil
+		case err != nil && !opts.Any:
+			return err
+		case err != nil:
+			failures = append(failures, err)
+		}
+	}
+
+	return errors.Join(failures...)
+}
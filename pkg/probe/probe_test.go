@@ -0,0 +1,169 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package probe
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustParse(t *testing.T, s string) *Target {
+	target, err := Parse(s)
+	require.NoError(t, err)
+
+	return target
+}
+
+// closedPort returns address of a port nothing listens on.
+func closedPort(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	addr := l.Addr().String()
+	l.Close()
+
+	return addr
+}
+
+func TestParse(t *testing.T) {
+	for _, s := range []string{"tcp://db:5432", "http://svc/health", "https://svc:8443/", "dns://db.local", "file:///shared/ready"} {
+		t.Run(s, func(t *testing.T) {
+			target, err := Parse(s)
+
+			require.NoError(t, err)
+			assert.Equal(t, s, target.String())
+		})
+	}
+
+	invalid := map[string]string{
+		"tcp://db":          "host and port are required",
+		"http:///health":    "host is required",
+		"dns://":            "host is required",
+		"file://relative":   "absolute path is required",
+		"udp://db:53":       "unsupported scheme",
+		"db:5432":           "unsupported scheme",
+		"http://[::1:80/x/": "invalid target",
+	}
+
+	for s, message := range invalid {
+		t.Run(s, func(t *testing.T) {
+			_, err := Parse(s)
+
+			assert.ErrorContains(t, err, message)
+		})
+	}
+}
+
+func TestTarget_Probe(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("tcp", func(t *testing.T) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer l.Close()
+
+		assert.NoError(t, mustParse(t, "tcp://"+l.Addr().String()).Probe(ctx))
+		assert.Error(t, mustParse(t, "tcp://"+closedPort(t)).Probe(ctx))
+	})
+
+	t.Run("http", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/health" {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer server.Close()
+
+		assert.NoError(t, mustParse(t, server.URL+"/health").Probe(ctx))
+		assert.EqualError(t, mustParse(t, server.URL+"/other").Probe(ctx), "unexpected status code: 503")
+	})
+
+	t.Run("dns", func(t *testing.T) {
+		assert.NoError(t, mustParse(t, "dns://localhost").Probe(ctx))
+		assert.Error(t, mustParse(t, "dns://nonexistent.invalid").Probe(ctx))
+	})
+
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "ready")
+		target := mustParse(t, "file://"+path)
+
+		assert.Error(t, target.Probe(ctx))
+
+		require.NoError(t, os.WriteFile(path, nil, 0o644))
+		assert.NoError(t, target.Probe(ctx))
+	})
+}
+
+func TestWaitAll(t *testing.T) {
+	opts := Options{Interval: 10 * time.Millisecond}
+
+	t.Run("waits for all targets", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "ready")
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			os.WriteFile(path, nil, 0o644)
+		}()
+
+		err := WaitAll(context.Background(), []*Target{mustParse(t, "dns://localhost"), mustParse(t, "file://"+path)}, opts)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("fails when a target times out", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		err := WaitAll(ctx, []*Target{mustParse(t, "dns://localhost"), mustParse(t, "file:///nonexistent")}, opts)
+
+		assert.ErrorContains(t, err, "file:///nonexistent is not ready")
+	})
+
+	t.Run("with target timeout", func(t *testing.T) {
+		assert := assert.New(t)
+
+		opts := opts
+		opts.TargetTimeout = 50 * time.Millisecond
+
+		started := time.Now()
+		err := WaitAll(context.Background(), []*Target{mustParse(t, "file:///nonexistent")}, opts)
+
+		assert.ErrorContains(err, "file:///nonexistent is not ready")
+		assert.Less(time.Since(started), time.Second)
+	})
+
+	t.Run("with any waits for first ready target", func(t *testing.T) {
+		opts := opts
+		opts.Any = true
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		err := WaitAll(ctx, []*Target{mustParse(t, "file:///nonexistent"), mustParse(t, "dns://localhost")}, opts)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("with any fails when all targets time out", func(t *testing.T) {
+		assert := assert.New(t)
+
+		opts := opts
+		opts.Any = true
+		opts.TargetTimeout = 50 * time.Millisecond
+
+		err := WaitAll(context.Background(), []*Target{mustParse(t, "file:///nonexistent"), mustParse(t, "tcp://"+closedPort(t))}, opts)
+
+		assert.ErrorContains(err, "file:///nonexistent is not ready")
+		assert.ErrorContains(err, "is not ready: dial tcp")
+	})
+}
@@ -0,0 +1,146 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+// Package procattr applies resource limits and scheduling attributes to the
+// current process, so that they are inherited by the command it executes.
+package procattr
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	MinNice        = -20
+	MaxNice        = 19
+	MinOOMScoreAdj = -1000
+	MaxOOMScoreAdj = 1000
+)
+
+// Rlimit is a resource limit, with unix.RLIM_INFINITY for unlimited.
+type Rlimit struct {
+	Name     string
+	Resource int
+	Cur, Max uint64
+}
+
+// Resources returns names of supported resources.
+func Resources() []string {
+	return slices.Sorted(maps.Keys(resources))
+}
+
+// ParseRlimit parses "name=soft[:hard]" resource limit, where name is one of
+// the RLIMIT_* names with or without the prefix, in any case, and limits are
+// numbers or "unlimited". Without hard limit, it equals the soft one.
+func ParseRlimit(s string) (Rlimit, error) {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok {
+		return Rlimit{}, fmt.Errorf("missing = in %q", s)
+	}
+
+	name = strings.TrimPrefix(strings.ToLower(name), "rlimit_")
+
+	resource, ok := resources[name]
+	if !ok {
+		return Rlimit{}, fmt.Errorf("unknown resource: %s", name)
+	}
+
+	soft, hard, hasHard := strings.Cut(value, ":")
+	if !hasHard {
+		hard = soft
+	}
+
+	cur, err := parseLimit(soft)
+	if err != nil {
+		return Rlimit{}, fmt.Errorf("invalid %s soft limit: %w", name, err)
+	}
+
+	max, err := parseLimit(hard)
+	if err != nil {
+		return Rlimit{}, fmt.Errorf("invalid %s hard limit: %w", name, err)
+	}
+
+	if cur > max {
+		return Rlimit{}, fmt.Errorf("%s soft limit exceeds hard limit", name)
+	}
+
+	return Rlimit{Name: name, Resource: resource, Cur: cur, Max: max}, nil
+}
+
+func parseLimit(s string) (uint64, error) {
+	if s == "unlimited" {
+		return unix.RLIM_INFINITY, nil
+	}
+
+	limit, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("not a number: %q", s)
+	}
+
+	return limit, nil
+}
+
+// ParseUmask parses octal file mode creation mask, e.g. "027".
+func ParseUmask(s string) (int, error) {
+	mask, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || mask > 0o777 {
+		return 0, fmt.Errorf("not an octal mode: %q", s)
+	}
+
+	return int(mask), nil
+}
+
+// Attributes of a process. Nil attributes are left as is.
+type Attributes struct {
+	Rlimits     []Rlimit
+	Nice        *int
+	OOMScoreAdj *int
+	Umask       *int
+	// Dir is the working directory, left as is if empty.
+	Dir string
+}
+
+// Apply applies attributes to the current process: working directory,
+// resource limits, nice value, OOM score adjustment and umask, in this order.
+// Raising hard limits, lowering nice value or OOM score adjustment require
+// privileges, so it should be called before dropping them.
+func (a *Attributes) Apply() error {
+	if a.Dir != "" {
+		if err := os.Chdir(a.Dir); err != nil {
+			return fmt.Errorf("failed to change directory: %w", err)
+		}
+	}
+
+	for _, rlimit := range a.Rlimits {
+		// syscall.Setrlimit makes children inherit the limit, while the Go
+		// runtime otherwise restores the original nofile soft limit for them.
+		if err := syscall.Setrlimit(rlimit.Resource, &syscall.Rlimit{Cur: rlimit.Cur, Max: rlimit.Max}); err != nil {
+			return fmt.Errorf("failed to set %s limit: %w", rlimit.Name, err)
+		}
+	}
+
+	if a.Nice != nil {
+		if err := setNice(*a.Nice); err != nil {
+			return fmt.Errorf("failed to set nice value: %w", err)
+		}
+	}
+
+	if a.OOMScoreAdj != nil {
+		if err := setOOMScoreAdj(*a.OOMScoreAdj); err != nil {
+			return fmt.Errorf("failed to set OOM score adjustment: %w", err)
+		}
+	}
+
+	if a.Umask != nil {
+		syscall.Umask(*a.Umask)
+	}
+
+	return nil
+}
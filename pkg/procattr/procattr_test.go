@@ -0,0 +1,147 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package procattr
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestParseRlimit(t *testing.T) {
+	valid := map[string]Rlimit{
+		"nofile=65536:65536":      {Name: "nofile", Resource: unix.RLIMIT_NOFILE, Cur: 65536, Max: 65536},
+		"nofile=1024":             {Name: "nofile", Resource: unix.RLIMIT_NOFILE, Cur: 1024, Max: 1024},
+		"RLIMIT_CORE=unlimited":   {Name: "core", Resource: unix.RLIMIT_CORE, Cur: unix.RLIM_INFINITY, Max: unix.RLIM_INFINITY},
+		"stack=8388608:unlimited": {Name: "stack", Resource: unix.RLIMIT_STACK, Cur: 8388608, Max: unix.RLIM_INFINITY},
+	}
+
+	for input, expected := range valid {
+		t.Run(input, func(t *testing.T) {
+			rlimit, err := ParseRlimit(input)
+
+			require.NoError(t, err)
+			assert.Equal(t, expected, rlimit)
+		})
+	}
+
+	invalid := map[string]string{
+		"nofile":           `missing = in "nofile"`,
+		"files=1024":       "unknown resource: files",
+		"nofile=many":      `invalid nofile soft limit: not a number: "many"`,
+		"nofile=1024:-1":   `invalid nofile hard limit: not a number: "-1"`,
+		"nofile=2048:1024": "nofile soft limit exceeds hard limit",
+	}
+
+	for input, message := range invalid {
+		t.Run(input, func(t *testing.T) {
+			_, err := ParseRlimit(input)
+
+			assert.EqualError(t, err, message)
+		})
+	}
+}
+
+func TestParseUmask(t *testing.T) {
+	for input, expected := range map[string]int{"022": 0o22, "0027": 0o27, "0": 0, "777": 0o777} {
+		t.Run(input, func(t *testing.T) {
+			mask, err := ParseUmask(input)
+
+			require.NoError(t, err)
+			assert.Equal(t, expected, mask)
+		})
+	}
+
+	for _, input := range []string{"", "8", "1000", "rwx"} {
+		t.Run(input, func(t *testing.T) {
+			_, err := ParseUmask(input)
+
+			assert.EqualError(t, err, fmt.Sprintf("not an octal mode: %q", input))
+		})
+	}
+}
+
+// TestAttributes_Apply applies attributes in a test binary subprocess, which
+// then reports them, as some of them can't be reverted without privileges.
+func TestAttributes_Apply(t *testing.T) {
+	if os.Getenv("PROCATTR_APPLY_HELPER") == "1" {
+		nice, umask := 5, 0o27
+		attrs := &Attributes{
+			Rlimits: []Rlimit{{Name: "core", Resource: unix.RLIMIT_CORE, Cur: 0, Max: 0}},
+			Nice:    &nice,
+			Umask:   &umask,
+			Dir:     os.TempDir(),
+		}
+
+		if runtime.GOOS == "linux" {
+			score := 500
+			attrs.OOMScoreAdj = &score
+		}
+
+		if err := attrs.Apply(); err != nil {
+			fmt.Fprint(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		// Reports attributes of a child, which inherits them.
+		cmd := exec.Command("sh", "-c", `ulimit -c; umask; pwd; ps -o nice= -p $$; cat /proc/self/oom_score_adj 2>/dev/null || echo 500`)
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+
+		if err := cmd.Run(); err != nil {
+			os.Exit(1)
+		}
+
+		os.Exit(0)
+	}
+
+	require := require.New(t)
+	assert := assert.New(t)
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestAttributes_Apply$")
+	cmd.Env = append(os.Environ(), "PROCATTR_APPLY_HELPER=1")
+
+	out, err := cmd.Output()
+	require.NoError(err, string(out))
+
+	lines := strings.Fields(string(out))
+	require.Len(lines, 5)
+
+	dir, err := os.Getwd()
+	require.NoError(err)
+
+	assert.Equal("0", lines[0])
+	assert.Equal("0027", lines[1])
+	assert.NotEqual(dir, lines[2])
+	assert.Equal("5", lines[3])
+	assert.Equal("500", lines[4])
+}
+
+func TestAttributes_Apply_errors(t *testing.T) {
+	t.Run("with missing directory", func(t *testing.T) {
+		attrs := &Attributes{Dir: "/nonexistent/directory"}
+
+		assert.ErrorContains(t, attrs.Apply(), "failed to change directory")
+	})
+
+	t.Run("with limit above hard limit", func(t *testing.T) {
+		if os.Geteuid() == 0 {
+			t.Skip("requires unprivileged user")
+		}
+
+		var current syscall.Rlimit
+		require.NoError(t, syscall.Getrlimit(unix.RLIMIT_NOFILE, &current))
+
+		attrs := &Attributes{Rlimits: []Rlimit{{Name: "nofile", Resource: unix.RLIMIT_NOFILE, Cur: current.Max, Max: unix.RLIM_INFINITY}}}
+
+		assert.ErrorContains(t, attrs.Apply(), "failed to set nofile limit")
+	})
+}
@@ -0,0 +1,92 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+// Package procfile parses process definitions in the Procfile format:
+//
+//	# comment
+//	web: bin/server --port 8080
+//	worker: bin/worker
+//
+// Names consist of letters, digits, underscores and dashes, and must be
+// unique. Commands are kept as is.
+package procfile
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Process is a named command.
+type Process struct {
+	Name    string
+	Command string
+}
+
+// ParseFile parses Procfile at path. See Parse.
+func ParseFile(path string) ([]Process, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read procfile: %w", err)
+	}
+
+	processes, err := Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", path, err)
+	}
+
+	return processes, nil
+}
+
+// Parse parses Procfile content into processes in the order of definition.
+// Errors are prefixed with the line number.
+func Parse(content string) ([]Process, error) {
+	var processes []Process
+
+	seen := map[string]bool{}
+
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		process, err := ParseProcess(line, ":")
+		if err != nil {
+			return nil, fmt.Errorf("%d: %w", i+1, err)
+		}
+
+		if seen[process.Name] {
+			return nil, fmt.Errorf("%d: duplicate process %s", i+1, process.Name)
+		}
+
+		seen[process.Name] = true
+		processes = append(processes, process)
+	}
+
+	return processes, nil
+}
+
+// ParseProcess parses process definition with name and command separated by
+// sep, e.g. "web: bin/server" with ":" or "web=bin/server" with "=".
+func ParseProcess(s string, sep string) (Process, error) {
+	name, command, ok := strings.Cut(s, sep)
+	if !ok {
+		return Process{}, fmt.Errorf("missing %s in %q", sep, s)
+	}
+
+	name, command = strings.TrimSpace(name), strings.TrimSpace(command)
+
+	if !namePattern.MatchString(name) {
+		return Process{}, fmt.Errorf("invalid process name: %q", name)
+	}
+
+	if command == "" {
+		return Process{}, fmt.Errorf("empty command of %s", name)
+	}
+
+	return Process{Name: name, Command: command}, nil
+}
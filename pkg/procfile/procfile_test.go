@@ -0,0 +1,105 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package procfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	valid := []struct {
+		name     string
+		input    string
+		expected []Process
+	}{
+		{"empty", "", nil},
+		{"comments and blank lines", "# comment\n\n  # indented\nweb: bin/server\n", []Process{{"web", "bin/server"}}},
+		{"order of definition", "web: bin/server\nworker: bin/worker", []Process{{"web", "bin/server"}, {"worker", "bin/worker"}}},
+		{"spaces around separator", "web :  bin/server --port 8080 ", []Process{{"web", "bin/server --port 8080"}}},
+		{"colon in command", "web: bin/server --listen :8080", []Process{{"web", "bin/server --listen :8080"}}},
+		{"CRLF line endings", "web: bin/server\r\nlog_shipper-1: bin/ship\r\n", []Process{{"web", "bin/server"}, {"log_shipper-1", "bin/ship"}}},
+	}
+
+	for _, tc := range valid {
+		t.Run(tc.name, func(t *testing.T) {
+			processes, err := Parse(tc.input)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, processes)
+		})
+	}
+
+	invalid := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{"missing separator", "\nweb bin/server", `2: missing : in "web bin/server"`},
+		{"invalid name", "web.1: bin/server", `1: invalid process name: "web.1"`},
+		{"empty name", ": bin/server", `1: invalid process name: ""`},
+		{"empty command", "web:", "1: empty command of web"},
+		{"duplicate name", "web: a\nweb: b", "2: duplicate process web"},
+	}
+
+	for _, tc := range invalid {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse(tc.input)
+
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestParseProcess(t *testing.T) {
+	t.Run("with = separator", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		process, err := ParseProcess("worker=bin/worker --queue=default", "=")
+
+		require.NoError(err)
+		assert.Equal(Process{"worker", "bin/worker --queue=default"}, process)
+	})
+
+	t.Run("with missing separator", func(t *testing.T) {
+		_, err := ParseProcess("bin/worker", "=")
+
+		assert.EqualError(t, err, `missing = in "bin/worker"`)
+	})
+}
+
+func TestParseFile(t *testing.T) {
+	t.Run("with valid file", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		path := filepath.Join(t.TempDir(), "Procfile")
+		require.NoError(os.WriteFile(path, []byte("web: bin/server\n"), 0o644))
+
+		processes, err := ParseFile(path)
+
+		require.NoError(err)
+		assert.Equal([]Process{{"web", "bin/server"}}, processes)
+	})
+
+	t.Run("with invalid file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "Procfile")
+		require.NoError(t, os.WriteFile(path, []byte("web: bin/server\nworker\n"), 0o644))
+
+		_, err := ParseFile(path)
+
+		assert.EqualError(t, err, path+`:2: missing : in "worker"`)
+	})
+
+	t.Run("with missing file", func(t *testing.T) {
+		_, err := ParseFile(filepath.Join(t.TempDir(), "Procfile"))
+
+		assert.ErrorContains(t, err, "failed to read procfile")
+	})
+}
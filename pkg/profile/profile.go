@@ -0,0 +1,223 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+// Package profile maps ECS metadata onto vendor-specific environment variables.
+//
+// A profile is a set of variable templates. Each template may reference
+// metadata fields as {field}, e.g. "{taskDefinitionFamily}@{containerImageTag}",
+// or container labels as {label:name}.
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ixti/ecs-task-helper/pkg/container_metadata"
+	"github.com/ixti/ecs-task-helper/pkg/environ"
+)
+
+const labelPrefix = "label:"
+
+type Profile struct {
+	Name      string
+	Variables map[string]string
+}
+
+// Registry holds profiles by name.
+type Registry map[string]*Profile
+
+// Builtin returns registry of built-in vendor profiles.
+func Builtin() Registry {
+	return Registry{
+		"datadog": {
+			Name: "datadog",
+			Variables: map[string]string{
+				"DD_ENV":     "{clusterName}",
+				"DD_SERVICE": "{containerName}",
+				"DD_VERSION": "{containerImageTag}",
+			},
+		},
+		"sentry": {
+			Name: "sentry",
+			Variables: map[string]string{
+				"SENTRY_ENVIRONMENT": "{clusterName}",
+				"SENTRY_RELEASE":     "{taskDefinitionFamily}@{containerImageTag}",
+				"SENTRY_SERVER_NAME": "{taskID}",
+			},
+		},
+		"newrelic": {
+			Name: "newrelic",
+			Variables: map[string]string{
+				"NEW_RELIC_APP_NAME":                  "{containerName}",
+				"NEW_RELIC_LABELS":                    "Cluster:{clusterName};Family:{taskDefinitionFamily};Version:{containerImageTag}",
+				"NEW_RELIC_PROCESS_HOST_DISPLAY_NAME": "{taskID}",
+			},
+		},
+		"honeycomb": {
+			Name: "honeycomb",
+			Variables: map[string]string{
+				"HONEYCOMB_DATASET": "{clusterName}",
+				"SERVICE_NAME":      "{containerName}",
+				"SERVICE_VERSION":   "{containerImageTag}",
+			},
+		},
+	}
+}
+
+// LoadFile loads profiles from a JSON file into the registry, replacing
+// profiles with the same name. The file is an object of profile names to
+// objects of variable templates:
+//
+//	{"myvendor": {"MY_RELEASE": "{taskDefinitionFamily}@{containerImageTag}"}}
+func (r Registry) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read profiles file: %w", err)
+	}
+
+	var profiles map[string]map[string]string
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return fmt.Errorf("failed to parse profiles file: %w", err)
+	}
+
+	for name, variables := range profiles {
+		for key, template := range variables {
+			if err := validate(template); err != nil {
+				return fmt.Errorf("invalid template for %s in profile %s: %w", key, name, err)
+			}
+		}
+
+		r[name] = &Profile{Name: name, Variables: variables}
+	}
+
+	return nil
+}
+
+// Resolve returns profiles by names in the given order.
+func (r Registry) Resolve(names []string) ([]*Profile, error) {
+	profiles := make([]*Profile, 0, len(names))
+
+	for _, name := range names {
+		p, ok := r[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown profile: %s", name)
+		}
+
+		profiles = append(profiles, p)
+	}
+
+	return profiles, nil
+}
+
+// Environ returns profile variables rendered from the metadata, sorted by name.
+// Variables referencing empty fields are omitted.
+func (p *Profile) Environ(m *container_metadata.Metadata) []string {
+	values := fields(m)
+	result := make([]string, 0, len(p.Variables))
+
+	for _, key := range slices.Sorted(maps.Keys(p.Variables)) {
+		if value, ok := render(p.Variables[key], m, values); ok {
+			result = append(result, key+"="+value)
+		}
+	}
+
+	return result
+}
+
+// EnvironWith returns base with variables of all profiles merged in.
+// Variables already set to a non-empty value in base are kept as is.
+func EnvironWith(base []string, m *container_metadata.Metadata, profiles []*Profile) []string {
+	env := base
+
+	for _, p := range profiles {
+		for _, v := range p.Environ(m) {
+			key, value, _ := strings.Cut(v, "=")
+
+			if existing, _ := environ.Lookup(env, key); existing == "" {
+				env = environ.Set(env, key, value)
+			}
+		}
+	}
+
+	return env
+}
+
+func fields(m *container_metadata.Metadata) map[string]string {
+	return map[string]string{
+		"containerARN":          m.ContainerARN,
+		"containerID":           m.ContainerID,
+		"containerName":         m.ContainerName,
+		"containerImage":        m.ContainerImage,
+		"containerImageName":    m.ContainerImageName(),
+		"containerImageTag":     m.ContainerImageTag(),
+		"taskARN":               m.TaskARN,
+		"taskID":                m.TaskID(),
+		"taskDefinitionFamily":  m.TaskDefinitionFamily,
+		"taskDefinitionVersion": m.TaskDefinitionVersion,
+		"clusterName":           m.ClusterName,
+		"clusterARN":            m.ClusterARN(),
+		"region":                m.Region(),
+		"accountID":             m.AccountID(),
+		"availabilityZone":      m.AvailabilityZone,
+		"launchType":            m.LaunchType,
+	}
+}
+
+// render expands {field} references of the template.
+// Returns false if any of the referenced fields is empty.
+func render(template string, m *container_metadata.Metadata, values map[string]string) (string, bool) {
+	var sb strings.Builder
+
+	rest := template
+	for {
+		before, after, found := strings.Cut(rest, "{")
+		sb.WriteString(before)
+
+		if !found {
+			return sb.String(), true
+		}
+
+		name, tail, _ := strings.Cut(after, "}")
+
+		var value string
+		if label, ok := strings.CutPrefix(name, labelPrefix); ok {
+			value = m.Labels[label]
+		} else {
+			value = values[name]
+		}
+
+		if value == "" {
+			return "", false
+		}
+
+		sb.WriteString(value)
+		rest = tail
+	}
+}
+
+func validate(template string) error {
+	known := fields(&container_metadata.Metadata{})
+
+	rest := template
+	for {
+		_, after, found := strings.Cut(rest, "{")
+		if !found {
+			return nil
+		}
+
+		name, tail, closed := strings.Cut(after, "}")
+		if !closed {
+			return fmt.Errorf("unterminated field reference")
+		}
+
+		if _, ok := known[name]; !ok && !strings.HasPrefix(name, labelPrefix) {
+			return fmt.Errorf("unknown field: %s", name)
+		}
+
+		rest = tail
+	}
+}
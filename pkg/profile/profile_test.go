@@ -0,0 +1,156 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ixti/ecs-task-helper/pkg/container_metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMetadata() *container_metadata.Metadata {
+	return &container_metadata.Metadata{
+		ContainerARN:          "arn:aws:ecs:us-west-2:111122223333:container/0206b271-b33f-47ab-86c6-a0ba208a70a9",
+		ContainerName:         "curl",
+		ContainerImage:        "111122223333.dkr.ecr.us-west-2.amazonaws.com/curltest:v1.2.3",
+		TaskARN:               "arn:aws:ecs:us-west-2:111122223333:task/default/8f03e41243824aea923aca126495f665",
+		TaskDefinitionFamily:  "curltest",
+		TaskDefinitionVersion: "24",
+		ClusterName:           "default",
+		Labels: map[string]string{
+			"com.example.environment": "production",
+		},
+	}
+}
+
+func writeProfilesFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	return path
+}
+
+func TestBuiltin(t *testing.T) {
+	assert := assert.New(t)
+
+	registry := Builtin()
+
+	for _, name := range []string{"datadog", "sentry", "newrelic", "honeycomb"} {
+		p, ok := registry[name]
+		if assert.True(ok, name) {
+			for key, template := range p.Variables {
+				assert.NoError(validate(template), key)
+			}
+		}
+	}
+}
+
+func TestRegistry_LoadFile(t *testing.T) {
+	t.Run("with valid file", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		registry := Builtin()
+		path := writeProfilesFile(t, `{
+			"custom": {"APP_ENV": "{label:com.example.environment}"},
+			"datadog": {"DD_ENV": "{label:com.example.environment}"}
+		}`)
+
+		require.NoError(registry.LoadFile(path))
+
+		assert.Equal(map[string]string{"APP_ENV": "{label:com.example.environment}"}, registry["custom"].Variables)
+		assert.Equal(map[string]string{"DD_ENV": "{label:com.example.environment}"}, registry["datadog"].Variables)
+		assert.Contains(registry, "sentry")
+	})
+
+	t.Run("with missing file", func(t *testing.T) {
+		err := Builtin().LoadFile(filepath.Join(t.TempDir(), "missing.json"))
+
+		assert.ErrorContains(t, err, "failed to read profiles file")
+	})
+
+	t.Run("with invalid JSON", func(t *testing.T) {
+		err := Builtin().LoadFile(writeProfilesFile(t, `{invalid`))
+
+		assert.ErrorContains(t, err, "failed to parse profiles file")
+	})
+
+	t.Run("with unknown field", func(t *testing.T) {
+		err := Builtin().LoadFile(writeProfilesFile(t, `{"custom": {"APP_ENV": "{environment}"}}`))
+
+		assert.ErrorContains(t, err, "invalid template for APP_ENV in profile custom: unknown field: environment")
+	})
+
+	t.Run("with unterminated field reference", func(t *testing.T) {
+		err := Builtin().LoadFile(writeProfilesFile(t, `{"custom": {"APP_ENV": "{clusterName"}}`))
+
+		assert.ErrorContains(t, err, "unterminated field reference")
+	})
+}
+
+func TestRegistry_Resolve(t *testing.T) {
+	t.Run("with known profiles", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		profiles, err := Builtin().Resolve([]string{"sentry", "datadog"})
+
+		require.NoError(err)
+		require.Len(profiles, 2)
+		assert.Equal("sentry", profiles[0].Name)
+		assert.Equal("datadog", profiles[1].Name)
+	})
+
+	t.Run("with unknown profile", func(t *testing.T) {
+		_, err := Builtin().Resolve([]string{"datadog", "unknown"})
+
+		assert.ErrorContains(t, err, "unknown profile: unknown")
+	})
+}
+
+func TestProfile_Environ(t *testing.T) {
+	t.Run("renders templates", func(t *testing.T) {
+		assert.Equal(t, []string{
+			"SENTRY_ENVIRONMENT=default",
+			"SENTRY_RELEASE=curltest@v1.2.3",
+			"SENTRY_SERVER_NAME=8f03e41243824aea923aca126495f665",
+		}, Builtin()["sentry"].Environ(testMetadata()))
+	})
+
+	t.Run("renders labels", func(t *testing.T) {
+		p := &Profile{Variables: map[string]string{"APP_ENV": "{label:com.example.environment}"}}
+
+		assert.Equal(t, []string{"APP_ENV=production"}, p.Environ(testMetadata()))
+	})
+
+	t.Run("omits variables referencing empty fields", func(t *testing.T) {
+		metadata := testMetadata()
+		metadata.ContainerImage = "curltest"
+
+		assert.Equal(t, []string{
+			"DD_ENV=default",
+			"DD_SERVICE=curl",
+		}, Builtin()["datadog"].Environ(metadata))
+	})
+}
+
+func TestEnvironWith(t *testing.T) {
+	assert := assert.New(t)
+
+	profiles, _ := Builtin().Resolve([]string{"datadog"})
+	base := []string{"PATH=/usr/bin", "DD_ENV=staging", "DD_SERVICE="}
+
+	env := EnvironWith(base, testMetadata(), profiles)
+
+	assert.Contains(env, "PATH=/usr/bin")
+	assert.Contains(env, "DD_ENV=staging")
+	assert.Contains(env, "DD_SERVICE=curl")
+	assert.Contains(env, "DD_VERSION=v1.2.3")
+	assert.NotContains(env, "DD_ENV=default")
+	assert.NotContains(env, "DD_SERVICE=")
+}
@@ -0,0 +1,172 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ixti/ecs-task-helper/pkg/environ"
+	"github.com/ixti/ecs-task-helper/pkg/sigv4"
+)
+
+// containerCredentialsHost is the ECS agent credentials endpoint host.
+//
+// See: https://docs.aws.amazon.com/AmazonECS/latest/developerguide/task-iam-roles.html
+const containerCredentialsHost = "169.254.170.2"
+
+// credentialsRefreshWindow is how long before expiration cached credentials
+// are refreshed.
+const credentialsRefreshWindow = 5 * time.Minute
+
+var ErrMissingCredentials = errors.New("no AWS credentials found")
+
+type CredentialsProvider interface {
+	Retrieve(ctx context.Context) (sigv4.Credentials, error)
+}
+
+// NewCredentialsProvider returns provider of credentials from AWS_ACCESS_KEY_ID
+// and AWS_SECRET_ACCESS_KEY if set, or the container credentials endpoint
+// given by AWS_CONTAINER_CREDENTIALS_RELATIVE_URI or _FULL_URI.
+func NewCredentialsProvider(env []string, client *http.Client) (CredentialsProvider, error) {
+	accessKeyID, _ := environ.Lookup(env, "AWS_ACCESS_KEY_ID")
+	secretAccessKey, _ := environ.Lookup(env, "AWS_SECRET_ACCESS_KEY")
+
+	if accessKeyID != "" && secretAccessKey != "" {
+		sessionToken, _ := environ.Lookup(env, "AWS_SESSION_TOKEN")
+
+		return staticCredentials{AccessKeyID: accessKeyID, SecretAccessKey: secretAccessKey, SessionToken: sessionToken}, nil
+	}
+
+	if uri, _ := environ.Lookup(env, "AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); uri != "" {
+		return &containerCredentials{client: client, url: "http://" + containerCredentialsHost + uri}, nil
+	}
+
+	if uri, _ := environ.Lookup(env, "AWS_CONTAINER_CREDENTIALS_FULL_URI"); uri != "" {
+		if err := validateCredentialsURI(uri); err != nil {
+			return nil, err
+		}
+
+		token, _ := environ.Lookup(env, "AWS_CONTAINER_AUTHORIZATION_TOKEN")
+		tokenFile, _ := environ.Lookup(env, "AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE")
+
+		return &containerCredentials{client: client, url: uri, token: token, tokenFile: tokenFile}, nil
+	}
+
+	return nil, ErrMissingCredentials
+}
+
+// validateCredentialsURI makes sure authorization token is never sent in
+// clear text anywhere but to a loopback or ECS agent address.
+func validateCredentialsURI(uri string) error {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return fmt.Errorf("invalid AWS_CONTAINER_CREDENTIALS_FULL_URI: %w", err)
+	}
+
+	if u.Scheme == "https" {
+		return nil
+	}
+
+	if u.Scheme == "http" {
+		host := u.Hostname()
+		if host == "localhost" || host == containerCredentialsHost {
+			return nil
+		}
+
+		if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid AWS_CONTAINER_CREDENTIALS_FULL_URI: %s is neither HTTPS nor loopback", uri)
+}
+
+type staticCredentials sigv4.Credentials
+
+func (c staticCredentials) Retrieve(context.Context) (sigv4.Credentials, error) {
+	return sigv4.Credentials(c), nil
+}
+
+type containerCredentialsPayload struct {
+	AccessKeyID     string    `json:"AccessKeyId"`
+	SecretAccessKey string    `json:"SecretAccessKey"`
+	Token           string    `json:"Token"`
+	Expiration      time.Time `json:"Expiration"`
+}
+
+// containerCredentials retrieves temporary credentials from the container
+// credentials endpoint and caches them until shortly before they expire.
+type containerCredentials struct {
+	client    *http.Client
+	url       string
+	token     string
+	tokenFile string
+
+	mu         sync.Mutex
+	cached     sigv4.Credentials
+	expiration time.Time
+}
+
+func (c *containerCredentials) Retrieve(ctx context.Context) (sigv4.Credentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cached.AccessKeyID != "" && time.Until(c.expiration) > credentialsRefreshWindow {
+		return c.cached, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return sigv4.Credentials{}, fmt.Errorf("failed to prepare credentials request: %w", err)
+	}
+
+	token := c.token
+	if c.tokenFile != "" {
+		data, err := os.ReadFile(c.tokenFile)
+		if err != nil {
+			return sigv4.Credentials{}, fmt.Errorf("failed to read authorization token: %w", err)
+		}
+
+		token = strings.TrimSpace(string(data))
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return sigv4.Credentials{}, fmt.Errorf("failed to execute credentials request: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return sigv4.Credentials{}, fmt.Errorf("credentials request failed with status %d", res.StatusCode)
+	}
+
+	payload := &containerCredentialsPayload{}
+	if err := json.NewDecoder(res.Body).Decode(payload); err != nil {
+		return sigv4.Credentials{}, fmt.Errorf("failed to decode credentials response: %w", err)
+	}
+
+	c.cached = sigv4.Credentials{
+		AccessKeyID:     payload.AccessKeyID,
+		SecretAccessKey: payload.SecretAccessKey,
+		SessionToken:    payload.Token,
+	}
+	c.expiration = payload.Expiration
+
+	return c.cached, nil
+}
@@ -0,0 +1,53 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+var fileValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "`", "\\`", "\n", `\n`)
+
+// WriteFile renders values as "NAME=\"value\"" lines, sorted by name, which
+// can be sourced by shells and read by dotenv parsers. The file is replaced
+// atomically and is readable by the owner only.
+func WriteFile(path string, values map[string]string) error {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	var sb strings.Builder
+	for _, name := range names {
+		sb.WriteString(name + `="` + fileValueEscaper.Replace(values[name]) + "\"\n")
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write secrets file: %w", err)
+	}
+
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(sb.String()); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write secrets file: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write secrets file: %w", err)
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("failed to write secrets file: %w", err)
+	}
+
+	return nil
+}
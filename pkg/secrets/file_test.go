@@ -0,0 +1,60 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFile(t *testing.T) {
+	t.Run("renders sorted escaped values", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		path := filepath.Join(t.TempDir(), "secrets.env")
+
+		require.NoError(WriteFile(path, map[string]string{
+			"TOKEN":    "t0k3n",
+			"PASSWORD": "a\"b\\c$d`e\nf",
+		}))
+
+		data, err := os.ReadFile(path)
+		require.NoError(err)
+		assert.Equal("PASSWORD=\"a\\\"b\\\\c\\$d\\`e\\nf\"\nTOKEN=\"t0k3n\"\n", string(data))
+
+		info, err := os.Stat(path)
+		require.NoError(err)
+		assert.Equal(os.FileMode(0o600), info.Mode().Perm())
+	})
+
+	t.Run("replaces existing file", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "secrets.env")
+
+		require.NoError(WriteFile(path, map[string]string{"TOKEN": "old"}))
+		require.NoError(WriteFile(path, map[string]string{"TOKEN": "new"}))
+
+		data, err := os.ReadFile(path)
+		require.NoError(err)
+		assert.Equal("TOKEN=\"new\"\n", string(data))
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(err)
+		assert.Len(entries, 1)
+	})
+
+	t.Run("with missing directory", func(t *testing.T) {
+		err := WriteFile(filepath.Join(t.TempDir(), "missing", "secrets.env"), nil)
+
+		assert.ErrorContains(t, err, "failed to write secrets file")
+	})
+}
@@ -0,0 +1,139 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package secrets
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+)
+
+const (
+	ServiceSSM            = "ssm"
+	ServiceSecretsManager = "secretsmanager"
+)
+
+// Reference points to an SSM parameter or a Secrets Manager secret:
+//
+//	ssm:///path/to/param[?version=N|label]
+//	secretsmanager://name[#jsonKey][?version=id&stage=AWSCURRENT&default=value]
+//
+// JSON key selection and default value are supported by both services.
+type Reference struct {
+	Service string
+	// Name of the parameter or secret, or its ARN.
+	Name string
+	// Key selects a top-level key of the value parsed as a JSON object.
+	Key string
+	// Version is parameter version or label for SSM, version ID for
+	// Secrets Manager.
+	Version string
+	// Stage is the Secrets Manager version stage.
+	Stage string
+	// Default is used when the parameter, secret or key does not exist.
+	Default    string
+	HasDefault bool
+}
+
+// ParseReference parses a secret reference. Returns false if value is not
+// a reference at all, and an error if it is a malformed one.
+func ParseReference(value string) (*Reference, bool, error) {
+	service, rest, ok := strings.Cut(value, "://")
+	if !ok || (service != ServiceSSM && service != ServiceSecretsManager) {
+		return nil, false, nil
+	}
+
+	ref := &Reference{Service: service}
+
+	// Names can contain neither "?" nor "#", so both the query and the key
+	// can come in any order, e.g. "name#key?version=1".
+	name, query, key := rest, "", ""
+	if i := strings.IndexAny(name, "?#"); i >= 0 {
+		name, rest = name[:i], name[i:]
+
+		for rest != "" {
+			end := strings.IndexAny(rest[1:], "?#") + 1
+			if end == 0 {
+				end = len(rest)
+			}
+
+			if rest[0] == '?' {
+				query = rest[1:end]
+			} else {
+				key = rest[1:end]
+			}
+
+			rest = rest[end:]
+		}
+	}
+
+	if name == "" {
+		return nil, true, fmt.Errorf("missing name in %s reference", service)
+	}
+
+	ref.Name, ref.Key = name, key
+
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, true, fmt.Errorf("invalid %s reference query: %w", service, err)
+	}
+
+	for param, values := range params {
+		v := values[len(values)-1]
+
+		switch {
+		case param == "version":
+			ref.Version = v
+		case param == "stage" && service == ServiceSecretsManager:
+			ref.Stage = v
+		case param == "default":
+			ref.Default, ref.HasDefault = v, true
+		default:
+			return nil, true, fmt.Errorf("unknown %s reference parameter: %s", service, param)
+		}
+	}
+
+	return ref, true, nil
+}
+
+// String returns the reference in its canonical form.
+func (r *Reference) String() string {
+	s := r.Service + "://" + r.Name
+
+	if r.Key != "" {
+		s += "#" + r.Key
+	}
+
+	params := url.Values{}
+	if r.Version != "" {
+		params.Set("version", r.Version)
+	}
+
+	if r.Stage != "" {
+		params.Set("stage", r.Stage)
+	}
+
+	if r.HasDefault {
+		params.Set("default", r.Default)
+	}
+
+	if len(params) > 0 {
+		s += "?" + params.Encode()
+	}
+
+	return s
+}
+
+// LogValue identifies the parameter or secret by its service, name and key
+// only, as the default is a secret value itself.
+func (r *Reference) LogValue() slog.Value {
+	attrs := []slog.Attr{slog.String("service", r.Service), slog.String("name", r.Name)}
+
+	if r.Key != "" {
+		attrs = append(attrs, slog.String("key", r.Key))
+	}
+
+	return slog.GroupValue(attrs...)
+}
@@ -0,0 +1,130 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package secrets
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReference(t *testing.T) {
+	t.Run("with SSM parameter path", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		ref, ok, err := ParseReference("ssm:///myapp/database/password")
+
+		require.NoError(err)
+		require.True(ok)
+		assert.Equal(&Reference{Service: ServiceSSM, Name: "/myapp/database/password"}, ref)
+	})
+
+	t.Run("with SSM parameter version", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		ref, ok, err := ParseReference("ssm://password?version=3")
+
+		require.NoError(err)
+		require.True(ok)
+		assert.Equal(&Reference{Service: ServiceSSM, Name: "password", Version: "3"}, ref)
+	})
+
+	t.Run("with Secrets Manager key after query", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		ref, ok, err := ParseReference("secretsmanager://myapp/db#password?version=v1&default=secret")
+
+		require.NoError(err)
+		require.True(ok)
+		assert.Equal(&Reference{
+			Service:    ServiceSecretsManager,
+			Name:       "myapp/db",
+			Key:        "password",
+			Version:    "v1",
+			Default:    "secret",
+			HasDefault: true,
+		}, ref)
+	})
+
+	t.Run("with Secrets Manager key before query", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		ref, ok, err := ParseReference("secretsmanager://arn:aws:secretsmanager:us-west-2:111122223333:secret:myapp/db-AbCdEf?stage=AWSPREVIOUS#password")
+
+		require.NoError(err)
+		require.True(ok)
+		assert.Equal(&Reference{
+			Service: ServiceSecretsManager,
+			Name:    "arn:aws:secretsmanager:us-west-2:111122223333:secret:myapp/db-AbCdEf",
+			Key:     "password",
+			Stage:   "AWSPREVIOUS",
+		}, ref)
+	})
+
+	t.Run("with empty default", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		ref, _, err := ParseReference("ssm:///feature?default=")
+
+		require.NoError(err)
+		assert.True(ref.HasDefault)
+		assert.Empty(ref.Default)
+	})
+
+	t.Run("with plain value", func(t *testing.T) {
+		for _, value := range []string{"", "password", "https://example.com", "ssm:/path"} {
+			ref, ok, err := ParseReference(value)
+
+			assert.NoError(t, err, value)
+			assert.False(t, ok, value)
+			assert.Nil(t, ref, value)
+		}
+	})
+
+	t.Run("with missing name", func(t *testing.T) {
+		_, ok, err := ParseReference("secretsmanager://#password")
+
+		assert.True(t, ok)
+		assert.ErrorContains(t, err, "missing name in secretsmanager reference")
+	})
+
+	t.Run("with unknown parameter", func(t *testing.T) {
+		_, _, err := ParseReference("ssm:///password?stage=AWSCURRENT")
+
+		assert.ErrorContains(t, err, "unknown ssm reference parameter: stage")
+	})
+}
+
+func TestReference_String(t *testing.T) {
+	for _, s := range []string{
+		"ssm:///myapp/database/password",
+		"ssm://password?version=3",
+		"secretsmanager://myapp/db#password?default=secret&stage=AWSCURRENT&version=v1",
+	} {
+		ref, _, err := ParseReference(s)
+		require.NoError(t, err)
+
+		assert.Equal(t, s, ref.String())
+	}
+}
+
+func TestReference_LogValue(t *testing.T) {
+	ref, _, err := ParseReference("secretsmanager://myapp/db#password?default=hunter2&version=v1")
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+
+	slog.New(slog.NewTextHandler(&out, nil)).Info("Secret changed", "reference", ref)
+
+	assert.Contains(t, out.String(), "reference.service=secretsmanager reference.name=myapp/db reference.key=password")
+	assert.NotContains(t, out.String(), "hunter2")
+}
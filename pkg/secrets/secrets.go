@@ -0,0 +1,367 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+// Package secrets resolves references to SSM Parameter Store parameters and
+// Secrets Manager secrets found in environment variables.
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ixti/ecs-task-helper/pkg/container_metadata"
+	"github.com/ixti/ecs-task-helper/pkg/environ"
+	"github.com/ixti/ecs-task-helper/pkg/sigv4"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// endpointEnvs lists per-service endpoint overrides, following AWS SDK naming.
+// AWS_ENDPOINT_URL overrides endpoints of all services.
+var endpointEnvs = map[string]string{
+	ServiceSSM:            "AWS_ENDPOINT_URL_SSM",
+	ServiceSecretsManager: "AWS_ENDPOINT_URL_SECRETS_MANAGER",
+}
+
+var ErrMissingRegion = errors.New("AWS region is unknown")
+
+// APIError is an error returned by AWS API.
+type APIError struct {
+	StatusCode int
+	Type       string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s (status %d): %s", e.Type, e.StatusCode, e.Message)
+}
+
+func (e *APIError) isNotFound() bool {
+	// Type may be qualified, e.g. "com.amazonaws.secretsmanager#ResourceNotFoundException".
+	_, name, _ := strings.Cut(e.Type, "#")
+	if name == "" {
+		name = e.Type
+	}
+
+	return name == "ParameterNotFound" || name == "ParameterVersionNotFound" || name == "ResourceNotFoundException"
+}
+
+// Variable is an environment variable holding a secret reference.
+type Variable struct {
+	Name      string
+	Reference *Reference
+}
+
+// Find returns variables of env with secret references as values.
+func Find(env []string) ([]Variable, error) {
+	var variables []Variable
+
+	for _, kv := range env {
+		name, value, _ := strings.Cut(kv, "=")
+
+		ref, ok, err := ParseReference(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret reference in %s: %w", name, err)
+		}
+
+		if ok {
+			variables = append(variables, Variable{Name: name, Reference: ref})
+		}
+	}
+
+	return variables, nil
+}
+
+type Resolver struct {
+	// Region is used unless reference name is an ARN.
+	Region string
+	// Endpoints override service endpoint URLs by service name.
+	Endpoints   map[string]string
+	Credentials CredentialsProvider
+	Client      *http.Client
+}
+
+// NewResolver configures resolver from AWS_REGION (or AWS_DEFAULT_REGION),
+// AWS_ENDPOINT_URL* and credentials variables of env.
+func NewResolver(env []string) (*Resolver, error) {
+	client := &http.Client{Timeout: defaultTimeout}
+
+	credentials, err := NewCredentialsProvider(env, client)
+	if err != nil {
+		return nil, err
+	}
+
+	region, _ := environ.Lookup(env, "AWS_REGION")
+	if region == "" {
+		region, _ = environ.Lookup(env, "AWS_DEFAULT_REGION")
+	}
+
+	endpoints := map[string]string{}
+	for service, key := range endpointEnvs {
+		if endpoint, _ := environ.Lookup(env, key); endpoint != "" {
+			endpoints[service] = endpoint
+		} else if endpoint, _ := environ.Lookup(env, "AWS_ENDPOINT_URL"); endpoint != "" {
+			endpoints[service] = endpoint
+		}
+	}
+
+	return &Resolver{Region: region, Endpoints: endpoints, Credentials: credentials, Client: client}, nil
+}
+
+// ResolveEnviron returns env with all secret references replaced by their
+// values. Fails if any of the references can't be resolved.
+func (r *Resolver) ResolveEnviron(ctx context.Context, env []string) ([]string, error) {
+	variables, err := Find(env)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := r.ResolveAll(ctx, variables)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range variables {
+		env = environ.Set(env, v.Name, values[v.Name])
+	}
+
+	return env, nil
+}
+
+// ResolveAll resolves variables, fetching each parameter or secret once.
+// Returns values by variable name.
+func (r *Resolver) ResolveAll(ctx context.Context, variables []Variable) (map[string]string, error) {
+	fetched := map[string]string{}
+	values := make(map[string]string, len(variables))
+
+	for _, v := range variables {
+		ref := v.Reference
+
+		// Key and default do not affect what is fetched.
+		id := (&Reference{Service: ref.Service, Name: ref.Name, Version: ref.Version, Stage: ref.Stage}).String()
+
+		raw, ok := fetched[id]
+		if !ok {
+			var err error
+
+			raw, err = r.fetch(ctx, ref)
+			if err != nil && !(ref.HasDefault && isNotFound(err)) {
+				return nil, fmt.Errorf("failed to resolve %s for %s: %w", ref, v.Name, err)
+			}
+
+			if err != nil {
+				values[v.Name] = ref.Default
+				continue
+			}
+
+			fetched[id] = raw
+		}
+
+		value, err := selectKey(raw, ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s for %s: %w", ref, v.Name, err)
+		}
+
+		values[v.Name] = value
+	}
+
+	return values, nil
+}
+
+func (r *Resolver) fetch(ctx context.Context, ref *Reference) (string, error) {
+	switch ref.Service {
+	case ServiceSSM:
+		return r.getParameter(ctx, ref)
+	default:
+		return r.getSecretValue(ctx, ref)
+	}
+}
+
+type getParameterInput struct {
+	Name           string `json:"Name"`
+	WithDecryption bool   `json:"WithDecryption"`
+}
+
+type getParameterOutput struct {
+	Parameter struct {
+		Value string `json:"Value"`
+	} `json:"Parameter"`
+}
+
+// See: https://docs.aws.amazon.com/systems-manager/latest/APIReference/API_GetParameter.html
+func (r *Resolver) getParameter(ctx context.Context, ref *Reference) (string, error) {
+	input := &getParameterInput{Name: ref.Name, WithDecryption: true}
+	if ref.Version != "" {
+		input.Name += ":" + ref.Version
+	}
+
+	output := &getParameterOutput{}
+	if err := r.call(ctx, ref, "AmazonSSM.GetParameter", input, output); err != nil {
+		return "", err
+	}
+
+	return output.Parameter.Value, nil
+}
+
+type getSecretValueInput struct {
+	SecretID     string `json:"SecretId"`
+	VersionID    string `json:"VersionId,omitempty"`
+	VersionStage string `json:"VersionStage,omitempty"`
+}
+
+type getSecretValueOutput struct {
+	SecretString string `json:"SecretString"`
+	SecretBinary string `json:"SecretBinary"`
+}
+
+// See: https://docs.aws.amazon.com/secretsmanager/latest/apireference/API_GetSecretValue.html
+func (r *Resolver) getSecretValue(ctx context.Context, ref *Reference) (string, error) {
+	input := &getSecretValueInput{SecretID: ref.Name, VersionID: ref.Version, VersionStage: ref.Stage}
+
+	output := &getSecretValueOutput{}
+	if err := r.call(ctx, ref, "secretsmanager.GetSecretValue", input, output); err != nil {
+		return "", err
+	}
+
+	if output.SecretString == "" && output.SecretBinary != "" {
+		data, err := base64.StdEncoding.DecodeString(output.SecretBinary)
+		if err != nil {
+			return "", fmt.Errorf("failed to decode secret binary: %w", err)
+		}
+
+		return string(data), nil
+	}
+
+	return output.SecretString, nil
+}
+
+// call performs AWS JSON 1.1 protocol request.
+func (r *Resolver) call(ctx context.Context, ref *Reference, target string, input any, output any) error {
+	region := r.Region
+	if arn, ok := container_metadata.ParseARN(ref.Name); ok {
+		region = arn.Region
+	}
+
+	if region == "" {
+		return ErrMissingRegion
+	}
+
+	endpoint := r.Endpoints[ref.Service]
+	if endpoint == "" {
+		endpoint = defaultEndpoint(ref.Service, region)
+	}
+
+	body, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s request: %w", target, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to prepare %s request: %w", target, err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", target)
+
+	creds, err := r.Credentials.Retrieve(ctx)
+	if err != nil {
+		return err
+	}
+
+	sigv4.Sign(req, body, creds, region, ref.Service, time.Now())
+
+	res, err := r.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute %s request: %w", target, err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return newAPIError(res)
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(output); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", target, err)
+	}
+
+	return nil
+}
+
+func newAPIError(res *http.Response) error {
+	payload := struct {
+		Type         string `json:"__type"`
+		Message      string `json:"message"`
+		MessageUpper string `json:"Message"`
+	}{}
+
+	data, _ := io.ReadAll(io.LimitReader(res.Body, 64*1024))
+	_ = json.Unmarshal(data, &payload)
+
+	err := &APIError{StatusCode: res.StatusCode, Type: payload.Type, Message: payload.Message}
+	if err.Message == "" {
+		err.Message = payload.MessageUpper
+	}
+
+	if err.Type == "" {
+		err.Type = "UnknownError"
+	}
+
+	return err
+}
+
+func defaultEndpoint(service string, region string) string {
+	if strings.HasPrefix(region, "cn-") {
+		return "https://" + service + "." + region + ".amazonaws.com.cn"
+	}
+
+	return "https://" + service + "." + region + ".amazonaws.com"
+}
+
+func isNotFound(err error) bool {
+	var apiErr *APIError
+
+	return errors.As(err, &apiErr) && apiErr.isNotFound()
+}
+
+// errMissingKey is returned when the selected JSON key does not exist.
+var errMissingKey = errors.New("missing JSON key")
+
+// selectKey returns value of the reference key in raw JSON object, or raw
+// itself if reference has no key. Non-string values are returned as JSON.
+func selectKey(raw string, ref *Reference) (string, error) {
+	if ref.Key == "" {
+		return raw, nil
+	}
+
+	object := map[string]json.RawMessage{}
+	if err := json.Unmarshal([]byte(raw), &object); err != nil {
+		// Never include the error itself, it may quote parts of the value.
+		return "", errors.New("value is not a JSON object")
+	}
+
+	value, ok := object[ref.Key]
+	if !ok {
+		if ref.HasDefault {
+			return ref.Default, nil
+		}
+
+		return "", fmt.Errorf("%w: %s", errMissingKey, ref.Key)
+	}
+
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		return s, nil
+	}
+
+	return string(value), nil
+}
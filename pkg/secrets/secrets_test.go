@@ -0,0 +1,301 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// standIn is a minimal SSM, Secrets Manager and container credentials server.
+type standIn struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	parameters map[string]string
+	secrets    map[string]string
+	calls      atomic.Int32
+}
+
+func newStandIn(t *testing.T) *standIn {
+	s := &standIn{
+		parameters: map[string]string{
+			"/myapp/password": "hunter2",
+			"/myapp/config:2": `{"feature":"on"}`,
+		},
+		secrets: map[string]string{
+			"myapp/db":            `{"username":"admin","password":"s3cr3t","port":5432}`,
+			"myapp/db@AWSPENDING": `{"username":"admin","password":"n3w"}`,
+			"myapp/token":         "t0k3n",
+		},
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /credentials", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "auth-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]any{
+			"AccessKeyId":     "AKIDEXAMPLE",
+			"SecretAccessKey": "secret",
+			"Token":           "session",
+			"Expiration":      time.Now().Add(time.Hour),
+		})
+	})
+
+	mux.HandleFunc("POST /", func(w http.ResponseWriter, r *http.Request) {
+		s.calls.Add(1)
+
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") || r.Header.Get("X-Amz-Security-Token") != "session" {
+			apiError(w, http.StatusForbidden, "UnrecognizedClientException")
+			return
+		}
+
+		input := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&input)
+
+		switch r.Header.Get("X-Amz-Target") {
+		case "AmazonSSM.GetParameter":
+			s.mu.Lock()
+			value, ok := s.parameters[input["Name"]]
+			s.mu.Unlock()
+
+			if !ok {
+				apiError(w, http.StatusBadRequest, "ParameterNotFound")
+				return
+			}
+
+			json.NewEncoder(w).Encode(map[string]any{"Parameter": map[string]string{"Value": value}})
+
+		case "secretsmanager.GetSecretValue":
+			id := input["SecretId"]
+			if input["VersionStage"] != "" {
+				id += "@" + input["VersionStage"]
+			}
+
+			value, ok := s.secrets[id]
+			if !ok {
+				apiError(w, http.StatusBadRequest, "com.amazonaws.secretsmanager#ResourceNotFoundException")
+				return
+			}
+
+			json.NewEncoder(w).Encode(map[string]string{"SecretString": value})
+
+		default:
+			apiError(w, http.StatusBadRequest, "UnknownOperationException")
+		}
+	})
+
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *standIn) setParameter(name string, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.parameters[name] = value
+}
+
+func apiError(w http.ResponseWriter, status int, errorType string) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"__type": errorType, "message": "nope"})
+}
+
+func (s *standIn) environ() []string {
+	return []string{
+		"AWS_REGION=us-west-2",
+		"AWS_ENDPOINT_URL=" + s.URL,
+		"AWS_CONTAINER_CREDENTIALS_FULL_URI=" + s.URL + "/credentials",
+		"AWS_CONTAINER_AUTHORIZATION_TOKEN=auth-token",
+	}
+}
+
+func (s *standIn) resolver(t *testing.T) *Resolver {
+	r, err := NewResolver(s.environ())
+	require.NoError(t, err)
+
+	return r
+}
+
+func TestFind(t *testing.T) {
+	t.Run("with references", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		variables, err := Find([]string{"PATH=/usr/bin", "DB_PASSWORD=secretsmanager://myapp/db#password", "TOKEN=ssm:///token"})
+
+		require.NoError(err)
+		require.Len(variables, 2)
+		assert.Equal("DB_PASSWORD", variables[0].Name)
+		assert.Equal("secretsmanager://myapp/db#password", variables[0].Reference.String())
+		assert.Equal("TOKEN", variables[1].Name)
+	})
+
+	t.Run("with malformed reference", func(t *testing.T) {
+		_, err := Find([]string{"TOKEN=ssm://?version=1"})
+
+		assert.ErrorContains(t, err, "invalid secret reference in TOKEN")
+	})
+}
+
+func TestNewResolver(t *testing.T) {
+	t.Run("with service endpoint overrides", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		r, err := NewResolver([]string{
+			"AWS_DEFAULT_REGION=eu-central-1",
+			"AWS_ACCESS_KEY_ID=AKIDEXAMPLE",
+			"AWS_SECRET_ACCESS_KEY=secret",
+			"AWS_ENDPOINT_URL=http://localhost:4566",
+			"AWS_ENDPOINT_URL_SSM=http://localhost:8080",
+		})
+
+		require.NoError(err)
+		assert.Equal("eu-central-1", r.Region)
+		assert.Equal(map[string]string{
+			ServiceSSM:            "http://localhost:8080",
+			ServiceSecretsManager: "http://localhost:4566",
+		}, r.Endpoints)
+	})
+
+	t.Run("without credentials", func(t *testing.T) {
+		_, err := NewResolver([]string{"AWS_REGION=us-west-2"})
+
+		assert.ErrorIs(t, err, ErrMissingCredentials)
+	})
+
+	t.Run("with insecure credentials endpoint", func(t *testing.T) {
+		_, err := NewResolver([]string{"AWS_CONTAINER_CREDENTIALS_FULL_URI=http://example.com/credentials"})
+
+		assert.ErrorContains(t, err, "neither HTTPS nor loopback")
+	})
+}
+
+func TestResolver_ResolveEnviron(t *testing.T) {
+	t.Run("replaces references", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		s := newStandIn(t)
+
+		env, err := s.resolver(t).ResolveEnviron(context.Background(), []string{
+			"PATH=/usr/bin",
+			"PASSWORD=ssm:///myapp/password",
+			"FEATURE=ssm:///myapp/config?version=2#feature",
+			"DB_USERNAME=secretsmanager://myapp/db#username",
+			"DB_PASSWORD=secretsmanager://myapp/db#password",
+			"DB_PORT=secretsmanager://myapp/db#port",
+			"DB_PENDING=secretsmanager://myapp/db?stage=AWSPENDING#password",
+			"TOKEN=secretsmanager://myapp/token",
+		})
+
+		require.NoError(err)
+		assert.Equal([]string{
+			"PATH=/usr/bin",
+			"PASSWORD=hunter2",
+			"FEATURE=on",
+			"DB_USERNAME=admin",
+			"DB_PASSWORD=s3cr3t",
+			"DB_PORT=5432",
+			"DB_PENDING=n3w",
+			"TOKEN=t0k3n",
+		}, env)
+
+		// Each secret is fetched once regardless of selected keys.
+		assert.EqualValues(5, s.calls.Load())
+	})
+
+	t.Run("with defaults", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		s := newStandIn(t)
+
+		env, err := s.resolver(t).ResolveEnviron(context.Background(), []string{
+			"DB_HOST=secretsmanager://myapp/db#host?default=localhost",
+			"MISSING=ssm:///myapp/missing?default=fallback",
+		})
+
+		require.NoError(err)
+		assert.Equal([]string{"DB_HOST=localhost", "MISSING=fallback"}, env)
+	})
+
+	t.Run("with missing parameter", func(t *testing.T) {
+		s := newStandIn(t)
+
+		_, err := s.resolver(t).ResolveEnviron(context.Background(), []string{"PASSWORD=ssm:///myapp/missing"})
+
+		assert.ErrorContains(t, err, "failed to resolve ssm:///myapp/missing for PASSWORD: ParameterNotFound (status 400)")
+	})
+
+	t.Run("with missing key", func(t *testing.T) {
+		s := newStandIn(t)
+
+		_, err := s.resolver(t).ResolveEnviron(context.Background(), []string{"DB_HOST=secretsmanager://myapp/db#host"})
+
+		assert.ErrorContains(t, err, "missing JSON key: host")
+	})
+
+	t.Run("with key of non-JSON value", func(t *testing.T) {
+		s := newStandIn(t)
+
+		_, err := s.resolver(t).ResolveEnviron(context.Background(), []string{"TOKEN=ssm:///myapp/password#token"})
+
+		assert.ErrorContains(t, err, "value is not a JSON object")
+		assert.NotContains(t, err.Error(), "hunter2")
+	})
+
+	t.Run("with rejected credentials", func(t *testing.T) {
+		s := newStandIn(t)
+
+		r := s.resolver(t)
+		r.Credentials = staticCredentials{AccessKeyID: "AKIDOTHER", SecretAccessKey: "secret"}
+
+		_, err := r.ResolveEnviron(context.Background(), []string{"PASSWORD=ssm:///myapp/password"})
+
+		assert.ErrorContains(t, err, "UnrecognizedClientException (status 403)")
+	})
+
+	t.Run("without region", func(t *testing.T) {
+		s := newStandIn(t)
+
+		r := s.resolver(t)
+		r.Region = ""
+
+		_, err := r.ResolveEnviron(context.Background(), []string{"PASSWORD=ssm:///myapp/password"})
+
+		assert.ErrorIs(t, err, ErrMissingRegion)
+	})
+
+	t.Run("without references", func(t *testing.T) {
+		r := &Resolver{}
+
+		env, err := r.ResolveEnviron(context.Background(), []string{"PATH=/usr/bin"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"PATH=/usr/bin"}, env)
+	})
+}
+
+func TestDefaultEndpoint(t *testing.T) {
+	assert.Equal(t, "https://ssm.us-west-2.amazonaws.com", defaultEndpoint(ServiceSSM, "us-west-2"))
+	assert.Equal(t, "https://secretsmanager.cn-north-1.amazonaws.com.cn", defaultEndpoint(ServiceSecretsManager, "cn-north-1"))
+}
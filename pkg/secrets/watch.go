@@ -0,0 +1,53 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package secrets
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"time"
+)
+
+// Watch re-resolves variables every interval until ctx is done. Whenever any
+// value differs from values, onChange is called with all values and names of
+// the changed variables. Resolution errors are logged and retried on the
+// next interval. Values are never logged.
+func (r *Resolver) Watch(ctx context.Context, variables []Variable, values map[string]string, interval time.Duration, onChange func(values map[string]string, changed []string)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	values = maps.Clone(values)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		next, err := r.ResolveAll(ctx, variables)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Warn("Can't refresh secrets", "error", err)
+			}
+
+			continue
+		}
+
+		var changed []string
+
+		for _, v := range variables {
+			if next[v.Name] != values[v.Name] {
+				slog.Info("Secret changed", "variable", v.Name, "reference", v.Reference)
+				changed = append(changed, v.Name)
+			}
+		}
+
+		if len(changed) > 0 {
+			values = next
+			onChange(maps.Clone(values), changed)
+		}
+	}
+}
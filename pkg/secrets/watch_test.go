@@ -0,0 +1,63 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package secrets
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolver_Watch(t *testing.T) {
+	t.Run("reports changed values", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		s := newStandIn(t)
+		variables, err := Find([]string{"PASSWORD=ssm:///myapp/password", "TOKEN=secretsmanager://myapp/token"})
+		require.NoError(err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		type change struct {
+			values  map[string]string
+			changed []string
+		}
+
+		changes := make(chan change, 1)
+
+		go s.resolver(t).Watch(ctx, variables, map[string]string{"PASSWORD": "hunter2", "TOKEN": "t0k3n"}, 10*time.Millisecond, func(values map[string]string, changed []string) {
+			changes <- change{values, changed}
+		})
+
+		s.setParameter("/myapp/password", "hunter3")
+
+		select {
+		case c := <-changes:
+			assert.Equal(map[string]string{"PASSWORD": "hunter3", "TOKEN": "t0k3n"}, c.values)
+			assert.Equal([]string{"PASSWORD"}, c.changed)
+		case <-time.After(time.Second):
+			t.Fatal("change was not reported")
+		}
+	})
+
+	t.Run("keeps values on resolution errors", func(t *testing.T) {
+		s := newStandIn(t)
+		variables, err := Find([]string{"PASSWORD=ssm:///myapp/missing"})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		s.resolver(t).Watch(ctx, variables, map[string]string{"PASSWORD": "hunter2"}, 10*time.Millisecond, func(map[string]string, []string) {
+			t.Error("unexpected change")
+		})
+
+		assert.Greater(t, s.calls.Load(), int32(1))
+	})
+}
@@ -0,0 +1,74 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+// Package shellwords splits command lines into arguments the way POSIX shell
+// does, without performing any expansions. It allows accepting commands as
+// single strings in images that have no shell.
+package shellwords
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrUnterminatedQuote = errors.New("unterminated quote")
+
+// Split splits s into words. Words are separated by unquoted whitespace.
+// Single quotes preserve everything literally, double quotes allow escaping
+// of `"`, `\` and `$` with a backslash, and unquoted backslash escapes any
+// character.
+func Split(s string) ([]string, error) {
+	var (
+		words  []string
+		word   strings.Builder
+		inWord bool
+		quote  rune
+		escape bool
+	)
+
+	for _, r := range s {
+		switch {
+		case escape:
+			if quote == '"' && !strings.ContainsRune(`"\$`, r) {
+				word.WriteRune('\\')
+			}
+
+			word.WriteRune(r)
+			escape = false
+
+		case r == '\\' && quote != '\'':
+			escape, inWord = true, true
+
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 || escape {
+		return nil, ErrUnterminatedQuote
+	}
+
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words, nil
+}
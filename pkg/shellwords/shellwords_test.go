@@ -0,0 +1,51 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package shellwords
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplit(t *testing.T) {
+	valid := []struct {
+		input    string
+		expected []string
+	}{
+		{"", nil},
+		{"   ", nil},
+		{"sleep 5", []string{"sleep", "5"}},
+		{"  curl \t -s\nhttp://localhost  ", []string{"curl", "-s", "http://localhost"}},
+		{`echo 'hello world'`, []string{"echo", "hello world"}},
+		{`echo "hello world"`, []string{"echo", "hello world"}},
+		{`echo ''`, []string{"echo", ""}},
+		{`echo "it's"`, []string{"echo", "it's"}},
+		{`echo 'say "hi"'`, []string{"echo", `say "hi"`}},
+		{`echo "say \"hi\""`, []string{"echo", `say "hi"`}},
+		{`echo "a\nb"`, []string{"echo", `a\nb`}},
+		{`echo 'a\b'`, []string{"echo", `a\b`}},
+		{`echo a\ b`, []string{"echo", "a b"}},
+		{`echo pre"mid"'post'`, []string{"echo", "premidpost"}},
+		{`echo $HOME`, []string{"echo", "$HOME"}},
+	}
+
+	for _, tc := range valid {
+		t.Run(tc.input, func(t *testing.T) {
+			words, err := Split(tc.input)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, words)
+		})
+	}
+
+	for _, input := range []string{`echo 'hello`, `echo "hello`, `echo hello\`} {
+		t.Run(input, func(t *testing.T) {
+			_, err := Split(input)
+
+			assert.ErrorIs(t, err, ErrUnterminatedQuote)
+		})
+	}
+}
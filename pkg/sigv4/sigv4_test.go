@@ -0,0 +1,90 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package sigv4
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test vectors are from the AWS Signature Version 4 test suite.
+var (
+	testCredentials = Credentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}
+
+	testTime = time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+)
+
+func TestSign(t *testing.T) {
+	t.Run("get-vanilla", func(t *testing.T) {
+		require := require.New(t)
+
+		req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+		require.NoError(err)
+
+		Sign(req, nil, testCredentials, "us-east-1", "service", testTime)
+
+		assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+		assert.Equal(t,
+			"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+			req.Header.Get("Authorization"),
+		)
+	})
+
+	t.Run("get-vanilla-query-order-key-case", func(t *testing.T) {
+		require := require.New(t)
+
+		req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/?Param2=value2&Param1=value1", nil)
+		require.NoError(err)
+
+		Sign(req, nil, testCredentials, "us-east-1", "service", testTime)
+
+		assert.Equal(t,
+			"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+			req.Header.Get("Authorization"),
+		)
+	})
+
+	t.Run("post-x-www-form-urlencoded", func(t *testing.T) {
+		require := require.New(t)
+
+		body := []byte("Param1=value1")
+		req, err := http.NewRequest(http.MethodPost, "https://example.amazonaws.com/", strings.NewReader(string(body)))
+		require.NoError(err)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		Sign(req, body, testCredentials, "us-east-1", "service", testTime)
+
+		assert.Equal(t,
+			"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+			req.Header.Get("Authorization"),
+		)
+	})
+
+	t.Run("with session token", func(t *testing.T) {
+		require := require.New(t)
+
+		req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+		require.NoError(err)
+
+		creds := testCredentials
+		creds.SessionToken = "token"
+
+		Sign(req, nil, creds, "us-east-1", "service", testTime)
+
+		assert.Equal(t, "token", req.Header.Get("X-Amz-Security-Token"))
+		assert.Contains(t, req.Header.Get("Authorization"), "SignedHeaders=host;x-amz-date;x-amz-security-token,")
+	})
+}
+
+func TestEscape(t *testing.T) {
+	assert.Equal(t, "AZaz09-_.~%20%2F%3D%2B", escape("AZaz09-_.~ /=+"))
+}
@@ -0,0 +1,253 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// Process is a named process run by Group.
+type Process struct {
+	Name string
+	Command
+	// Essential process exiting stops the whole group.
+	Essential bool
+	// Stdout and Stderr of the process. Default to those of the supervisor.
+	// Output is copied through a pipe, unless it's an *os.File.
+	Stdout, Stderr io.Writer
+}
+
+type GroupConfig struct {
+	Processes []Process
+	// Env holds environment of all processes in the "KEY=value" form.
+	Env []string
+	// StopSignal is sent to all process groups on SIGTERM, or once an
+	// essential process exits. Defaults to SIGTERM.
+	StopSignal syscall.Signal
+	// KillAfter is the deadline for processes to exit since the stop signal
+	// was sent. Zero disables escalation to SIGKILL.
+	KillAfter time.Duration
+}
+
+// GroupResult is the result of the essential process that exited first, or
+// of the last process to exit if no essential process did.
+type GroupResult struct {
+	Result
+	// Name of the process, and whether it's essential.
+	Name      string
+	Essential bool
+}
+
+// Group runs several processes side by side, each in its own process group.
+// Signals received by the supervisor are forwarded to all of them, and
+// orphaned descendants are reaped.
+type Group struct {
+	config GroupConfig
+
+	running  map[int]*Process
+	started  map[int]time.Time
+	stopping bool
+	kill     <-chan time.Time
+	outputs  outputs
+}
+
+func NewGroup(config GroupConfig) *Group {
+	return &Group{config: config, running: map[int]*Process{}, started: map[int]time.Time{}}
+}
+
+// Config returns configuration the group was created with.
+func (g *Group) Config() GroupConfig {
+	return g.config
+}
+
+// Run starts all processes and supervises them until all of them exit.
+// Failure to start a process stops those already started. Cancelling ctx
+// stops the group, same as SIGTERM.
+func (g *Group) Run(ctx context.Context) (*GroupResult, error) {
+	if err := setSubreaper(); err != nil {
+		slog.Warn("Can't become child subreaper", "error", err)
+	}
+
+	signals := make(chan os.Signal, 32)
+	signal.Notify(signals, forwardedSignals...)
+	defer signal.Stop(signals)
+
+	children := make(chan os.Signal, 1)
+	signal.Notify(children, unix.SIGCHLD)
+	defer signal.Stop(children)
+
+	defer g.outputs.drain()
+
+	var startErr error
+
+	for i := range g.config.Processes {
+		if startErr = g.start(&g.config.Processes[i]); startErr != nil {
+			g.stop()
+			break
+		}
+	}
+
+	var result *GroupResult
+
+	done := ctx.Done()
+
+	for len(g.running) > 0 {
+		select {
+		case sig := <-signals:
+			if received := sig.(syscall.Signal); received == unix.SIGTERM {
+				g.stop()
+			} else {
+				g.forward(received)
+			}
+
+		case <-done:
+			done = nil
+			g.stop()
+
+		case <-g.kill:
+			slog.Warn("Processes did not stop in time, killing", "kill_after", g.config.KillAfter)
+			g.forward(unix.SIGKILL)
+
+		case <-children:
+			for _, exited := range g.reap() {
+				if result == nil || !result.Essential {
+					result = exited
+				}
+
+				if exited.Essential && !g.stopping {
+					slog.Info("Essential process exited, stopping the rest", "process", exited.Name)
+					g.stop()
+				}
+			}
+		}
+	}
+
+	if startErr != nil {
+		return nil, startErr
+	}
+
+	return result, nil
+}
+
+// forward sends sig to all process groups.
+func (g *Group) forward(sig syscall.Signal) {
+	slog.Debug("Forwarding signal", "signal", unix.SignalName(sig))
+
+	for pid, p := range g.running {
+		if err := unix.Kill(-pid, sig); err != nil && !errors.Is(err, unix.ESRCH) {
+			slog.Warn("Can't forward signal", "process", p.Name, "signal", unix.SignalName(sig), "error", err)
+		}
+	}
+}
+
+// stop sends the stop signal to all processes, unless they're already being
+// stopped, and arms the kill deadline.
+func (g *Group) stop() {
+	if g.stopping {
+		slog.Debug("Stop is already in progress")
+		return
+	}
+
+	g.stopping = true
+
+	if g.config.KillAfter > 0 {
+		g.kill = time.After(g.config.KillAfter)
+	}
+
+	sig := g.config.StopSignal
+	if sig == 0 {
+		sig = unix.SIGTERM
+	}
+
+	g.forward(sig)
+}
+
+func (g *Group) start(p *Process) error {
+	stdin, err := os.Open(os.DevNull)
+	if err != nil {
+		return fmt.Errorf("failed to start %s: %w", p.Name, err)
+	}
+
+	defer stdin.Close()
+
+	stdout, err := g.outputs.file(p.Stdout, os.Stdout)
+	if err != nil {
+		return fmt.Errorf("failed to start %s: %w", p.Name, err)
+	}
+
+	defer closeOwned(stdout, p.Stdout)
+
+	stderr, err := g.outputs.file(p.Stderr, os.Stderr)
+	if err != nil {
+		return fmt.Errorf("failed to start %s: %w", p.Name, err)
+	}
+
+	defer closeOwned(stderr, p.Stderr)
+
+	proc, err := os.StartProcess(p.Path, p.Args, &os.ProcAttr{
+		Env:   g.config.Env,
+		Files: []*os.File{stdin, stdout, stderr},
+		Sys:   &syscall.SysProcAttr{Setpgid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start %s: %w", p.Name, err)
+	}
+
+	g.running[proc.Pid], g.started[proc.Pid] = p, time.Now()
+
+	// Processes are waited for with wait4(2) by reap, not via os.Process.
+	proc.Release()
+
+	slog.Debug("Started process", "process", p.Name, "pid", proc.Pid)
+
+	return nil
+}
+
+// reap collects all exited children, and returns results of the processes
+// of the group among them.
+func (g *Group) reap() []*GroupResult {
+	var results []*GroupResult
+
+	for {
+		var (
+			status unix.WaitStatus
+			rusage unix.Rusage
+		)
+
+		pid, err := unix.Wait4(-1, &status, unix.WNOHANG, &rusage)
+		if errors.Is(err, unix.EINTR) {
+			continue
+		}
+
+		if err != nil || pid <= 0 {
+			return results
+		}
+
+		p, ok := g.running[pid]
+		if !ok {
+			slog.Debug("Reaped orphaned process", "pid", pid)
+			continue
+		}
+
+		result := &GroupResult{Result: *newResult(status), Name: p.Name, Essential: p.Essential}
+		result.Usage = newUsage(&rusage, g.started[pid])
+
+		delete(g.running, pid)
+		delete(g.started, pid)
+
+		results = append(results, result)
+
+		slog.Info("Process exited", "process", p.Name, "exit_code", result.ExitCode)
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package supervisor
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func shellProcess(name string, script string, essential bool) Process {
+	return Process{
+		Name:      name,
+		Command:   Command{Path: "/bin/sh", Args: []string{"sh", "-c", script}},
+		Essential: essential,
+	}
+}
+
+// withoutGroupUsage returns copy of result without usage, which varies
+// between runs.
+func withoutGroupUsage(result *GroupResult) *GroupResult {
+	status := *result
+	status.Usage = Usage{}
+
+	return &status
+}
+
+func TestGroup_Run(t *testing.T) {
+	env := []string{"PATH=/usr/bin:/bin"}
+
+	t.Run("with essential process exiting stops the rest", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		started := time.Now()
+
+		result, err := NewGroup(GroupConfig{
+			Processes: []Process{
+				shellProcess("web", "exec sleep 10", true),
+				shellProcess("worker", "exit 3", true),
+			},
+			Env: env,
+		}).Run(context.Background())
+
+		require.NoError(err)
+		assert.Equal(&GroupResult{Result: Result{ExitCode: 3}, Name: "worker", Essential: true}, withoutGroupUsage(result))
+		assert.Less(time.Since(started), 5*time.Second)
+	})
+
+	t.Run("with non-essential process exiting keeps the rest", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		result, err := NewGroup(GroupConfig{
+			Processes: []Process{
+				shellProcess("web", "sleep 0.2; exit 4", true),
+				shellProcess("shipper", "exit 1", false),
+			},
+			Env: env,
+		}).Run(context.Background())
+
+		require.NoError(err)
+		assert.Equal(&GroupResult{Result: Result{ExitCode: 4}, Name: "web", Essential: true}, withoutGroupUsage(result))
+	})
+
+	t.Run("without essential processes waits for all", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		result, err := NewGroup(GroupConfig{
+			Processes: []Process{
+				shellProcess("a", "exit 1", false),
+				shellProcess("b", "sleep 0.2; exit 2", false),
+			},
+			Env: env,
+		}).Run(context.Background())
+
+		require.NoError(err)
+		assert.Equal(&GroupResult{Result: Result{ExitCode: 2}, Name: "b"}, withoutGroupUsage(result))
+		assert.GreaterOrEqual(result.Usage.Runtime, 200*time.Millisecond)
+	})
+
+	t.Run("copies output to writers", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var stdout, stderr bytes.Buffer
+
+		web := shellProcess("web", `echo "out $GREETING"; echo err >&2`, true)
+		web.Stdout, web.Stderr = &stdout, &stderr
+
+		_, err := NewGroup(GroupConfig{
+			Processes: []Process{web},
+			Env:       append(env, "GREETING=hello"),
+		}).Run(context.Background())
+
+		require.NoError(err)
+		assert.Equal("out hello\n", stdout.String())
+		assert.Equal("err\n", stderr.String())
+	})
+
+	t.Run("forwards received signals to all processes", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		dir := t.TempDir()
+		script := `trap 'echo $0 > ` + dir + `/$0; exit 0' USR1; touch ` + dir + `/$0.ready; while :; do sleep 0.01; done`
+
+		processes := []Process{}
+		for _, name := range []string{"a", "b"} {
+			p := shellProcess(name, script, false)
+			p.Args = append(p.Args, name)
+			processes = append(processes, p)
+		}
+
+		go func() {
+			for _, name := range []string{"a", "b"} {
+				for {
+					if _, err := os.Stat(filepath.Join(dir, name+".ready")); err == nil {
+						break
+					}
+
+					time.Sleep(10 * time.Millisecond)
+				}
+			}
+
+			unix.Kill(os.Getpid(), unix.SIGUSR1)
+		}()
+
+		result, err := NewGroup(GroupConfig{Processes: processes, Env: env}).Run(context.Background())
+
+		require.NoError(err)
+		assert.Equal(0, result.ExitCode)
+
+		for _, name := range []string{"a", "b"} {
+			data, err := os.ReadFile(filepath.Join(dir, name))
+
+			require.NoError(err)
+			assert.Equal(name+"\n", string(data))
+		}
+	})
+
+	t.Run("with cancelled context stops all processes", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		result, err := NewGroup(GroupConfig{
+			Processes: []Process{
+				shellProcess("a", "exec sleep 10", true),
+				shellProcess("b", "exec sleep 10", false),
+			},
+			Env:        env,
+			StopSignal: unix.SIGINT,
+		}).Run(ctx)
+
+		require.NoError(err)
+		assert.Equal(130, result.ExitCode)
+		assert.Equal(unix.SIGINT, result.Signal)
+	})
+
+	t.Run("kills processes not stopped in time", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		started := time.Now()
+
+		result, err := NewGroup(GroupConfig{
+			Processes: []Process{
+				shellProcess("stubborn", "trap '' TERM; while :; do sleep 0.01; done", true),
+			},
+			Env:       env,
+			KillAfter: 100 * time.Millisecond,
+		}).Run(ctx)
+
+		require.NoError(err)
+		assert.Equal(unix.SIGKILL, result.Signal)
+		assert.Less(time.Since(started), 5*time.Second)
+	})
+
+	t.Run("with missing executable stops started processes", func(t *testing.T) {
+		assert := assert.New(t)
+
+		started := time.Now()
+
+		missing := Process{Name: "missing", Command: Command{Path: "/nonexistent", Args: []string{"nonexistent"}}, Essential: true}
+
+		_, err := NewGroup(GroupConfig{
+			Processes: []Process{shellProcess("web", "exec sleep 10", true), missing},
+			Env:       env,
+		}).Run(context.Background())
+
+		assert.ErrorContains(err, "failed to start missing")
+		assert.Less(time.Since(started), 5*time.Second)
+	})
+
+	t.Run("reaps orphaned processes", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var stdout bytes.Buffer
+
+		web := shellProcess("web", "sh -c 'exit 0' & wait; echo done", true)
+		web.Stdout = &stdout
+
+		result, err := NewGroup(GroupConfig{Processes: []Process{web}, Env: env}).Run(context.Background())
+
+		require.NoError(err)
+		assert.Equal(0, result.ExitCode)
+		assert.Equal("done", strings.TrimSpace(stdout.String()))
+	})
+}
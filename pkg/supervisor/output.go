@@ -0,0 +1,75 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package supervisor
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// outputDrainTimeout is how long to wait for output of exited processes to
+// be copied. Their descendants may keep the pipes open.
+const outputDrainTimeout = 1 * time.Second
+
+// outputs copies output of processes to writers through pipes.
+type outputs struct {
+	// pipes holds read ends of the pipes being copied.
+	pipes   []*os.File
+	copying sync.WaitGroup
+}
+
+// file returns file for processes to write their output to: w itself if it's
+// a file, def if w is nil, or write end of a pipe copied to w otherwise.
+// The write end is to be closed with closeOwned.
+func (o *outputs) file(w io.Writer, def *os.File) (*os.File, error) {
+	if w == nil {
+		return def, nil
+	}
+
+	if f, ok := w.(*os.File); ok {
+		return f, nil
+	}
+
+	r, pw, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+
+	o.pipes = append(o.pipes, r)
+	o.copying.Go(func() { io.Copy(w, r) })
+
+	return pw, nil
+}
+
+// drain waits for output to be copied, and closes the pipes.
+func (o *outputs) drain() {
+	copied := make(chan struct{})
+
+	go func() {
+		o.copying.Wait()
+		close(copied)
+	}()
+
+	select {
+	case <-copied:
+	case <-time.After(outputDrainTimeout):
+		slog.Debug("Output is still open, closing", "timeout", outputDrainTimeout)
+	}
+
+	for _, r := range o.pipes {
+		r.Close()
+	}
+
+	o.copying.Wait()
+}
+
+// closeOwned closes f returned by file for w, if it's the write end of a pipe.
+func closeOwned(f *os.File, w io.Writer) {
+	if _, ok := w.(*os.File); !ok && w != nil {
+		f.Close()
+	}
+}
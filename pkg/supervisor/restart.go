@@ -0,0 +1,131 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package supervisor
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// RestartPolicy defines when the child is restarted after it exits.
+type RestartPolicy string
+
+const (
+	// RestartNever never restarts the child. This is the default.
+	RestartNever RestartPolicy = "never"
+	// RestartOnFailure restarts the child if it exits with non-zero status
+	// or is killed by a signal.
+	RestartOnFailure RestartPolicy = "on-failure"
+	// RestartAlways restarts the child whenever it exits.
+	RestartAlways RestartPolicy = "always"
+)
+
+// ParseRestartPolicy parses restart policy name.
+func ParseRestartPolicy(s string) (RestartPolicy, error) {
+	switch policy := RestartPolicy(s); policy {
+	case RestartNever, RestartOnFailure, RestartAlways:
+		return policy, nil
+	}
+
+	return "", fmt.Errorf("unknown restart policy: %s", s)
+}
+
+// RestartConfig describes when and how the child is restarted after it exits
+// on its own. Children exiting due to the stop sequence are never restarted.
+type RestartConfig struct {
+	Policy RestartPolicy
+	// MaxRestarts within Window, after which the supervisor gives up and
+	// exits with the status of the child. Zero for unlimited.
+	MaxRestarts int
+	// Window restarts are counted within. Zero counts all restarts.
+	Window time.Duration
+	// Backoff is the delay before restart, doubled for each restart within
+	// Window, up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Prepare returns environment to restart the child with, given the number
+	// of the restart, starting from 1, and the current environment. Optional.
+	Prepare func(attempt int, env []string) []string
+}
+
+// shouldRestart reports whether the policy restarts the child with result.
+func (c RestartConfig) shouldRestart(result *Result) bool {
+	switch c.Policy {
+	case RestartAlways:
+		return true
+	case RestartOnFailure:
+		return result.ExitCode != 0
+	default:
+		return false
+	}
+}
+
+// scheduleRestart returns delay before the child exited with result should
+// be restarted, or false if it should not be restarted.
+func (s *Supervisor) scheduleRestart(result *Result) (time.Duration, bool) {
+	config := s.config.Restart
+
+	s.mu.Lock()
+	stopping := s.stopping
+	s.mu.Unlock()
+
+	if stopping || !config.shouldRestart(result) {
+		return 0, false
+	}
+
+	now := time.Now()
+
+	if config.Window > 0 {
+		s.restarts = slices.DeleteFunc(s.restarts, func(t time.Time) bool {
+			return now.Sub(t) >= config.Window
+		})
+	}
+
+	if config.MaxRestarts > 0 && len(s.restarts) >= config.MaxRestarts {
+		slog.Error(
+			"Child process keeps failing, giving up",
+			"exit_code", result.ExitCode,
+			"restarts", len(s.restarts),
+			"window", config.Window,
+		)
+
+		return 0, false
+	}
+
+	delay := config.Backoff
+	for range s.restarts {
+		if config.MaxBackoff > 0 && delay >= config.MaxBackoff {
+			break
+		}
+
+		delay *= 2
+	}
+
+	if config.MaxBackoff > 0 {
+		delay = min(delay, config.MaxBackoff)
+	}
+
+	s.restarts = append(s.restarts, now)
+
+	return delay, true
+}
+
+// restart starts the child again, with environment prepared for the attempt.
+func (s *Supervisor) restart() error {
+	s.attempt++
+
+	if prepare := s.config.Restart.Prepare; prepare != nil {
+		env := prepare(s.attempt, s.Config().Env)
+
+		s.mu.Lock()
+		s.config.Env = env
+		s.mu.Unlock()
+	}
+
+	slog.Info("Restarting child process", "attempt", s.attempt)
+
+	return s.start()
+}
@@ -0,0 +1,11 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package supervisor
+
+import "golang.org/x/sys/unix"
+
+// maxRSS returns maximum resident set size in bytes. Linux reports kilobytes.
+func maxRSS(rusage *unix.Rusage) int64 {
+	return rusage.Maxrss * 1024
+}
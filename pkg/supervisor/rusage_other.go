@@ -0,0 +1,13 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+//go:build !linux
+
+package supervisor
+
+import "golang.org/x/sys/unix"
+
+// maxRSS returns maximum resident set size in bytes. macOS reports bytes.
+func maxRSS(rusage *unix.Rusage) int64 {
+	return rusage.Maxrss
+}
@@ -0,0 +1,88 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package supervisor
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// forwardedSignals lists catchable signals relayed to the child.
+// SIGCHLD is handled by the supervisor itself, synchronous signals (SIGSEGV,
+// SIGBUS, etc.) are meaningless to relay, SIGPIPE and terminal I/O signals
+// (SIGTTIN, SIGTTOU) only concern the supervisor itself, and SIGURG and
+// SIGPROF are used by the Go runtime.
+var forwardedSignals = []os.Signal{
+	unix.SIGHUP,
+	unix.SIGINT,
+	unix.SIGQUIT,
+	unix.SIGTERM,
+	unix.SIGUSR1,
+	unix.SIGUSR2,
+	unix.SIGALRM,
+	unix.SIGVTALRM,
+	unix.SIGCONT,
+	unix.SIGTSTP,
+	unix.SIGWINCH,
+	unix.SIGIO,
+	unix.SIGXCPU,
+	unix.SIGXFSZ,
+}
+
+// ParseSignal parses signal name, with or without SIG prefix, or number.
+// E.g. "TERM", "SIGTERM", "term" and "15" all yield SIGTERM.
+func ParseSignal(s string) (syscall.Signal, error) {
+	if n, err := strconv.Atoi(s); err == nil {
+		if unix.SignalName(syscall.Signal(n)) == "" {
+			return 0, fmt.Errorf("unknown signal: %s", s)
+		}
+
+		return syscall.Signal(n), nil
+	}
+
+	name := strings.ToUpper(s)
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+
+	if sig := unix.SignalNum(name); sig != 0 {
+		return sig, nil
+	}
+
+	return 0, fmt.Errorf("unknown signal: %s", s)
+}
+
+// ParseSignalMapping parses "FROM:TO" signal mapping, e.g. "TERM:QUIT".
+// Target "DROP" yields zero signal, meaning FROM is to be ignored.
+func ParseSignalMapping(s string) (from syscall.Signal, to syscall.Signal, err error) {
+	source, target, ok := strings.Cut(s, ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid signal mapping: %s", s)
+	}
+
+	from, err = ParseSignal(source)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if from == unix.SIGKILL || from == unix.SIGSTOP || from == unix.SIGCHLD {
+		return 0, 0, fmt.Errorf("signal can't be mapped: %s", source)
+	}
+
+	if strings.EqualFold(target, "DROP") {
+		return from, 0, nil
+	}
+
+	to, err = ParseSignal(target)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return from, to, nil
+}
@@ -0,0 +1,72 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package supervisor
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestParseSignal(t *testing.T) {
+	for _, input := range []string{"TERM", "SIGTERM", "term", "sigterm", "15"} {
+		t.Run(input, func(t *testing.T) {
+			sig, err := ParseSignal(input)
+
+			require.NoError(t, err)
+			assert.Equal(t, unix.SIGTERM, sig)
+		})
+	}
+
+	for _, input := range []string{"", "SIG", "BOGUS", "SIGBOGUS", "0", "-1", "1000"} {
+		t.Run("invalid "+input, func(t *testing.T) {
+			_, err := ParseSignal(input)
+
+			assert.ErrorContains(t, err, "unknown signal")
+		})
+	}
+}
+
+func TestParseSignalMapping(t *testing.T) {
+	t.Run("with signal target", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		from, to, err := ParseSignalMapping("TERM:SIGQUIT")
+
+		require.NoError(err)
+		assert.Equal(unix.SIGTERM, from)
+		assert.Equal(unix.SIGQUIT, to)
+	})
+
+	t.Run("with drop target", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		from, to, err := ParseSignalMapping("hup:drop")
+
+		require.NoError(err)
+		assert.Equal(unix.SIGHUP, from)
+		assert.Equal(syscall.Signal(0), to)
+	})
+
+	for input, message := range map[string]string{
+		"TERM":       "invalid signal mapping: TERM",
+		"TERM:":      "unknown signal: ",
+		":QUIT":      "unknown signal: ",
+		"BOGUS:QUIT": "unknown signal: BOGUS",
+		"TERM:BOGUS": "unknown signal: BOGUS",
+		"KILL:TERM":  "signal can't be mapped: KILL",
+		"CHLD:TERM":  "signal can't be mapped: CHLD",
+	} {
+		t.Run("invalid "+input, func(t *testing.T) {
+			_, _, err := ParseSignalMapping(input)
+
+			assert.ErrorContains(t, err, message)
+		})
+	}
+}
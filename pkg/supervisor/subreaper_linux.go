@@ -0,0 +1,12 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package supervisor
+
+import "golang.org/x/sys/unix"
+
+// setSubreaper makes orphaned descendants reparent to the supervisor instead
+// of PID 1, so they can be reaped even when the supervisor is not PID 1.
+func setSubreaper() error {
+	return unix.Prctl(unix.PR_SET_CHILD_SUBREAPER, 1, 0, 0, 0)
+}
@@ -0,0 +1,11 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+//go:build !linux
+
+package supervisor
+
+// setSubreaper is a no-op: child subreapers are Linux-specific.
+func setSubreaper() error {
+	return nil
+}
@@ -0,0 +1,518 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+// Package supervisor runs a child process the way a container init does:
+// the child gets its own process group, signals received by the supervisor
+// are forwarded to that group, and orphaned descendants are reaped.
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+var (
+	ErrNotRunning = errors.New("child process is not running")
+	ErrStopping   = errors.New("supervisor is stopping")
+)
+
+type Config struct {
+	// Path is the resolved path of the executable.
+	Path string
+	// Args holds command line arguments, including the command as Args[0].
+	Args []string
+	// Env holds environment of the child in the "KEY=value" form.
+	Env []string
+	// Stdin of the child. Defaults to that of the supervisor.
+	Stdin *os.File
+	// Stdout and Stderr of the child and hooks. Default to those of the
+	// supervisor. Output is copied through a pipe, unless it's an *os.File.
+	Stdout, Stderr io.Writer
+	// Stop configures what happens when the supervisor receives SIGTERM.
+	Stop StopConfig
+	// SignalMap rewrites received signals before they are forwarded.
+	// Signals mapped to zero are dropped. SIGTERM mapped to another signal
+	// still triggers the stop sequence.
+	SignalMap map[syscall.Signal]syscall.Signal
+	// Credential, if set, is the user and groups to run the child and hooks
+	// as. Changing them requires the supervisor to run as root.
+	Credential *syscall.Credential
+	// Restart configures restarts of the child exiting on its own.
+	Restart RestartConfig
+}
+
+// Command is an auxiliary command run by the supervisor, e.g. a hook.
+type Command struct {
+	Path string
+	Args []string
+}
+
+// StopConfig describes graceful stop sequence, triggered by SIGTERM:
+// run PreStop hook, wait for Delay, send Signal to the child process group,
+// and send SIGKILL if the child is still running KillAfter since SIGTERM.
+type StopConfig struct {
+	// PreStop hook is run with the child environment. Optional.
+	PreStop *Command
+	// Delay before the stop signal is sent, e.g. to let load balancers drain.
+	Delay time.Duration
+	// Signal sent to the child process group. Defaults to SIGTERM.
+	Signal syscall.Signal
+	// KillAfter is the deadline for the child to exit since the stop sequence
+	// was triggered. Zero disables escalation to SIGKILL.
+	KillAfter time.Duration
+}
+
+type Result struct {
+	// ExitCode is the exit status of the child, or 128+signal if the child
+	// was killed by a signal.
+	ExitCode int
+	// Signal that killed the child, if any.
+	Signal syscall.Signal
+	// Usage of resources by the child and its descendants it waited for.
+	Usage Usage
+}
+
+// Usage is resource usage of an exited process.
+type Usage struct {
+	// Runtime is the wall clock time since the process was started.
+	Runtime time.Duration
+	// UserTime and SystemTime are CPU times spent in user and kernel mode.
+	UserTime   time.Duration
+	SystemTime time.Duration
+	// MaxRSS is the maximum resident set size in bytes.
+	MaxRSS int64
+}
+
+type Supervisor struct {
+	config Config
+
+	mu         sync.Mutex
+	pid        int
+	waiters    map[int]chan unix.WaitStatus
+	stopping   bool
+	restarting bool
+	restartEnv []string
+
+	// restarts holds times of restarts by the policy within the window.
+	restarts []time.Time
+	attempt  int
+
+	// stdout and stderr are files the child and hooks write their output to.
+	stdout, stderr *os.File
+	outputs        outputs
+
+	// started is when the current child was started.
+	started time.Time
+}
+
+func New(config Config) *Supervisor {
+	return &Supervisor{config: config, waiters: map[int]chan unix.WaitStatus{}}
+}
+
+// Config returns configuration the supervisor was created with.
+func (s *Supervisor) Config() Config {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.config
+}
+
+// Run starts the child and supervises it until it exits.
+// Cancelling ctx triggers the stop sequence, same as SIGTERM.
+func (s *Supervisor) Run(ctx context.Context) (*Result, error) {
+	if err := setSubreaper(); err != nil {
+		slog.Warn("Can't become child subreaper", "error", err)
+	}
+
+	signals := make(chan os.Signal, 32)
+	signal.Notify(signals, forwardedSignals...)
+	for sig := range s.config.SignalMap {
+		signal.Notify(signals, sig)
+	}
+	defer signal.Stop(signals)
+
+	children := make(chan os.Signal, 1)
+	signal.Notify(children, unix.SIGCHLD)
+	defer signal.Stop(children)
+
+	if err := s.openOutputs(); err != nil {
+		return nil, err
+	}
+
+	defer s.closeOutputs()
+
+	if err := s.start(); err != nil {
+		return nil, err
+	}
+
+	stopCtx, cancelStop := context.WithCancel(context.Background())
+	defer cancelStop()
+
+	done := ctx.Done()
+
+	// Result of the exited child while waiting to restart it.
+	var (
+		exited  *Result
+		backoff <-chan time.Time
+	)
+
+	for {
+		select {
+		case sig := <-signals:
+			received := sig.(syscall.Signal)
+			mapped, isMapped := s.config.SignalMap[received]
+
+			switch {
+			case isMapped && mapped == 0:
+				slog.Debug("Dropping signal", "signal", unix.SignalName(received))
+			case received == unix.SIGTERM && exited != nil:
+				return exited, nil
+			case received == unix.SIGTERM:
+				s.stop(stopCtx)
+			case isMapped:
+				s.forward(mapped)
+			default:
+				s.forward(received)
+			}
+
+		case <-done:
+			if exited != nil {
+				return exited, nil
+			}
+
+			done = nil
+			s.stop(stopCtx)
+
+		case <-children:
+			result := s.reap()
+			if result == nil {
+				continue
+			}
+
+			if s.takeRestart() {
+				slog.Info("Restarting child process", "exit_code", result.ExitCode)
+
+				if err := s.start(); err != nil {
+					return nil, err
+				}
+
+				continue
+			}
+
+			delay, ok := s.scheduleRestart(result)
+			if !ok {
+				return result, nil
+			}
+
+			slog.Warn("Child process exited, restarting", "exit_code", result.ExitCode, "delay", delay)
+
+			exited, backoff = result, time.After(delay)
+
+		case <-backoff:
+			exited, backoff = nil, nil
+
+			if err := s.restart(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// Signal sends sig to the child process group.
+func (s *Supervisor) Signal(sig syscall.Signal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.pid == 0 {
+		return ErrNotRunning
+	}
+
+	return unix.Kill(-s.pid, sig)
+}
+
+// Restart sends the stop signal to the child process group and starts the
+// child again with env once it exits. Pre-stop hook and delay are skipped,
+// but the child is killed if it's still running KillAfter since.
+func (s *Supervisor) Restart(env []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopping {
+		return ErrStopping
+	}
+
+	if s.pid == 0 {
+		return ErrNotRunning
+	}
+
+	s.restarting, s.restartEnv = true, env
+
+	pid := s.pid
+	config := s.config.Stop
+
+	if config.KillAfter > 0 {
+		time.AfterFunc(config.KillAfter, func() {
+			s.mu.Lock()
+			running := s.pid == pid
+			s.mu.Unlock()
+
+			if running {
+				slog.Warn("Child did not stop in time, killing", "kill_after", config.KillAfter)
+				unix.Kill(-pid, unix.SIGKILL)
+			}
+		})
+	}
+
+	sig := config.Signal
+	if sig == 0 {
+		sig = unix.SIGTERM
+	}
+
+	return unix.Kill(-pid, sig)
+}
+
+// takeRestart reports whether restart was requested, and applies new child
+// environment if so. Stop sequence takes precedence over restart.
+func (s *Supervisor) takeRestart() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.restarting || s.stopping {
+		return false
+	}
+
+	s.config.Env, s.restarting, s.restartEnv = s.restartEnv, false, nil
+
+	return true
+}
+
+func (s *Supervisor) start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	proc, err := os.StartProcess(s.config.Path, s.config.Args, s.procAttr(true))
+	if err != nil {
+		return fmt.Errorf("failed to start child process: %w", err)
+	}
+
+	s.pid, s.started = proc.Pid, time.Now()
+
+	// The child is waited for with wait4(2) by reap, not via os.Process.
+	proc.Release()
+
+	slog.Debug("Started child process", "pid", s.pid)
+
+	return nil
+}
+
+// spawn starts an auxiliary command. Its exit status is delivered to the
+// returned channel by reap.
+func (s *Supervisor) spawn(command *Command) (<-chan unix.WaitStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	proc, err := os.StartProcess(command.Path, command.Args, s.procAttr(false))
+	if err != nil {
+		return nil, err
+	}
+
+	wait := make(chan unix.WaitStatus, 1)
+	s.waiters[proc.Pid] = wait
+
+	proc.Release()
+
+	return wait, nil
+}
+
+// openOutputs opens files the child and hooks write their output to. Pipes
+// are shared by all of them, so that output of restarted child is copied too.
+func (s *Supervisor) openOutputs() error {
+	var err error
+
+	if s.stdout, err = s.outputs.file(s.config.Stdout, os.Stdout); err != nil {
+		return fmt.Errorf("failed to open child output: %w", err)
+	}
+
+	if s.stderr, err = s.outputs.file(s.config.Stderr, os.Stderr); err != nil {
+		closeOwned(s.stdout, s.config.Stdout)
+		return fmt.Errorf("failed to open child output: %w", err)
+	}
+
+	return nil
+}
+
+// closeOutputs closes write ends of output pipes, and waits for the output
+// to be copied.
+func (s *Supervisor) closeOutputs() {
+	closeOwned(s.stdout, s.config.Stdout)
+	closeOwned(s.stderr, s.config.Stderr)
+
+	s.outputs.drain()
+}
+
+func (s *Supervisor) procAttr(setpgid bool) *os.ProcAttr {
+	return &os.ProcAttr{
+		Env:   s.config.Env,
+		Files: []*os.File{orDefault(s.config.Stdin, os.Stdin), orDefault(s.stdout, os.Stdout), orDefault(s.stderr, os.Stderr)},
+		Sys:   &syscall.SysProcAttr{Setpgid: setpgid, Credential: s.config.Credential},
+	}
+}
+
+func (s *Supervisor) forward(sig syscall.Signal) {
+	slog.Debug("Forwarding signal", "signal", unix.SignalName(sig))
+
+	if err := s.Signal(sig); err != nil && !errors.Is(err, unix.ESRCH) && !errors.Is(err, ErrNotRunning) {
+		slog.Warn("Can't forward signal", "signal", unix.SignalName(sig), "error", err)
+	}
+}
+
+// stop starts the stop sequence, unless it's already in progress.
+func (s *Supervisor) stop(ctx context.Context) {
+	s.mu.Lock()
+	stopping := s.stopping
+	s.stopping = true
+	s.mu.Unlock()
+
+	if stopping {
+		slog.Debug("Stop sequence is already in progress")
+		return
+	}
+
+	config := s.config.Stop
+
+	if config.KillAfter > 0 {
+		timer := time.AfterFunc(config.KillAfter, func() {
+			slog.Warn("Child did not stop in time, killing", "kill_after", config.KillAfter)
+			s.forward(unix.SIGKILL)
+		})
+
+		context.AfterFunc(ctx, func() { timer.Stop() })
+	}
+
+	go s.runStopSequence(ctx, config)
+}
+
+func (s *Supervisor) runStopSequence(ctx context.Context, config StopConfig) {
+	if config.PreStop != nil {
+		slog.Info("Running pre-stop hook", "command", config.PreStop.Args[0])
+
+		if err := s.runHook(ctx, config.PreStop); err != nil {
+			slog.Warn("Pre-stop hook failed", "command", config.PreStop.Args[0], "error", err)
+		}
+	}
+
+	if config.Delay > 0 {
+		slog.Info("Delaying stop signal", "delay", config.Delay)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(config.Delay):
+		}
+	}
+
+	sig := config.Signal
+	if sig == 0 {
+		sig = unix.SIGTERM
+	}
+
+	s.forward(sig)
+}
+
+func (s *Supervisor) runHook(ctx context.Context, command *Command) error {
+	wait, err := s.spawn(command)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+
+	case status := <-wait:
+		if status.ExitStatus() != 0 || status.Signaled() {
+			return fmt.Errorf("exit status %d", newResult(status).ExitCode)
+		}
+
+		return nil
+	}
+}
+
+// reap collects all exited children. Returns result once the supervised
+// child has exited, nil otherwise.
+func (s *Supervisor) reap() *Result {
+	var result *Result
+
+	for {
+		var (
+			status unix.WaitStatus
+			rusage unix.Rusage
+		)
+
+		pid, err := unix.Wait4(-1, &status, unix.WNOHANG, &rusage)
+		if errors.Is(err, unix.EINTR) {
+			continue
+		}
+
+		if err != nil || pid <= 0 {
+			return result
+		}
+
+		s.mu.Lock()
+		started := s.started
+		wait, isAuxiliary := s.waiters[pid]
+		delete(s.waiters, pid)
+
+		isChild := pid == s.pid
+		if isChild {
+			s.pid = 0
+		}
+		s.mu.Unlock()
+
+		switch {
+		case isChild:
+			result = newResult(status)
+			result.Usage = newUsage(&rusage, started)
+		case isAuxiliary:
+			wait <- status
+		default:
+			slog.Debug("Reaped orphaned process", "pid", pid)
+		}
+	}
+}
+
+func newResult(status unix.WaitStatus) *Result {
+	if status.Signaled() {
+		return &Result{ExitCode: 128 + int(status.Signal()), Signal: status.Signal()}
+	}
+
+	return &Result{ExitCode: status.ExitStatus()}
+}
+
+// newUsage returns usage of the process started at started.
+func newUsage(rusage *unix.Rusage, started time.Time) Usage {
+	return Usage{
+		Runtime:    time.Since(started),
+		UserTime:   time.Duration(rusage.Utime.Nano()),
+		SystemTime: time.Duration(rusage.Stime.Nano()),
+		MaxRSS:     maxRSS(rusage),
+	}
+}
+
+func orDefault(f *os.File, def *os.File) *os.File {
+	if f != nil {
+		return f
+	}
+
+	return def
+}
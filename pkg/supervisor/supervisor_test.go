@@ -0,0 +1,600 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package supervisor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func shell(script string) Config {
+	return Config{
+		Path: "/bin/sh",
+		Args: []string{"sh", "-c", script},
+		Env:  []string{"PATH=/usr/bin:/bin"},
+	}
+}
+
+// pipe returns a pipe closed on test cleanup.
+func pipe(t *testing.T) (*os.File, *os.File) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		r.Close()
+		w.Close()
+	})
+
+	return r, w
+}
+
+// withoutUsage returns copy of result without usage, which varies between
+// runs.
+func withoutUsage(result *Result) *Result {
+	if result == nil {
+		return nil
+	}
+
+	status := *result
+	status.Usage = Usage{}
+
+	return &status
+}
+
+func TestSupervisor_Run(t *testing.T) {
+	t.Run("with successful child", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		result, err := New(shell("exit 0")).Run(context.Background())
+
+		require.NoError(err)
+		assert.Equal(&Result{ExitCode: 0}, withoutUsage(result))
+	})
+
+	t.Run("with failing child", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		result, err := New(shell("exit 3")).Run(context.Background())
+
+		require.NoError(err)
+		assert.Equal(&Result{ExitCode: 3}, withoutUsage(result))
+	})
+
+	t.Run("with child killed by signal", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		result, err := New(shell("kill -KILL $$")).Run(context.Background())
+
+		require.NoError(err)
+		assert.Equal(&Result{ExitCode: 137, Signal: unix.SIGKILL}, withoutUsage(result))
+	})
+
+	t.Run("reports usage", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		result, err := New(shell("sleep 0.2; i=0; while [ $i -lt 10000 ]; do i=$((i+1)); done")).Run(context.Background())
+
+		require.NoError(err)
+		assert.GreaterOrEqual(result.Usage.Runtime, 200*time.Millisecond)
+		assert.Positive(result.Usage.UserTime + result.Usage.SystemTime)
+		assert.Positive(result.Usage.MaxRSS)
+	})
+
+	t.Run("passes environment", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		config := shell(`test "$GREETING" = "hello"`)
+		config.Env = append(config.Env, "GREETING=hello")
+
+		result, err := New(config).Run(context.Background())
+
+		require.NoError(err)
+		assert.Equal(0, result.ExitCode)
+	})
+
+	t.Run("starts child in its own process group", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		r, w := pipe(t)
+		config := shell(`ps -o pgid= -p $$ | tr -d ' '; echo $$`)
+		config.Stdout = w
+
+		result, err := New(config).Run(context.Background())
+		require.NoError(err)
+		require.Equal(0, result.ExitCode)
+
+		scanner := bufio.NewScanner(r)
+		require.True(scanner.Scan())
+		pgid := scanner.Text()
+		require.True(scanner.Scan())
+		pid := scanner.Text()
+
+		assert.Equal(pid, pgid)
+	})
+
+	t.Run("forwards received signals", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		r, w := pipe(t)
+		config := shell(`trap 'exit 7' USR1; echo ready; while :; do sleep 0.01; done`)
+		config.Stdout = w
+
+		go func() {
+			bufio.NewReader(r).ReadString('\n')
+			unix.Kill(os.Getpid(), unix.SIGUSR1)
+		}()
+
+		result, err := New(config).Run(context.Background())
+
+		require.NoError(err)
+		assert.Equal(7, result.ExitCode)
+	})
+
+	t.Run("with cancelled context terminates child", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		result, err := New(shell("exec sleep 10")).Run(ctx)
+
+		require.NoError(err)
+		assert.Equal(&Result{ExitCode: 143, Signal: unix.SIGTERM}, withoutUsage(result))
+	})
+
+	t.Run("copies output to writers", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		var stdout, stderr bytes.Buffer
+
+		config := shell(`echo "out $$"; echo err >&2; [ -e "$0" ] || { touch "$0"; exit 1; }`)
+		config.Args = append(config.Args, t.TempDir()+"/restarted")
+		config.Stdout, config.Stderr = &stdout, &stderr
+		config.Restart = RestartConfig{Policy: RestartOnFailure}
+
+		result, err := New(config).Run(context.Background())
+
+		require.NoError(err)
+		assert.Equal(0, result.ExitCode)
+		assert.Len(strings.Split(strings.TrimSpace(stdout.String()), "\n"), 2, "output of both runs is copied")
+		assert.Equal("err\nerr\n", stderr.String())
+	})
+
+	t.Run("with missing executable", func(t *testing.T) {
+		assert := assert.New(t)
+
+		result, err := New(Config{Path: "/nonexistent", Args: []string{"nonexistent"}}).Run(context.Background())
+
+		assert.Nil(result)
+		assert.ErrorContains(err, "failed to start child process")
+	})
+}
+
+// runStopped runs the supervisor and triggers its stop sequence once
+// the child reports readiness by printing a line.
+func runStopped(t *testing.T, config Config) (*Result, time.Duration, error) {
+	r, w := pipe(t)
+	config.Stdout = w
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	triggered := make(chan time.Time, 1)
+	go func() {
+		bufio.NewReader(r).ReadString('\n')
+		triggered <- time.Now()
+		cancel()
+	}()
+
+	result, err := New(config).Run(ctx)
+
+	return result, time.Since(<-triggered), err
+}
+
+func TestSupervisor_Stop(t *testing.T) {
+	t.Run("sends stop signal", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		config := shell(`trap 'exit 3' QUIT; echo ready; while :; do sleep 0.01; done`)
+		config.Stop.Signal = unix.SIGQUIT
+
+		result, _, err := runStopped(t, config)
+
+		require.NoError(err)
+		assert.Equal(3, result.ExitCode)
+	})
+
+	t.Run("delays stop signal", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		config := shell(`trap 'exit 0' TERM; echo ready; while :; do sleep 0.01; done`)
+		config.Stop.Delay = 200 * time.Millisecond
+
+		result, elapsed, err := runStopped(t, config)
+
+		require.NoError(err)
+		assert.Equal(0, result.ExitCode)
+		assert.GreaterOrEqual(elapsed, 200*time.Millisecond)
+	})
+
+	t.Run("runs pre-stop hook before stop signal", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		marker := t.TempDir() + "/stopping"
+
+		config := shell(`trap 'test -f "$MARKER" && exit 0; exit 1' TERM; echo ready; while :; do sleep 0.01; done`)
+		config.Env = append(config.Env, "MARKER="+marker)
+		config.Stop.PreStop = &Command{Path: "/bin/sh", Args: []string{"sh", "-c", `sleep 0.1; touch "$MARKER"`}}
+
+		result, _, err := runStopped(t, config)
+
+		require.NoError(err)
+		assert.Equal(0, result.ExitCode)
+	})
+
+	t.Run("with failing pre-stop hook still stops child", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		config := shell(`trap 'exit 0' TERM; echo ready; while :; do sleep 0.01; done`)
+		config.Stop.PreStop = &Command{Path: "/bin/sh", Args: []string{"sh", "-c", "exit 1"}}
+
+		result, _, err := runStopped(t, config)
+
+		require.NoError(err)
+		assert.Equal(0, result.ExitCode)
+	})
+
+	t.Run("with missing pre-stop hook still stops child", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		config := shell(`trap 'exit 0' TERM; echo ready; while :; do sleep 0.01; done`)
+		config.Stop.PreStop = &Command{Path: "/nonexistent", Args: []string{"nonexistent"}}
+
+		result, _, err := runStopped(t, config)
+
+		require.NoError(err)
+		assert.Equal(0, result.ExitCode)
+	})
+
+	t.Run("kills child that ignores stop signal", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		config := shell(`trap '' TERM; echo ready; while :; do sleep 0.01; done`)
+		config.Stop.KillAfter = 100 * time.Millisecond
+
+		result, elapsed, err := runStopped(t, config)
+
+		require.NoError(err)
+		assert.Equal(&Result{ExitCode: 137, Signal: unix.SIGKILL}, withoutUsage(result))
+		assert.GreaterOrEqual(elapsed, 100*time.Millisecond)
+	})
+
+	t.Run("kill deadline includes delay", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		config := shell(`trap 'exit 0' TERM; echo ready; while :; do sleep 0.01; done`)
+		config.Stop.Delay = 5 * time.Second
+		config.Stop.KillAfter = 100 * time.Millisecond
+
+		result, elapsed, err := runStopped(t, config)
+
+		require.NoError(err)
+		assert.Equal(137, result.ExitCode)
+		assert.Less(elapsed, 5*time.Second)
+	})
+}
+
+func TestSupervisor_SignalMap(t *testing.T) {
+	t.Run("rewrites forwarded signal", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		r, w := pipe(t)
+		config := shell(`trap 'exit 1' USR1; trap 'exit 5' USR2; echo ready; while :; do sleep 0.01; done`)
+		config.Stdout = w
+		config.SignalMap = map[syscall.Signal]syscall.Signal{unix.SIGUSR1: unix.SIGUSR2}
+
+		go func() {
+			bufio.NewReader(r).ReadString('\n')
+			unix.Kill(os.Getpid(), unix.SIGUSR1)
+		}()
+
+		result, err := New(config).Run(context.Background())
+
+		require.NoError(err)
+		assert.Equal(5, result.ExitCode)
+	})
+
+	t.Run("drops signal", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		r, w := pipe(t)
+		config := shell(`trap 'exit 1' USR1; trap 'exit 0' TERM; echo ready; while :; do sleep 0.01; done`)
+		config.Stdout = w
+		config.SignalMap = map[syscall.Signal]syscall.Signal{unix.SIGUSR1: 0}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go func() {
+			bufio.NewReader(r).ReadString('\n')
+			unix.Kill(os.Getpid(), unix.SIGUSR1)
+			time.Sleep(100 * time.Millisecond)
+			cancel()
+		}()
+
+		result, err := New(config).Run(ctx)
+
+		require.NoError(err)
+		assert.Equal(0, result.ExitCode)
+	})
+}
+
+func TestSupervisor_Restart(t *testing.T) {
+	t.Run("restarts child with new environment", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		r, w := pipe(t)
+		config := shell(`echo "$GENERATION"; test "$GENERATION" = 2 && exit 4; trap 'exit 0' TERM; while :; do sleep 0.01; done`)
+		config.Stdout = w
+		config.Env = append(config.Env, "GENERATION=1")
+
+		s := New(config)
+
+		go func() {
+			reader := bufio.NewReader(r)
+			reader.ReadString('\n')
+			s.Restart(append(config.Env, "GENERATION=2"))
+		}()
+
+		result, err := s.Run(context.Background())
+
+		require.NoError(err)
+		assert.Equal(4, result.ExitCode)
+		assert.Contains(s.Config().Env, "GENERATION=2")
+	})
+
+	t.Run("kills child that ignores stop signal", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		r, w := pipe(t)
+		config := shell(`test -n "$RESTARTED" && exit 4; trap '' TERM; echo ready; while :; do sleep 0.01; done`)
+		config.Stdout = w
+		config.Stop.KillAfter = 100 * time.Millisecond
+
+		s := New(config)
+
+		go func() {
+			bufio.NewReader(r).ReadString('\n')
+			s.Restart(append(config.Env, "RESTARTED=1"))
+		}()
+
+		result, err := s.Run(context.Background())
+
+		require.NoError(err)
+		assert.Equal(4, result.ExitCode)
+	})
+
+	t.Run("when not running", func(t *testing.T) {
+		err := New(shell("exit 0")).Restart(nil)
+
+		assert.ErrorIs(t, err, ErrNotRunning)
+	})
+}
+
+func TestSupervisor_Signal(t *testing.T) {
+	t.Run("when not running", func(t *testing.T) {
+		err := New(shell("exit 0")).Signal(unix.SIGTERM)
+
+		assert.ErrorIs(t, err, ErrNotRunning)
+	})
+}
+
+func TestSupervisor_RestartPolicy(t *testing.T) {
+	// counter makes child count its runs in a file, and exit with status
+	// given by the script.
+	counter := func(t *testing.T, script string) (Config, string) {
+		path := t.TempDir() + "/runs"
+
+		return shell(`echo "${ECS_RESTART_ATTEMPT:-0}" >> ` + path + `; runs=$(wc -l < ` + path + `); ` + script), path
+	}
+
+	runs := func(t *testing.T, path string) []string {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		return strings.Fields(string(data))
+	}
+
+	t.Run("restarts failing child", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		config, path := counter(t, `[ "$runs" -ge 3 ] || exit 1`)
+		config.Restart = RestartConfig{
+			Policy: RestartOnFailure,
+			Prepare: func(attempt int, env []string) []string {
+				return append(env, fmt.Sprintf("ECS_RESTART_ATTEMPT=%d", attempt))
+			},
+		}
+
+		result, err := New(config).Run(context.Background())
+
+		require.NoError(err)
+		assert.Equal(&Result{ExitCode: 0}, withoutUsage(result))
+		assert.Equal([]string{"0", "1", "2"}, runs(t, path))
+	})
+
+	t.Run("with on-failure policy does not restart successful child", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		config, path := counter(t, "exit 0")
+		config.Restart = RestartConfig{Policy: RestartOnFailure}
+
+		result, err := New(config).Run(context.Background())
+
+		require.NoError(err)
+		assert.Equal(&Result{ExitCode: 0}, withoutUsage(result))
+		assert.Len(runs(t, path), 1)
+	})
+
+	t.Run("with always policy restarts successful child", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		config, path := counter(t, `[ "$runs" -ge 2 ] && exit 4; exit 0`)
+		config.Restart = RestartConfig{Policy: RestartAlways, MaxRestarts: 1}
+
+		result, err := New(config).Run(context.Background())
+
+		require.NoError(err)
+		assert.Equal(&Result{ExitCode: 4}, withoutUsage(result))
+		assert.Len(runs(t, path), 2)
+	})
+
+	t.Run("gives up after max restarts", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		config, path := counter(t, "exit 1")
+		config.Restart = RestartConfig{Policy: RestartOnFailure, MaxRestarts: 2, Window: time.Minute}
+
+		result, err := New(config).Run(context.Background())
+
+		require.NoError(err)
+		assert.Equal(&Result{ExitCode: 1}, withoutUsage(result))
+		assert.Len(runs(t, path), 3)
+	})
+
+	t.Run("waits with exponential backoff", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		config, _ := counter(t, "exit 1")
+		config.Restart = RestartConfig{Policy: RestartOnFailure, MaxRestarts: 3, Backoff: 50 * time.Millisecond}
+
+		start := time.Now()
+		result, err := New(config).Run(context.Background())
+
+		require.NoError(err)
+		assert.Equal(&Result{ExitCode: 1}, withoutUsage(result))
+		assert.GreaterOrEqual(time.Since(start), (50+100+200)*time.Millisecond)
+	})
+
+	t.Run("with cancelled context during backoff exits immediately", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		config, path := counter(t, "exit 1")
+		config.Restart = RestartConfig{Policy: RestartOnFailure, Backoff: time.Minute}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		result, err := New(config).Run(ctx)
+
+		require.NoError(err)
+		assert.Equal(&Result{ExitCode: 1}, withoutUsage(result))
+		assert.Less(time.Since(start), 5*time.Second)
+		assert.Len(runs(t, path), 1)
+	})
+
+	t.Run("with stopped child does not restart", func(t *testing.T) {
+		assert := assert.New(t)
+		require := require.New(t)
+
+		config := shell("echo ready; sleep 10")
+		config.Restart = RestartConfig{Policy: RestartAlways}
+
+		result, _, err := runStopped(t, config)
+
+		require.NoError(err)
+		assert.Equal(128+int(unix.SIGTERM), result.ExitCode)
+	})
+}
+
+func TestSupervisor_scheduleRestart(t *testing.T) {
+	failed := &Result{ExitCode: 1}
+
+	t.Run("doubles backoff up to max backoff", func(t *testing.T) {
+		assert := assert.New(t)
+
+		s := New(Config{Restart: RestartConfig{Policy: RestartOnFailure, Backoff: time.Second, MaxBackoff: 5 * time.Second}})
+
+		var delays []time.Duration
+		for range 5 {
+			delay, ok := s.scheduleRestart(failed)
+			assert.True(ok)
+
+			delays = append(delays, delay)
+		}
+
+		assert.Equal([]time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}, delays)
+	})
+
+	t.Run("counts restarts within window", func(t *testing.T) {
+		assert := assert.New(t)
+
+		s := New(Config{Restart: RestartConfig{Policy: RestartOnFailure, MaxRestarts: 2, Window: time.Minute, Backoff: time.Second}})
+		s.restarts = []time.Time{time.Now().Add(-2 * time.Minute), time.Now().Add(-30 * time.Second)}
+
+		delay, ok := s.scheduleRestart(failed)
+		assert.True(ok)
+		assert.Equal(2*time.Second, delay)
+
+		_, ok = s.scheduleRestart(failed)
+		assert.False(ok)
+	})
+
+	t.Run("with never policy", func(t *testing.T) {
+		_, ok := New(Config{}).scheduleRestart(failed)
+
+		assert.False(t, ok)
+	})
+}
+
+func TestParseRestartPolicy(t *testing.T) {
+	for _, name := range []string{"never", "on-failure", "always"} {
+		policy, err := ParseRestartPolicy(name)
+
+		require.NoError(t, err)
+		assert.Equal(t, RestartPolicy(name), policy)
+	}
+
+	_, err := ParseRestartPolicy("sometimes")
+
+	assert.EqualError(t, err, "unknown restart policy: sometimes")
+}
@@ -0,0 +1,195 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+// Package tuning derives runtime settings, e.g. GOMAXPROCS or JVM heap size,
+// from CPU and memory limits of the container. Runtimes tend to size thread
+// pools and heaps by the host resources, overcommitting the container.
+package tuning
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ixti/ecs-task-helper/pkg/cgroup"
+	"github.com/ixti/ecs-task-helper/pkg/container_metadata"
+	"github.com/ixti/ecs-task-helper/pkg/environ"
+)
+
+const (
+	mebibyte = 1 << 20
+
+	// goMemoryPercent of the memory limit is used as the Go soft memory
+	// limit, leaving room for non-heap memory.
+	goMemoryPercent = 90
+	// heapPercent of the memory limit is used for JVM and Node.js heaps.
+	heapPercent = 75
+	// uvThreadpoolSize is the libuv default, which is not reduced.
+	uvThreadpoolSize = 4
+)
+
+// Limits are effective resource limits of the container.
+type Limits struct {
+	// CPU is the number of CPUs, zero if not limited.
+	CPU float64
+	// Memory is in bytes, zero if not limited.
+	Memory int64
+}
+
+// CPUs returns the number of CPUs rounded up, zero if not limited.
+func (l Limits) CPUs() int {
+	return int(math.Ceil(l.CPU))
+}
+
+// Detect returns limits from the task metadata, falling back to the limits
+// of the cgroup for resources the metadata does not limit.
+func Detect(m *container_metadata.Metadata, g *cgroup.Group) Limits {
+	var limits Limits
+
+	if m.Limits != nil {
+		limits.CPU = m.Limits.CPU
+		limits.Memory = m.Limits.Memory * mebibyte
+	}
+
+	if limits.CPU == 0 {
+		cpu, err := g.CPULimit()
+		if err != nil {
+			slog.Debug("Can't read cgroup CPU limit", "error", err)
+		}
+
+		limits.CPU = cpu
+	}
+
+	if limits.Memory == 0 {
+		memory, err := g.MemoryLimit()
+		if err != nil {
+			slog.Debug("Can't read cgroup memory limit", "error", err)
+		}
+
+		limits.Memory = memory
+	}
+
+	return limits
+}
+
+// runtime returns variables to set for the limits. Values of option list
+// variables, like JAVA_TOOL_OPTIONS, are options to append.
+type runtime func(env []string, limits Limits) []string
+
+var runtimes = map[string]runtime{
+	"go":   goRuntime,
+	"java": javaRuntime,
+	"node": nodeRuntime,
+	"web":  webRuntime,
+}
+
+// Runtimes returns names of supported runtimes.
+func Runtimes() []string {
+	return slices.Sorted(maps.Keys(runtimes))
+}
+
+// Validate returns error if any of the runtime names is unknown.
+func Validate(names []string) error {
+	for _, name := range names {
+		if _, ok := runtimes[name]; !ok {
+			return fmt.Errorf("unknown runtime: %s", name)
+		}
+	}
+
+	return nil
+}
+
+// EnvironWith returns env with settings of the named runtimes for the limits.
+// Variables already set to a non-empty value are kept as is, and options
+// already present in option lists are never overridden.
+func EnvironWith(env []string, limits Limits, names []string) ([]string, error) {
+	if err := Validate(names); err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		env = runtimes[name](env, limits)
+	}
+
+	return env, nil
+}
+
+func goRuntime(env []string, limits Limits) []string {
+	if limits.CPU > 0 {
+		env = setDefault(env, "GOMAXPROCS", strconv.Itoa(limits.CPUs()))
+	}
+
+	if limits.Memory > 0 {
+		env = setDefault(env, "GOMEMLIMIT", fmt.Sprintf("%dMiB", limits.Memory*goMemoryPercent/100/mebibyte))
+	}
+
+	return env
+}
+
+func javaRuntime(env []string, limits Limits) []string {
+	if limits.CPU > 0 {
+		env = appendOption(env, "JAVA_TOOL_OPTIONS", fmt.Sprintf("-XX:ActiveProcessorCount=%d", limits.CPUs()), "-XX:ActiveProcessorCount=")
+	}
+
+	if limits.Memory > 0 {
+		// MaxRAM makes percentages relative to the limit even if the JVM
+		// can't see it in cgroup, e.g. with memory limit set on task level.
+		option := fmt.Sprintf("-XX:MaxRAM=%d -XX:MaxRAMPercentage=%d.0", limits.Memory, heapPercent)
+		env = appendOption(env, "JAVA_TOOL_OPTIONS", option, "-Xmx", "-XX:MaxHeapSize=", "-XX:MaxRAM")
+	}
+
+	return env
+}
+
+func nodeRuntime(env []string, limits Limits) []string {
+	if limits.CPU > 0 {
+		env = setDefault(env, "UV_THREADPOOL_SIZE", strconv.Itoa(max(limits.CPUs(), uvThreadpoolSize)))
+	}
+
+	if limits.Memory > 0 {
+		option := fmt.Sprintf("--max-old-space-size=%d", limits.Memory*heapPercent/100/mebibyte)
+		env = appendOption(env, "NODE_OPTIONS", option, "--max-old-space-size")
+	}
+
+	return env
+}
+
+func webRuntime(env []string, limits Limits) []string {
+	if limits.CPU > 0 {
+		env = setDefault(env, "WEB_CONCURRENCY", strconv.Itoa(limits.CPUs()))
+	}
+
+	return env
+}
+
+func setDefault(env []string, key string, value string) []string {
+	if existing, _ := environ.Lookup(env, key); existing != "" {
+		return env
+	}
+
+	return environ.Set(env, key, value)
+}
+
+// appendOption appends option to the space-separated option list, unless
+// the list already has an option with any of the prefixes.
+func appendOption(env []string, key string, option string, prefixes ...string) []string {
+	existing, _ := environ.Lookup(env, key)
+
+	for _, field := range strings.Fields(existing) {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(field, prefix) {
+				return env
+			}
+		}
+	}
+
+	if existing != "" {
+		option = existing + " " + option
+	}
+
+	return environ.Set(env, key, option)
+}
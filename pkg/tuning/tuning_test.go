@@ -0,0 +1,118 @@
+// Copyright (C) 2026 Alexey Zapparov
+// SPDX-License-Identifier: MIT
+
+package tuning
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ixti/ecs-task-helper/pkg/cgroup"
+	"github.com/ixti/ecs-task-helper/pkg/container_metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fakeCgroup(t *testing.T, cpuMax string, memoryMax string) *cgroup.Group {
+	root := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "cgroup.controllers"), []byte("cpu memory\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "cpu.max"), []byte(cpuMax), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "memory.max"), []byte(memoryMax), 0o644))
+
+	return cgroup.Open(root)
+}
+
+func TestDetect(t *testing.T) {
+	t.Run("with metadata limits", func(t *testing.T) {
+		m := &container_metadata.Metadata{Limits: &container_metadata.Limits{CPU: 0.5, Memory: 512}}
+
+		limits := Detect(m, fakeCgroup(t, "200000 100000", "1073741824"))
+
+		assert.Equal(t, Limits{CPU: 0.5, Memory: 512 << 20}, limits)
+	})
+
+	t.Run("with partial metadata limits", func(t *testing.T) {
+		m := &container_metadata.Metadata{Limits: &container_metadata.Limits{Memory: 512}}
+
+		limits := Detect(m, fakeCgroup(t, "200000 100000", "1073741824"))
+
+		assert.Equal(t, Limits{CPU: 2, Memory: 512 << 20}, limits)
+	})
+
+	t.Run("without metadata limits", func(t *testing.T) {
+		limits := Detect(&container_metadata.Metadata{}, fakeCgroup(t, "150000 100000", "1073741824"))
+
+		assert.Equal(t, Limits{CPU: 1.5, Memory: 1 << 30}, limits)
+	})
+
+	t.Run("without any limits", func(t *testing.T) {
+		limits := Detect(&container_metadata.Metadata{}, cgroup.Open(t.TempDir()))
+
+		assert.Equal(t, Limits{}, limits)
+	})
+}
+
+func TestEnvironWith(t *testing.T) {
+	limits := Limits{CPU: 1.5, Memory: 1 << 30}
+
+	tests := []struct {
+		name     string
+		runtimes []string
+		env      []string
+		limits   Limits
+		expected []string
+	}{
+		{
+			"go",
+			[]string{"go"}, nil, limits,
+			[]string{"GOMAXPROCS=2", "GOMEMLIMIT=921MiB"},
+		},
+		{
+			"java",
+			[]string{"java"}, nil, limits,
+			[]string{"JAVA_TOOL_OPTIONS=-XX:ActiveProcessorCount=2 -XX:MaxRAM=1073741824 -XX:MaxRAMPercentage=75.0"},
+		},
+		{
+			"node",
+			[]string{"node"}, nil, limits,
+			[]string{"UV_THREADPOOL_SIZE=4", "NODE_OPTIONS=--max-old-space-size=768"},
+		},
+		{
+			"web",
+			[]string{"web"}, nil, Limits{CPU: 0.25},
+			[]string{"WEB_CONCURRENCY=1"},
+		},
+		{
+			"with user-set variables",
+			[]string{"go", "node", "web"}, []string{"GOMAXPROCS=8", "UV_THREADPOOL_SIZE=16", "WEB_CONCURRENCY="}, Limits{CPU: 6, Memory: 1 << 30},
+			[]string{"GOMAXPROCS=8", "UV_THREADPOOL_SIZE=16", "WEB_CONCURRENCY=6", "GOMEMLIMIT=921MiB", "NODE_OPTIONS=--max-old-space-size=768"},
+		},
+		{
+			"with user-set options",
+			[]string{"java", "node"}, []string{"JAVA_TOOL_OPTIONS=-Xmx256m -Dfoo=bar", "NODE_OPTIONS=--max-old-space-size=100 --enable-source-maps"}, limits,
+			[]string{"JAVA_TOOL_OPTIONS=-Xmx256m -Dfoo=bar -XX:ActiveProcessorCount=2", "NODE_OPTIONS=--max-old-space-size=100 --enable-source-maps", "UV_THREADPOOL_SIZE=4"},
+		},
+		{
+			"without limits",
+			[]string{"go", "java", "node", "web"}, []string{"PATH=/bin"}, Limits{},
+			[]string{"PATH=/bin"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			env, err := EnvironWith(tc.env, tc.limits, tc.runtimes)
+
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tc.expected, env)
+		})
+	}
+
+	t.Run("with unknown runtime", func(t *testing.T) {
+		_, err := EnvironWith(nil, limits, []string{"go", "cobol"})
+
+		assert.EqualError(t, err, "unknown runtime: cobol")
+	})
+}